@@ -2,9 +2,13 @@ package main
 
 import (
 	"bibleapp/backend/internal/api"
+	"bibleapp/backend/internal/calendar"
 	"bibleapp/backend/internal/config"
 	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/metering"
+	"bibleapp/backend/internal/moderation"
 	"bibleapp/backend/internal/repository"
+	"bibleapp/backend/internal/scheduler"
 	"bibleapp/backend/internal/service"
 	"context"
 	"encoding/json"
@@ -12,6 +16,9 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -197,6 +204,25 @@ func downloadAndImportKJV(ctx context.Context, collection *mongo.Collection) err
 	return nil
 }
 
+// configuredLLMModels lists the models the configuration sends requests to, each once
+func configuredLLMModels(cfg *config.Config) []string {
+	candidates := []string{cfg.LLMModelName, cfg.ModerationModel, cfg.LLMBudgetFallback}
+	for _, steps := range cfg.LLMRoutes {
+		for _, step := range steps {
+			candidates = append(candidates, step.Model)
+		}
+	}
+	var models []string
+	seen := make(map[string]bool)
+	for _, model := range candidates {
+		if model != "" && !seen[model] {
+			seen[model] = true
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
 func main() {
 	// Load Configuration
 	cfg := config.Load()
@@ -229,11 +255,39 @@ func main() {
 
 	// 2. Repositories
 	planRepo := repository.NewMongoPlanRepository(mongoDB)
+	planRevisionRepo := repository.NewMongoPlanRevisionRepository(mongoDB)
 	userRepo := repository.NewMongoUserRepository(mongoDB)
 
 	// 3. External Clients (LLM)
 	planningModelName := cfg.LLMModelName
-	openRouterClient := llm.NewOpenRouterClient(cfg.OpenRouterAPIKey, cfg.OpenRouterBaseURL)
+	// Every request is metered: its tokens and cost are recorded and the monthly budget applied
+	llmUsageRepo := repository.NewMongoLLMUsageRepository(mongoDB)
+	// Each feature's requests go to its route of providers, falling back along it when one fails
+	llmRegistry, err := llm.NewRegistry(cfg.LLMProviders)
+	if err != nil {
+		log.Fatalf("FATAL: Invalid LLM providers: %v", err)
+	}
+	llmRoutes := make(map[string]llm.LLMClient, len(cfg.LLMRoutes))
+	for feature, steps := range cfg.LLMRoutes {
+		route, err := llmRegistry.Route(steps)
+		if err != nil {
+			log.Fatalf("FATAL: Invalid LLM route for %s: %v", feature, err)
+		}
+		llmRoutes[feature] = route
+		log.Printf("INFO: LLM route for %s: %v", feature, steps)
+	}
+	// The budget route isn't a feature's; it takes every request once the budget is spent
+	llmBudget := metering.Budget{MonthlyLimit: cfg.LLMMonthlyBudget, Fallback: llmRoutes[llm.BudgetRoute]}
+	delete(llmRoutes, llm.BudgetRoute)
+	llmRouter := llm.NewFeatureRouter(llmRoutes, llmRoutes[llm.DefaultRoute], metering.FeatureOf)
+	llmClient := metering.NewClient(llmRouter, llmUsageRepo, cfg.LLMPrices, llmBudget)
+	log.Printf("INFO: LLM metering configured: %d priced models, monthly budget=$%.2f, budget route=%v",
+		len(cfg.LLMPrices), cfg.LLMMonthlyBudget, cfg.LLMRoutes[llm.BudgetRoute])
+	for _, model := range configuredLLMModels(cfg) {
+		if !cfg.LLMPrices.Has(model) {
+			log.Printf("WARN: LLM model %s has no price in LLM_PRICES, so its requests cost nothing against the budget", model)
+		}
+	}
 
 	// 4. Services
 	// Get verse collection and check/import data
@@ -265,27 +319,73 @@ func main() {
 	log.Printf("INFO: Using MongoDB repository for Bible verses (current count: %d).", verseCount)
 	verseRepo := repository.NewMongoVerseRepository(mongoDB)
 
-	// Initialize chat usage repository for rate limiting (in-memory)
-	chatUsageRepo := repository.NewMemoryChatUsageRepository()
-	log.Printf("INFO: Rate limiting configured: enabled=%v, limit=%d per day (in-memory storage)",
-		cfg.ChatRateLimitEnabled, cfg.ChatRateLimitPerDay)
+	// Chat usage is counted in MongoDB so limits survive restarts and are shared by replicas
+	chatUsageRepo := repository.NewMongoChatUsageRepository(mongoDB)
+	log.Printf("INFO: Rate limiting configured: enabled=%v, limit=%d per day, role limits=%v",
+		cfg.ChatRateLimitEnabled, cfg.ChatRateLimitPerDay, cfg.ChatRateLimitPerRole)
 
 	// Create all services
 	verseService := service.NewVerseService(verseRepo)
-	chatService := service.NewChatService(openRouterClient, cfg.LLMModelName, verseService, chatUsageRepo, cfg)
-	planService := service.NewPlanService(planRepo, openRouterClient, planningModelName)
+	conversationRepo := repository.NewMongoConversationRepository(mongoDB)
+
+	// Chat moderation: rule lists first, then the LLM classifier if a model is configured
+	var moderator moderation.Moderator
+	if cfg.ModerationEnabled {
+		moderators := []moderation.Moderator{moderation.NewRuleModerator(moderation.DefaultRules(), cfg.ModerationBlockTerms...)}
+		if cfg.ModerationModel != "" {
+			moderators = append(moderators, moderation.NewLLMClassifier(llmClient, cfg.ModerationModel))
+		}
+		moderator = moderation.NewPipeline(moderators...)
+	}
+	log.Printf("INFO: Chat moderation configured: enabled=%v, classifier model=%q, extra blocked terms=%d",
+		cfg.ModerationEnabled, cfg.ModerationModel, len(cfg.ModerationBlockTerms))
+	moderationService := service.NewModerationService(moderator, repository.NewMongoModerationRepository(mongoDB), userRepo)
+	themeCalendar := calendar.NewThemeCalendar(cfg.ThemeCalendar, cfg.LiturgicalThemes)
+	log.Printf("INFO: Theme calendar configured: %d entries, liturgical themes=%v", len(cfg.ThemeCalendar), cfg.LiturgicalThemes)
+	planService := service.NewPlanService(planRepo, planRevisionRepo, userRepo, llmClient, verseService, planningModelName, cfg.DefaultPlanTracks, themeCalendar)
+	chatService := service.NewChatService(llmClient, cfg.LLMModelName, verseService, planService, chatUsageRepo, conversationRepo, userRepo, moderationService, cfg)
+	log.Printf("INFO: Chat tools enabled: %v", cfg.ChatToolsEnabled)
+
+	for _, track := range cfg.DefaultPlanTracks {
+		log.Printf("INFO: Default track '%s' uses theme: %s and target audience: %s", track.ID, track.Theme, track.TargetAudience)
+	}
 
-	// Start weekly Bible plan generation scheduler
-	service.StartWeeklyPlanScheduler(planService, cfg)
+	// Background jobs run on a persisted schedule shared by all replicas
+	schedulerLocation, err := time.LoadLocation(cfg.SchedulerTimezone)
+	if err != nil {
+		log.Fatalf("FATAL: Invalid SCHEDULER_TIMEZONE '%s': %v", cfg.SchedulerTimezone, err)
+	}
+	jobScheduler := scheduler.New(repository.NewMongoJobRepository(mongoDB), schedulerLocation)
+	if err := jobScheduler.Register(service.NewDefaultPlanJob(planService, cfg.DefaultPlanSchedule)); err != nil {
+		log.Fatalf("FATAL: Invalid default plan job: %v", err)
+	}
+
+	// Cancelled on SIGINT/SIGTERM to stop the server and background jobs
+	shutdownCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := jobScheduler.Start(shutdownCtx); err != nil {
+		log.Fatalf("FATAL: Could not start job scheduler: %v", err)
+	}
 
 	// Set up Google OAuth config
 	googleOAuthConfig := service.SetupGoogleOAuthConfig(cfg)
 
 	// Create auth service with proper dependencies
-	authService := service.NewAuthService(googleOAuthConfig, userRepo, cfg.JWTSecret) // Auth service for Google OAuth
+	authService := service.NewAuthService(googleOAuthConfig, userRepo, cfg.JWTSecret, cfg.BootstrapAdminEmails) // Auth service for Google OAuth
+	userService := service.NewUserService(userRepo)
+	devotionalService := service.NewDevotionalService(planRepo, llmClient, verseService, cfg.LLMModelName)
+	quizAttemptRepo := repository.NewMongoQuizAttemptRepository(mongoDB)
+	studyService := service.NewStudyService(planRepo, quizAttemptRepo, userRepo, llmClient, verseService, cfg.LLMModelName)
+	suggestionService := service.NewSuggestionService(planService, quizAttemptRepo, themeCalendar, llmClient, cfg.LLMModelName)
+	usageService := service.NewUsageService(llmUsageRepo, llmBudget)
+	feedbackService := service.NewFeedbackService(chatService, verseService, repository.NewMongoFeedbackRepository(mongoDB))
+	if len(cfg.BootstrapAdminEmails) > 0 {
+		log.Printf("INFO: Bootstrap admin emails configured: %d", len(cfg.BootstrapAdminEmails))
+	}
 
 	// 4. API Handler (Inject all services)
-	apiHandler := api.NewAPIHandler(chatService, planService, verseService, authService, cfg.JWTSecret, cfg.CorsAllowedOrigin)
+	apiHandler := api.NewAPIHandler(chatService, planService, verseService, authService, userService, devotionalService, studyService, suggestionService, moderationService, feedbackService, usageService, jobScheduler, cfg.JWTSecret, cfg.CorsAllowedOrigin)
 
 	// 5. Router
 	router := api.NewRouter(apiHandler, cfg.CorsAllowedOrigin)
@@ -306,8 +406,23 @@ func main() {
 		// IdleTimeout:  120 * time.Second,
 	}
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("FATAL: Could not listen on %s: %v\n", serverAddr, err)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("FATAL: Could not listen on %s: %v\n", serverAddr, err)
+		}
+	}()
+
+	<-shutdownCtx.Done()
+	log.Println("INFO: Shutdown signal received, stopping server and background jobs...")
+
+	gracePeriod, cancelGrace := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelGrace()
+
+	if err := server.Shutdown(gracePeriod); err != nil {
+		log.Printf("ERROR: Server shutdown did not complete: %v", err)
+	}
+	if err := jobScheduler.Stop(gracePeriod); err != nil {
+		log.Printf("ERROR: Job scheduler shutdown did not complete: %v", err)
 	}
 
 	log.Println("INFO: Server stopped gracefully.")
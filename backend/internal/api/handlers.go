@@ -1,18 +1,25 @@
 package api
 
 import (
+	"archive/zip"
 	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/metering"
 	"bibleapp/backend/internal/repository" // Import repository for errors
+	"bibleapp/backend/internal/scheduler"
 	"bibleapp/backend/internal/service"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -29,6 +36,12 @@ type UserClaims struct {
 	Email    string
 	Name     string
 	Picture  string
+	Roles    []string
+}
+
+// Actor converts the claims into the identity services use for permission checks
+func (c UserClaims) Actor() domain.Actor {
+	return domain.Actor{UserID: c.UserID, Roles: c.Roles}
 }
 
 // APIHandler now includes AuthService, VerseService and JWT secret
@@ -37,17 +50,33 @@ type APIHandler struct {
 	planService       service.PlanService
 	verseService      service.VerseService // Add VerseService for on-demand verse content
 	authService       *service.AuthService // Add AuthService
-	jwtSecret         []byte               // Store JWT secret for middleware
-	corsAllowedOrigin string               // Store CORS allowed origin for redirects
+	userService       service.UserService  // User and role management
+	devotionalService service.DevotionalService
+	studyService      service.StudyService
+	suggestionService service.SuggestionService
+	moderationService service.ModerationService // Review queue of flagged chats
+	feedbackService   service.FeedbackService   // Ratings of chat answers
+	usageService      service.UsageService      // LLM tokens and cost
+	jobScheduler      *scheduler.Scheduler      // Background job status for admins
+	jwtSecret         []byte                    // Store JWT secret for middleware
+	corsAllowedOrigin string                    // Store CORS allowed origin for redirects
 }
 
 // Update NewAPIHandler
-func NewAPIHandler(cs service.ChatService, ps service.PlanService, vs service.VerseService, as *service.AuthService, jwtSecret string, corsAllowedOrigin string) *APIHandler {
+func NewAPIHandler(cs service.ChatService, ps service.PlanService, vs service.VerseService, as *service.AuthService, us service.UserService, ds service.DevotionalService, ss service.StudyService, sgs service.SuggestionService, ms service.ModerationService, fs service.FeedbackService, uss service.UsageService, js *scheduler.Scheduler, jwtSecret string, corsAllowedOrigin string) *APIHandler {
 	return &APIHandler{
 		chatService:       cs,
 		planService:       ps,
 		verseService:      vs, // Inject VerseService
 		authService:       as, // Inject AuthService
+		userService:       us,
+		devotionalService: ds,
+		studyService:      ss,
+		suggestionService: sgs,
+		moderationService: ms,
+		feedbackService:   fs,
+		usageService:      uss,
+		jobScheduler:      js,
 		jwtSecret:         []byte(jwtSecret),
 		corsAllowedOrigin: corsAllowedOrigin,
 	}
@@ -183,11 +212,13 @@ func (h *APIHandler) HandleGetCurrentUser(w http.ResponseWriter, r *http.Request
 	}
 
 	// Return relevant user info (don't expose everything from JWT if not needed)
-	userInfo := map[string]string{
-		"id":      userClaims.UserID,
-		"email":   userClaims.Email,
-		"name":    userClaims.Name,
-		"picture": userClaims.Picture,
+	userInfo := map[string]interface{}{
+		"id":          userClaims.UserID,
+		"email":       userClaims.Email,
+		"name":        userClaims.Name,
+		"picture":     userClaims.Picture,
+		"roles":       userClaims.Roles,
+		"permissions": domain.PermissionsForRoles(userClaims.Roles),
 		// Add other fields as needed by the frontend
 	}
 	writeJSON(w, http.StatusOK, userInfo)
@@ -247,20 +278,60 @@ func (h *APIHandler) AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Roles are loaded on every request rather than trusted from the token,
+		// so demoting or removing a user takes effect at once
+		user, err := h.userService.GetUser(r.Context(), userID)
+		if err != nil {
+			// A subject that isn't a valid user ID can't belong to anyone either
+			if err.Error() == "user not found" || strings.Contains(err.Error(), "invalid user ID format") {
+				log.Printf("WARN: Token for unknown user %s", userID)
+				writeError(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+			log.Printf("ERROR: Failed to load user %s: %v", userID, err)
+			writeError(w, "Failed to load user", http.StatusInternalServerError)
+			return
+		}
+		roles := user.EffectiveRoles()
+
 		userClaims := UserClaims{
 			UserID:   userID,
 			GoogleID: googleID,
 			Email:    email,
 			Name:     name,
 			Picture:  picture,
+			Roles:    roles,
 		}
 		ctx := context.WithValue(r.Context(), userContextKey, userClaims)
+		ctx = metering.WithUser(ctx, userClaims.UserID) // LLM requests made for this request count as theirs
 
 		// 5. Call the next handler with the updated context
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// RequirePermission rejects requests from users whose roles don't grant the permission.
+// It must run after AuthMiddleware.
+func (h *APIHandler) RequirePermission(perm domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userClaims, ok := UserFromContext(r.Context())
+			if !ok {
+				writeError(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			if !userClaims.Actor().Can(perm) {
+				log.Printf("WARN: User %s (roles %v) denied %s on %s %s", userClaims.UserID, userClaims.Roles, perm, r.Method, r.URL.Path)
+				writeError(w, "You don't have permission to do that", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // UserFromContext retrieves the UserClaims from the request context
 func UserFromContext(ctx context.Context) (UserClaims, bool) {
 	user, ok := ctx.Value(userContextKey).(UserClaims)
@@ -269,10 +340,15 @@ func UserFromContext(ctx context.Context) (UserClaims, bool) {
 
 // --- Plan Handlers (Modified for Auth) ---
 
+// maxMinutesPerDay caps the daily reading time target a plan can ask for
+const maxMinutesPerDay = 120
+
 // CreatePlanRequest remains the same
 type CreatePlanRequest struct {
-	Topic        string `json:"topic"`
-	DurationDays int    `json:"duration_days"`
+	Topic         string                  `json:"topic"`
+	DurationDays  int                     `json:"duration_days"`
+	MinutesPerDay int                     `json:"minutes_per_day,omitempty"` // Optional daily reading time target; 0 sets none
+	Audience      *domain.AudienceProfile `json:"audience,omitempty"`        // Overrides the user's audience profile for this plan, unless it is locked
 }
 
 // UpdatePlanRequest for plan updates
@@ -302,10 +378,19 @@ func (h *APIHandler) HandleCreatePlan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	targetAudience := "14-year-old niece" // Still hardcoded
+	if req.MinutesPerDay < 0 || req.MinutesPerDay > maxMinutesPerDay {
+		writeError(w, fmt.Sprintf("minutes_per_day must be between 1 and %d, or 0 for no target", maxMinutesPerDay), http.StatusBadRequest)
+		return
+	}
+
+	audience, err := h.userService.AudienceFor(r.Context(), userClaims.UserID, req.Audience)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Pass the authenticated user's ID to the service
-	plan, err := h.planService.CreatePlan(r.Context(), userClaims.UserID, req.Topic, req.DurationDays, targetAudience)
+	plan, err := h.planService.CreatePlan(r.Context(), userClaims.UserID, req.Topic, req.DurationDays, audience.Describe(), req.MinutesPerDay)
 	if err != nil {
 		log.Printf("ERROR: Plan creation failed for user %s: %v", userClaims.UserID, err)
 		writeError(w, "Failed to create reading plan.", http.StatusInternalServerError)
@@ -342,15 +427,11 @@ func (h *APIHandler) HandleDeletePlan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract plan ID from URL path or query parameter
-	planID := r.URL.Query().Get("id")
-	if planID == "" {
-		writeError(w, "Plan ID is required", http.StatusBadRequest)
-		return
-	}
+	// Extract plan ID from the URL path
+	planID := chi.URLParam(r, "id")
 
 	// Call service to delete plan
-	err := h.planService.DeletePlan(r.Context(), planID, userClaims.UserID)
+	err := h.planService.DeletePlan(r.Context(), planID, userClaims.Actor())
 	if err != nil {
 		log.Printf("ERROR: Failed to delete plan: %v", err)
 
@@ -389,12 +470,13 @@ func (h *APIHandler) HandleUpdatePlan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate request
-	if req.ID == "" {
-		writeError(w, "Plan ID is required", http.StatusBadRequest)
+	// The plan ID comes from the URL path; a body ID, if sent, must match it
+	if req.ID != "" && req.ID != chi.URLParam(r, "id") {
+		writeError(w, "Plan ID in body does not match URL", http.StatusBadRequest)
 		return
 	}
 
+	// Validate request
 	if req.Topic == "" {
 		writeError(w, "Topic is required", http.StatusBadRequest)
 		return
@@ -406,7 +488,7 @@ func (h *APIHandler) HandleUpdatePlan(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse UUID
-	planID, err := uuid.Parse(req.ID)
+	planID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
 		log.Printf("ERROR: Invalid plan ID format: %v", err)
 		writeError(w, "Invalid plan ID format", http.StatusBadRequest)
@@ -422,7 +504,7 @@ func (h *APIHandler) HandleUpdatePlan(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service to update plan
-	err = h.planService.UpdatePlan(r.Context(), plan, userClaims.UserID)
+	err = h.planService.UpdatePlan(r.Context(), plan, userClaims.Actor())
 	if err != nil {
 		log.Printf("ERROR: Failed to update plan: %v", err)
 
@@ -436,6 +518,11 @@ func (h *APIHandler) HandleUpdatePlan(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if strings.HasPrefix(err.Error(), "invalid plan") {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		writeError(w, "Failed to update plan", http.StatusInternalServerError)
 		return
 	}
@@ -444,7 +531,14 @@ func (h *APIHandler) HandleUpdatePlan(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"message": "Plan updated successfully"})
 }
 
-// HandleGetPlanVerseToday now gets the plan for the logged-in user
+// TodayReadingsResponse lists a date's readings across all of a user's plans
+type TodayReadingsResponse struct {
+	Date     string              `json:"date"`
+	Readings []domain.DailyVerse `json:"readings"`
+}
+
+// HandleGetPlanVerseToday gets the logged-in user's reading for today, or for ?date=YYYY-MM-DD.
+// By default it serves the active plan; ?scope=all returns the readings of every plan running that day.
 func (h *APIHandler) HandleGetPlanVerseToday(w http.ResponseWriter, r *http.Request) {
 	userClaims, ok := UserFromContext(r.Context())
 	if !ok {
@@ -452,128 +546,1580 @@ func (h *APIHandler) HandleGetPlanVerseToday(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	date, ok := dateFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.URL.Query().Get("scope") {
+	case "", "active":
+	case "all":
+		h.writeAllReadingsForDate(w, r, userClaims.UserID, date)
+		return
+	default:
+		writeError(w, "scope must be 'active' or 'all'", http.StatusBadRequest)
+		return
+	}
+
 	// Check if plan exists
-	verse, err := h.planService.GetActiveVerseForToday(r.Context(), userClaims.UserID)
+	verse, err := h.planService.GetVerseForDate(r.Context(), userClaims.UserID, date)
 	if err != nil {
 		if err.Error() == "no active reading plan found" {
 			writeError(w, "No active reading plan found for today.", http.StatusNotFound)
 		} else if errors.Is(err, repository.ErrDayOutOfRange) {
 			writeError(w, "Your reading plan is finished!", http.StatusOK)
 		} else {
-			log.Printf("ERROR: Failed to get today's plan verse for user %s: %v", userClaims.UserID, err)
+			log.Printf("ERROR: Failed to get plan verse for %s for user %s: %v", date.Format("2006-01-02"), userClaims.UserID, err)
 			writeError(w, "Failed to retrieve today's verse", http.StatusInternalServerError)
 		}
 		return
 	}
 
-	// Check if client explicitly requests no content via query parameter
-	skipContent := r.URL.Query().Get("content") == "false"
+	h.writeDailyVerse(w, r, verse)
+}
 
-	if !skipContent {
-		// By default, get full verse content using the verse service
-		enrichedVerse, err := h.planService.GetEnrichedVerseForToday(r.Context(), userClaims.UserID, h.verseService)
-		if err == nil {
-			// Return the verse with full content
-			writeJSON(w, http.StatusOK, enrichedVerse)
+// HandleGetPlan returns a single plan
+func (h *APIHandler) HandleGetPlan(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	planID := chi.URLParam(r, "id")
+	plan, err := h.planService.GetPlan(r.Context(), planID, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to get plan %s: %v", planID, err)
+		writePlanServiceError(w, err, "Failed to retrieve plan")
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// HandleGetPlanDay returns one day of a plan, so readers can read ahead, revisit past days and share links
+func (h *APIHandler) HandleGetPlanDay(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	planID, dayNumber, ok := planDayFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	verse, err := h.planService.GetPlanDay(r.Context(), planID, dayNumber, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to get day %d of plan %s: %v", dayNumber, planID, err)
+		if errors.Is(err, repository.ErrDayOutOfRange) {
+			writeError(w, "Day not found in plan", http.StatusNotFound)
 			return
 		}
+		writePlanServiceError(w, err, "Failed to retrieve plan day")
+		return
+	}
+
+	h.writeDailyVerse(w, r, verse)
+}
+
+// writeAllReadingsForDate sends the readings of every plan the user is reading on a date
+func (h *APIHandler) writeAllReadingsForDate(w http.ResponseWriter, r *http.Request, userID string, date time.Time) {
+	verses, err := h.planService.GetVersesForDate(r.Context(), userID, date)
+	if err != nil {
+		if errors.Is(err, repository.ErrDayOutOfRange) {
+			writeError(w, "Your reading plans are finished!", http.StatusOK)
+		} else {
+			log.Printf("ERROR: Failed to get readings for %s for user %s: %v", date.Format("2006-01-02"), userID, err)
+			writeError(w, "No reading plan found for this date.", http.StatusNotFound)
+		}
+		return
+	}
+
+	withContent := r.URL.Query().Get("content") != "false"
+	readings := make([]domain.DailyVerse, len(verses))
+	for i, verse := range verses {
+		if withContent {
+			verse = h.withDailyContent(r.Context(), verse)
+		}
+		readings[i] = verse
+	}
+	writeJSON(w, http.StatusOK, TodayReadingsResponse{Date: date.Format("2006-01-02"), Readings: readings})
+}
 
+// writeDailyVerse sends a day's reading with its passage text and devotional.
+// ?content=false returns only the reference and title.
+func (h *APIHandler) writeDailyVerse(w http.ResponseWriter, r *http.Request, verse domain.DailyVerse) {
+	if r.URL.Query().Get("content") == "false" {
+		writeJSON(w, http.StatusOK, verse)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.withDailyContent(r.Context(), verse))
+}
+
+// withDailyContent adds the passage text and devotional to a day's reading
+func (h *APIHandler) withDailyContent(ctx context.Context, verse domain.DailyVerse) domain.DailyVerse {
+	// Get full verse content using the verse service
+	enrichedVerse, err := h.verseService.EnrichDailyVerse(ctx, verse)
+	if err != nil {
+		log.Printf("ERROR: Failed to enrich verse with content: %v", err)
 		// Still return the verse with just the reference, but add an error message
 		verse.Text = "[Error fetching verse content. Please try again.]"
-		writeJSON(w, http.StatusOK, verse)
+		return verse
+	}
+
+	// Add the day's devotional, generated on the first view; a failure only omits it
+	withDevotional, err := h.devotionalService.EnsureDevotional(ctx, enrichedVerse)
+	if err != nil {
+		log.Printf("WARN: Serving day %d of plan %s without devotional: %v", enrichedVerse.DayNumber, enrichedVerse.PlanID, err)
+	}
+	return withDevotional
+}
+
+// HandleGetTopicSuggestions recommends plan topics for the user (?limit=, default 5)
+func (h *APIHandler) HandleGetTopicSuggestions(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
-	// Only return the reference and title if explicitly requested
-	writeJSON(w, http.StatusOK, verse)
+	limit := 5
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > service.MaxTopicSuggestions {
+			writeError(w, fmt.Sprintf("limit must be between 1 and %d", service.MaxTopicSuggestions), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := h.suggestionService.SuggestTopics(r.Context(), userClaims.UserID, limit)
+	if err != nil {
+		log.Printf("ERROR: Failed to suggest topics for user %s: %v", userClaims.UserID, err)
+		writeError(w, "Failed to suggest topics", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, suggestions)
 }
 
-// --- Chat Handlers (Can also be protected) ---
+// HandleActivatePlan makes a plan the user's active plan
+func (h *APIHandler) HandleActivatePlan(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
 
-type ChatRequest struct {
-	Verse    domain.DailyVerse `json:"verse"`
-	Question string            `json:"question"`
+	planID := chi.URLParam(r, "id")
+	plan, err := h.planService.ActivatePlan(r.Context(), planID, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to activate plan %s: %v", planID, err)
+		writePlanServiceError(w, err, "Failed to activate plan")
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
 }
 
-type ChatResponse struct {
-	Answer     string `json:"answer"`
-	UsageToday int    `json:"usage_today,omitempty"`
-	DailyLimit int    `json:"daily_limit,omitempty"`
+// SetPlanPriorityRequest orders a plan among the user's concurrent plans
+type SetPlanPriorityRequest struct {
+	Priority int `json:"priority"`
 }
 
-// HandleChat requires authentication
-func (h *APIHandler) HandleChat(w http.ResponseWriter, r *http.Request) {
+// HandleSetPlanPriority sets the order of a plan among the user's concurrent plans
+func (h *APIHandler) HandleSetPlanPriority(w http.ResponseWriter, r *http.Request) {
 	userClaims, ok := UserFromContext(r.Context())
 	if !ok {
-		writeError(w, "User authentication failed", http.StatusUnauthorized)
+		writeError(w, "Authentication required", http.StatusUnauthorized)
 		return
 	}
 
-	var req ChatRequest
+	var req SetPlanPriorityRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, "Invalid request format", http.StatusBadRequest)
 		return
 	}
-	if req.Question == "" {
-		writeError(w, "Question cannot be empty", http.StatusBadRequest)
+
+	planID := chi.URLParam(r, "id")
+	plan, err := h.planService.SetPlanPriority(r.Context(), planID, req.Priority, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to set priority of plan %s: %v", planID, err)
+		writePlanServiceError(w, err, "Failed to set plan priority")
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// HandleRegenerateDevotional replaces the cached devotional of a plan day
+func (h *APIHandler) HandleRegenerateDevotional(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	planID, dayNumber, ok := planDayFromURL(w, r)
+	if !ok {
 		return
 	}
 
-	// Pass user ID for rate limiting
-	answer, err := h.chatService.GetResponse(r.Context(), req.Verse, req.Question, userClaims.UserID)
+	verse, err := h.devotionalService.RegenerateDevotional(r.Context(), planID, dayNumber, userClaims.Actor())
 	if err != nil {
-		log.Printf("ERROR: Failed to get chat response for user %s: %v", userClaims.UserID, err)
-		if errors.Is(err, context.DeadlineExceeded) {
-			writeError(w, "Chatbot request timed out.", http.StatusGatewayTimeout)
-		} else if _, ok := err.(service.ErrRateLimitExceeded); ok {
-			// Special case for rate limiting with a friendly message
-			writeError(w, "⏰ Daily chat limit reached. Try again tomorrow! We're working on increasing limits soon.", http.StatusTooManyRequests)
-		} else {
-			writeError(w, "Chatbot couldn't answer right now.", http.StatusInternalServerError)
+		log.Printf("ERROR: Failed to regenerate devotional for day %d of plan %s: %v", dayNumber, planID, err)
+		if errors.Is(err, repository.ErrDayOutOfRange) {
+			writeError(w, "Day not found in plan", http.StatusNotFound)
+			return
 		}
+		writePlanServiceError(w, err, "Failed to regenerate devotional")
 		return
 	}
+	writeJSON(w, http.StatusOK, verse)
+}
 
-	// Get current usage stats to include in response
-	currentUsage, dailyLimit, _ := h.chatService.GetChatUsage(r.Context(), userClaims.UserID)
+// HandleListPlanRevisions returns the revision history of a plan
+func (h *APIHandler) HandleListPlanRevisions(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
 
-	// Include usage information in the response
-	writeJSON(w, http.StatusOK, ChatResponse{
-		Answer:     answer,
-		UsageToday: currentUsage,
-		DailyLimit: dailyLimit,
+	planID := chi.URLParam(r, "id")
+
+	revisions, err := h.planService.ListPlanRevisions(r.Context(), planID, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to list revisions of plan %s: %v", planID, err)
+		writePlanServiceError(w, err, "Failed to retrieve plan revisions")
+		return
+	}
+	writeJSON(w, http.StatusOK, revisions)
+}
+
+// HandleDiffPlanRevisions shows the day-level differences between two revisions
+func (h *APIHandler) HandleDiffPlanRevisions(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	planID := chi.URLParam(r, "id")
+
+	fromRevision, errFrom := strconv.Atoi(query.Get("from"))
+	toRevision, errTo := strconv.Atoi(query.Get("to"))
+	if errFrom != nil || errTo != nil || fromRevision <= 0 || toRevision <= 0 {
+		writeError(w, "Positive 'from' and 'to' revision numbers are required", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.planService.DiffPlanRevisions(r.Context(), planID, fromRevision, toRevision, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to diff revisions %d..%d of plan %s: %v", fromRevision, toRevision, planID, err)
+		writePlanServiceError(w, err, "Failed to compare plan revisions")
+		return
+	}
+	writeJSON(w, http.StatusOK, diff)
+}
+
+// HandleRollbackPlan restores a plan to an earlier revision
+func (h *APIHandler) HandleRollbackPlan(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	planID := chi.URLParam(r, "id")
+
+	revision, err := strconv.Atoi(r.URL.Query().Get("revision"))
+	if err != nil || revision <= 0 {
+		writeError(w, "A positive revision number is required", http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.planService.RollbackPlan(r.Context(), planID, revision, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to roll back plan %s to revision %d: %v", planID, revision, err)
+		writePlanServiceError(w, err, "Failed to roll back plan")
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// HandleRevalidatePlan re-checks a single stored plan against the verse repository
+func (h *APIHandler) HandleRevalidatePlan(w http.ResponseWriter, r *http.Request) {
+	planID := chi.URLParam(r, "id")
+	report, err := h.planService.RevalidatePlan(r.Context(), planID)
+	if err != nil {
+		log.Printf("ERROR: Failed to revalidate plan %s: %v", planID, err)
+		if err.Error() == "plan not found" {
+			writeError(w, "Plan not found", http.StatusNotFound)
+			return
+		}
+		writeError(w, "Failed to revalidate plan", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// HandleRevalidatePlans re-checks every stored plan against the verse repository
+func (h *APIHandler) HandleRevalidatePlans(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.planService.RevalidateAllPlans(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to revalidate plans: %v", err)
+		writeError(w, "Failed to revalidate plans", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
+
+// --- Job Handlers ---
+
+// HandleListJobs lists the scheduled background jobs with their last and next runs
+func (h *APIHandler) HandleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.jobScheduler.Jobs(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to list jobs: %v", err)
+		writeError(w, "Failed to list jobs", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// HandleTriggerJob makes a job due immediately; the next replica to poll runs it
+func (h *APIHandler) HandleTriggerJob(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := h.jobScheduler.Trigger(r.Context(), name); err != nil {
+		log.Printf("ERROR: Failed to trigger job '%s': %v", name, err)
+		if errors.Is(err, repository.ErrJobNotFound) {
+			writeError(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		writeError(w, "Failed to trigger job", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "triggered", "job": name})
+}
+
+// --- Default Track Handlers ---
+
+// TracksResponse lists the default plan tracks and the user's current choice
+type TracksResponse struct {
+	Tracks   []domain.PlanTrack `json:"tracks"`
+	Selected string             `json:"selected"`
+}
+
+// SelectTrackRequest chooses a default plan track
+type SelectTrackRequest struct {
+	TrackID string `json:"track_id"`
+}
+
+// HandleListTracks lists the default plan tracks a user can follow
+func (h *APIHandler) HandleListTracks(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "User authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	selected := h.planService.GetUserTrack(r.Context(), userClaims.UserID)
+	writeJSON(w, http.StatusOK, TracksResponse{
+		Tracks:   h.planService.ListTracks(),
+		Selected: selected.ID,
 	})
 }
 
-// HandleResetChat requires authentication
-func (h *APIHandler) HandleResetChat(w http.ResponseWriter, r *http.Request) {
+// HandleSelectTrack sets the default plan track the user reads when they have no plan of their own
+func (h *APIHandler) HandleSelectTrack(w http.ResponseWriter, r *http.Request) {
 	userClaims, ok := UserFromContext(r.Context())
 	if !ok {
 		writeError(w, "User authentication failed", http.StatusUnauthorized)
 		return
 	}
 
-	// Pass user context if the chat service needs it (e.g., to clear user-specific history)
-	// Example: err := h.chatService.ResetChatHistoryForUser(r.Context(), userClaims.UserID)
-	err := h.chatService.ResetChatHistory(r.Context())
+	var req SelectTrackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TrackID == "" {
+		writeError(w, "track_id is required", http.StatusBadRequest)
+		return
+	}
+
+	track, err := h.planService.SelectTrack(r.Context(), userClaims.UserID, req.TrackID)
 	if err != nil {
-		log.Printf("ERROR: Failed to reset chat history for user %s: %v", userClaims.UserID, err)
-		writeError(w, "Failed to reset chat history", http.StatusInternalServerError)
+		log.Printf("ERROR: Failed to select track '%s' for user %s: %v", req.TrackID, userClaims.UserID, err)
+		if err.Error() == "track not found" {
+			writeError(w, "Track not found", http.StatusNotFound)
+			return
+		}
+		writeError(w, "Failed to select track", http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]string{"message": "Chat history reset successfully"})
+	writeJSON(w, http.StatusOK, track)
 }
 
-// --- Helper Functions ---
+// SetTimezoneRequest sets the time zone the user's daily chat limit resets in
+type SetTimezoneRequest struct {
+	Timezone string `json:"timezone"` // IANA name such as "Europe/Berlin"; empty means UTC
+}
 
-func writeJSON(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if data != nil {
-		if err := json.NewEncoder(w).Encode(data); err != nil {
-			log.Printf("ERROR: Failed to write JSON response: %v", err)
-			http.Error(w, `{"error":"Failed to encode response"}`, http.StatusInternalServerError)
+// HandleSetTimezone stores the logged-in user's time zone, usually the browser's
+func (h *APIHandler) HandleSetTimezone(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "User authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	var req SetTimezoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.SetTimezone(r.Context(), userClaims.UserID, req.Timezone)
+	if err != nil {
+		log.Printf("ERROR: Failed to set timezone of user %s: %v", userClaims.UserID, err)
+		switch {
+		case err.Error() == "user not found":
+			writeError(w, "User not found", http.StatusNotFound)
+		case strings.HasPrefix(err.Error(), "invalid timezone"):
+			writeError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeError(w, "Failed to update timezone", http.StatusInternalServerError)
 		}
+		return
+	}
+	writeJSON(w, http.StatusOK, SetTimezoneRequest{Timezone: user.Timezone})
+}
+
+// --- Audience Handlers ---
+
+// AudienceResponse is a user's stored audience profile and the profile answers are actually written for
+type AudienceResponse struct {
+	Audience  *domain.AudienceProfile `json:"audience"` // Null when the user hasn't chosen one
+	Effective domain.AudienceProfile  `json:"effective"`
+	Locked    bool                    `json:"locked"` // Set by an admin or guardian; the user can't change it
+}
+
+// HandleGetAudience returns the logged-in user's audience profile
+func (h *APIHandler) HandleGetAudience(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "User authentication failed", http.StatusUnauthorized)
+		return
+	}
+	h.writeAudience(w, r, userClaims.UserID)
+}
+
+// HandleSetAudience stores the logged-in user's audience profile; an empty profile resets it
+func (h *APIHandler) HandleSetAudience(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "User authentication failed", http.StatusUnauthorized)
+		return
+	}
+	h.setAudience(w, r, userClaims.UserID, false)
+}
+
+// HandleSetUserAudience stores another user's audience profile, e.g. for a child's account.
+// The profile is locked so the user can't change it; an empty profile resets and unlocks it.
+func (h *APIHandler) HandleSetUserAudience(w http.ResponseWriter, r *http.Request) {
+	h.setAudience(w, r, chi.URLParam(r, "userID"), true)
+}
+
+// HandleSetWardAudience stores and locks the audience profile of one of the guardian's wards;
+// an empty profile resets and unlocks it
+func (h *APIHandler) HandleSetWardAudience(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	actor := userClaims.Actor()
+	h.setAudienceWith(w, r, chi.URLParam(r, "userID"), func(userID string, profile *domain.AudienceProfile) error {
+		_, err := h.userService.SetWardAudience(r.Context(), userID, profile, actor)
+		return err
+	})
+}
+
+func (h *APIHandler) setAudience(w http.ResponseWriter, r *http.Request, userID string, managed bool) {
+	h.setAudienceWith(w, r, userID, func(userID string, profile *domain.AudienceProfile) error {
+		_, err := h.userService.SetAudience(r.Context(), userID, profile, managed)
+		return err
+	})
+}
+
+// setAudienceWith decodes a profile, stores it with set and answers with the user's audience
+func (h *APIHandler) setAudienceWith(w http.ResponseWriter, r *http.Request, userID string, set func(userID string, profile *domain.AudienceProfile) error) {
+	var profile domain.AudienceProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := set(userID, &profile); err != nil {
+		log.Printf("ERROR: Failed to set audience of user %s: %v", userID, err)
+		switch {
+		case err.Error() == "user not found":
+			writeError(w, "User not found", http.StatusNotFound)
+		case strings.HasPrefix(err.Error(), "unauthorized"):
+			writeError(w, "Not a guardian of this user", http.StatusForbidden)
+		case strings.HasPrefix(err.Error(), "audience locked"):
+			writeError(w, "Your audience profile was set by an admin or guardian and can't be changed", http.StatusForbidden)
+		case strings.HasPrefix(err.Error(), "invalid audience"):
+			writeError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeError(w, "Failed to update audience", http.StatusInternalServerError)
+		}
+		return
+	}
+	h.writeAudience(w, r, userID)
+}
+
+func (h *APIHandler) writeAudience(w http.ResponseWriter, r *http.Request, userID string) {
+	user, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("ERROR: Failed to get audience of user %s: %v", userID, err)
+		writeError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	effective, _ := h.userService.AudienceFor(r.Context(), userID, nil)
+	writeJSON(w, http.StatusOK, AudienceResponse{Audience: user.Audience, Effective: effective, Locked: user.AudienceLocked})
+}
+
+// --- User Admin Handlers ---
+
+// SetRolesRequest replaces a user's roles
+type SetRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+// HandleListUsers lists all users with their roles
+func (h *APIHandler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.userService.ListUsers(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to list users: %v", err)
+		writeError(w, "Failed to retrieve users", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+// HandleSetUserRoles replaces a user's roles
+func (h *APIHandler) HandleSetUserRoles(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+
+	var req SetRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.SetRoles(r.Context(), userID, req.Roles)
+	if err != nil {
+		log.Printf("ERROR: User %s failed to set roles of user %s: %v", userClaims.UserID, userID, err)
+		switch {
+		case err.Error() == "user not found":
+			writeError(w, "User not found", http.StatusNotFound)
+		case strings.HasPrefix(err.Error(), "invalid roles"):
+			writeError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeError(w, "Failed to update roles", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("INFO: User %s set roles of user %s to %v", userClaims.UserID, userID, user.Roles)
+	writeJSON(w, http.StatusOK, user)
+}
+
+// SetGuardiansRequest replaces the guardians linked to a user
+type SetGuardiansRequest struct {
+	GuardianIDs []string `json:"guardian_ids"`
+}
+
+// HandleSetUserGuardians links guardians to a user
+func (h *APIHandler) HandleSetUserGuardians(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+
+	var req SetGuardiansRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.SetGuardians(r.Context(), userID, req.GuardianIDs)
+	if err != nil {
+		log.Printf("ERROR: User %s failed to set guardians of user %s: %v", userClaims.UserID, userID, err)
+		switch {
+		case err.Error() == "user not found":
+			writeError(w, "User not found", http.StatusNotFound)
+		case strings.HasPrefix(err.Error(), "invalid guardians"):
+			writeError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeError(w, "Failed to update guardians", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// SetChatLimitRequest overrides a user's daily chat limit
+type SetChatLimitRequest struct {
+	DailyLimit *int `json:"daily_limit"` // Null goes back to the role limits; 0 means unlimited
+}
+
+// HandleSetUserChatLimit sets or clears a user's own daily chat limit
+func (h *APIHandler) HandleSetUserChatLimit(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+
+	var req SetChatLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.SetChatLimit(r.Context(), userID, req.DailyLimit)
+	if err != nil {
+		log.Printf("ERROR: User %s failed to set chat limit of user %s: %v", userClaims.UserID, userID, err)
+		switch {
+		case err.Error() == "user not found":
+			writeError(w, "User not found", http.StatusNotFound)
+		case strings.HasPrefix(err.Error(), "invalid limit"):
+			writeError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeError(w, "Failed to update chat limit", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// --- Study Handlers ---
+
+// SubmitQuizRequest carries the selected option index for each quiz question, in order
+type SubmitQuizRequest struct {
+	Answers []int `json:"answers"`
+}
+
+// HandleGetDayStudy returns the reflection questions and quiz of a plan day
+func (h *APIHandler) HandleGetDayStudy(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	planID, dayNumber, ok := planDayFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	study, err := h.studyService.GetDayStudy(r.Context(), planID, dayNumber, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to get study for day %d of plan %s: %v", dayNumber, planID, err)
+		writeStudyError(w, err, "Failed to retrieve study questions")
+		return
+	}
+	writeJSON(w, http.StatusOK, study)
+}
+
+// HandleGetTodayStudy returns the reflection questions and quiz of the user's reading for today, or for ?date=YYYY-MM-DD
+func (h *APIHandler) HandleGetTodayStudy(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	date, ok := dateFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	verse, err := h.planService.GetVerseForDate(r.Context(), userClaims.UserID, date)
+	if err != nil {
+		log.Printf("ERROR: Failed to get today's verse for study of user %s: %v", userClaims.UserID, err)
+		writeError(w, "No reading found for today", http.StatusNotFound)
+		return
+	}
+
+	study, err := h.studyService.GetDayStudy(r.Context(), verse.PlanID, verse.DayNumber, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to get study for day %d of plan %s: %v", verse.DayNumber, verse.PlanID, err)
+		writeStudyError(w, err, "Failed to retrieve study questions")
+		return
+	}
+	writeJSON(w, http.StatusOK, study)
+}
+
+// HandleSubmitQuiz scores answers to a plan day's quiz and records the attempt
+func (h *APIHandler) HandleSubmitQuiz(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	planID, dayNumber, ok := planDayFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req SubmitQuizRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.studyService.SubmitQuiz(r.Context(), planID, dayNumber, req.Answers, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to submit quiz for day %d of plan %s: %v", dayNumber, planID, err)
+		writeStudyError(w, err, "Failed to submit quiz")
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// --- Guardian Handlers ---
+
+// HandleListWards lists the users linked to the current guardian
+func (h *APIHandler) HandleListWards(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	wards, err := h.userService.ListWards(r.Context(), userClaims.UserID)
+	if err != nil {
+		log.Printf("ERROR: Failed to list wards of guardian %s: %v", userClaims.UserID, err)
+		writeError(w, "Failed to retrieve wards", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, wards)
+}
+
+// HandleGetWardEngagement summarizes a ward's quiz activity over the last ?days= days (default 30)
+func (h *APIHandler) HandleGetWardEngagement(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+	days := 30
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 365 {
+			writeError(w, "days must be between 1 and 365", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	engagement, err := h.studyService.GetEngagement(r.Context(), userID, days, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Guardian %s failed to get engagement of user %s: %v", userClaims.UserID, userID, err)
+		switch {
+		case err.Error() == "user not found":
+			writeError(w, "User not found", http.StatusNotFound)
+		case strings.Contains(err.Error(), "unauthorized"):
+			writeError(w, "Not a guardian of this user", http.StatusForbidden)
+		default:
+			writeError(w, "Failed to retrieve engagement", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, engagement)
+}
+
+// --- Moderation Review Handlers ---
+
+// ReviewFlagRequest moves a moderation flag to a new status
+type ReviewFlagRequest struct {
+	Status string `json:"status"` // "open", "resolved" or "dismissed"
+	Note   string `json:"note,omitempty"`
+}
+
+// HandleListModerationFlags lists the flagged chats the current guardian or admin may review, newest first
+func (h *APIHandler) HandleListModerationFlags(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	flags, err := h.moderationService.ListFlags(r.Context(), r.URL.Query().Get("status"), userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: Failed to list moderation flags for user %s: %v", userClaims.UserID, err)
+		writeModerationError(w, err, "Failed to retrieve flagged chats")
+		return
+	}
+	writeJSON(w, http.StatusOK, flags)
+}
+
+// HandleReviewModerationFlag records a guardian's or admin's review of a flagged chat
+func (h *APIHandler) HandleReviewModerationFlag(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req ReviewFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	flagID := chi.URLParam(r, "flagID")
+	flag, err := h.moderationService.ReviewFlag(r.Context(), flagID, req.Status, req.Note, userClaims.Actor())
+	if err != nil {
+		log.Printf("ERROR: User %s failed to review moderation flag %s: %v", userClaims.UserID, flagID, err)
+		writeModerationError(w, err, "Failed to review flagged chat")
+		return
+	}
+	writeJSON(w, http.StatusOK, flag)
+}
+
+// writeModerationError maps moderation service errors to HTTP responses
+func writeModerationError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case err.Error() == "moderation flag not found":
+		writeError(w, "Flagged chat not found", http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "invalid"):
+		writeError(w, err.Error(), http.StatusBadRequest)
+	case strings.Contains(err.Error(), "unauthorized"):
+		writeError(w, "Not a guardian of this user", http.StatusForbidden)
+	default:
+		writeError(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// --- Chat Handlers (Can also be protected) ---
+
+type ChatRequest struct {
+	ConversationID string                  `json:"conversation_id,omitempty"` // Empty starts a new conversation
+	Verse          domain.DailyVerse       `json:"verse"`
+	Question       string                  `json:"question"`
+	EditMessageID  string                  `json:"edit_message_id,omitempty"` // Earlier question the new one replaces, starting a branch
+	Regenerate     bool                    `json:"regenerate,omitempty"`      // Answer the branch's last question again instead
+	Audience       *domain.AudienceProfile `json:"audience,omitempty"`        // Overrides the user's audience profile for this answer, unless it is locked
+}
+
+// chatQuestion turns a chat request into the question to answer
+func (req ChatRequest) chatQuestion() service.ChatQuestion {
+	return service.ChatQuestion{
+		ConversationID: req.ConversationID,
+		Text:           req.Question,
+		EditOf:         req.EditMessageID,
+		Regenerate:     req.Regenerate,
+	}
+}
+
+type ChatResponse struct {
+	ConversationID string            `json:"conversation_id"`
+	QuestionID     string            `json:"question_id"`
+	MessageID      string            `json:"message_id"` // The answer, for rating it
+	Answer         string            `json:"answer"`
+	Citations      []domain.Citation `json:"citations"` // Passages the answer cites, for showing sources
+	UsageToday     int               `json:"usage_today,omitempty"`
+	DailyLimit     int               `json:"daily_limit,omitempty"`
+}
+
+// HandleChat requires authentication
+func (h *APIHandler) HandleChat(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "User authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Question == "" && !req.Regenerate {
+		writeError(w, "Question cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	audience, err := h.userService.AudienceFor(r.Context(), userClaims.UserID, req.Audience)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Pass user ID for rate limiting and conversation ownership
+	reply, err := h.chatService.GetResponse(r.Context(), userClaims.UserID, req.chatQuestion(), req.Verse, audience)
+	if err != nil {
+		log.Printf("ERROR: Failed to get chat response for user %s: %v", userClaims.UserID, err)
+		message, status := chatErrorResponse(err)
+		writeError(w, message, status)
+		return
+	}
+
+	// Get current usage stats to include in response
+	currentUsage, dailyLimit, _ := h.chatService.GetChatUsage(r.Context(), userClaims.UserID)
+
+	// Include usage information in the response
+	writeJSON(w, http.StatusOK, ChatResponse{
+		ConversationID: reply.ConversationID,
+		QuestionID:     reply.QuestionID,
+		MessageID:      reply.MessageID,
+		Answer:         reply.Answer,
+		Citations:      reply.Citations,
+		UsageToday:     currentUsage,
+		DailyLimit:     dailyLimit,
+	})
+}
+
+// ChatDelta is a piece of a streamed answer
+type ChatDelta struct {
+	Content string `json:"content"`
+}
+
+// HandleChatStream answers like HandleChat but streams the answer as server-sent events:
+// "token" events carry ChatDelta pieces, a "reset" event drops the pieces sent so far, and a
+// final "done" event carries the ChatResponse.
+// Failures before the first token are plain JSON errors; later ones are sent as an "error" event.
+func (h *APIHandler) HandleChatStream(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "User authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Question == "" && !req.Regenerate {
+		writeError(w, "Question cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	audience, err := h.userService.AudienceFor(r.Context(), userClaims.UserID, req.Audience)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	streaming := false
+	onDelta := func(delta string) error {
+		if !streaming {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("X-Accel-Buffering", "no") // Keep reverse proxies from buffering the stream
+			w.WriteHeader(http.StatusOK)
+			streaming = true
+		}
+		if err := writeSSE(w, "token", ChatDelta{Content: delta}); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return r.Context().Err() // Stop generating once the client has gone
+	}
+	onReset := func() error {
+		if err := writeSSE(w, "reset", struct{}{}); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return r.Context().Err()
+	}
+
+	reply, err := h.chatService.StreamResponse(r.Context(), userClaims.UserID, req.chatQuestion(), req.Verse, audience, onDelta, onReset)
+	if err != nil {
+		log.Printf("ERROR: Failed to stream chat response for user %s: %v", userClaims.UserID, err)
+		message, status := chatErrorResponse(err)
+		if !streaming {
+			writeError(w, message, status)
+			return
+		}
+		writeSSE(w, "error", ErrorResponse{Error: message})
+		flusher.Flush()
+		return
+	}
+
+	currentUsage, dailyLimit, _ := h.chatService.GetChatUsage(r.Context(), userClaims.UserID)
+	writeSSE(w, "done", ChatResponse{
+		ConversationID: reply.ConversationID,
+		QuestionID:     reply.QuestionID,
+		MessageID:      reply.MessageID,
+		Answer:         reply.Answer,
+		Citations:      reply.Citations,
+		UsageToday:     currentUsage,
+		DailyLimit:     dailyLimit,
+	})
+	flusher.Flush()
+}
+
+// writeSSE writes one server-sent event with a JSON payload
+func writeSSE(w http.ResponseWriter, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
+
+// chatErrorResponse maps chat service errors to a user-facing message and status
+func chatErrorResponse(err error) (string, int) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "Chatbot request timed out.", http.StatusGatewayTimeout
+	}
+	if _, ok := err.(service.ErrRateLimitExceeded); ok {
+		// Special case for rate limiting with a friendly message
+		return "⏰ Daily chat limit reached. Try again tomorrow! We're working on increasing limits soon.", http.StatusTooManyRequests
+	}
+	if errors.Is(err, metering.ErrBudgetExceeded) {
+		return "The Bible helper is resting until next month. Please try again later.", http.StatusServiceUnavailable
+	}
+	if _, ok := err.(service.ErrContentBlocked); ok {
+		return "Sorry, that question can't be answered here. If something is troubling you, please talk to a parent or another trusted adult.", http.StatusUnprocessableEntity
+	}
+	switch {
+	case err.Error() == "conversation not found":
+		return "Conversation not found", http.StatusNotFound
+	case err.Error() == "message not found":
+		return "Message not found", http.StatusNotFound
+	case err.Error() == "nothing to regenerate", strings.HasPrefix(err.Error(), "invalid edit"):
+		return err.Error(), http.StatusBadRequest
+	}
+	return "Chatbot couldn't answer right now.", http.StatusInternalServerError
+}
+
+// ResetChatRequest names the conversation to clear
+type ResetChatRequest struct {
+	ConversationID string `json:"conversation_id"`
+}
+
+// HandleResetChat clears the messages of one of the user's conversations
+func (h *APIHandler) HandleResetChat(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "User authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	var req ResetChatRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.ConversationID == "" {
+		writeError(w, "conversation_id is required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.chatService.ResetChatHistory(r.Context(), userClaims.UserID, req.ConversationID)
+	if err != nil {
+		log.Printf("ERROR: Failed to reset chat history for user %s: %v", userClaims.UserID, err)
+		writeConversationError(w, err, "Failed to reset chat history")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Chat history reset successfully"})
+}
+
+// HandleListConversations lists the user's conversations, optionally for one plan day (?plan_id=&day=)
+func (h *APIHandler) HandleListConversations(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	filter := repository.ConversationFilter{PlanID: r.URL.Query().Get("plan_id")}
+	if raw := r.URL.Query().Get("day"); raw != "" {
+		day, err := strconv.Atoi(raw)
+		if err != nil || day < 1 {
+			writeError(w, "day must be a positive number", http.StatusBadRequest)
+			return
+		}
+		filter.DayNumber = day
+	}
+
+	conversations, err := h.chatService.ListConversations(r.Context(), userClaims.UserID, filter)
+	if err != nil {
+		log.Printf("ERROR: Failed to list conversations for user %s: %v", userClaims.UserID, err)
+		writeError(w, "Failed to retrieve conversations", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, conversations)
+}
+
+// ConversationResponse is a conversation showing one branch, with the alternatives to each message
+type ConversationResponse struct {
+	*domain.Conversation
+	Messages []domain.BranchMessage `json:"messages"`
+}
+
+// conversationResponse shows the conversation's current branch
+func conversationResponse(conversation *domain.Conversation) ConversationResponse {
+	return ConversationResponse{Conversation: conversation, Messages: conversation.BranchMessages()}
+}
+
+// HandleGetConversation opens one of the user's conversations at the branch last shown
+func (h *APIHandler) HandleGetConversation(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	conversation, err := h.chatService.GetConversation(r.Context(), userClaims.UserID, chi.URLParam(r, "conversationID"))
+	if err != nil {
+		log.Printf("ERROR: Failed to get conversation for user %s: %v", userClaims.UserID, err)
+		writeConversationError(w, err, "Failed to retrieve conversation")
+		return
+	}
+	writeJSON(w, http.StatusOK, conversationResponse(conversation))
+}
+
+// SwitchBranchRequest names a message of the branch to show
+type SwitchBranchRequest struct {
+	MessageID string `json:"message_id"`
+}
+
+// HandleSwitchBranch shows the branch of one of the user's conversations that contains a message
+func (h *APIHandler) HandleSwitchBranch(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req SwitchBranchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MessageID == "" {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	conversation, err := h.chatService.SwitchBranch(r.Context(), userClaims.UserID, chi.URLParam(r, "conversationID"), req.MessageID)
+	if err != nil {
+		log.Printf("ERROR: Failed to switch branch for user %s: %v", userClaims.UserID, err)
+		if err.Error() == "message not found" {
+			writeError(w, "Message not found", http.StatusNotFound)
+			return
+		}
+		writeConversationError(w, err, "Failed to switch branch")
+		return
+	}
+	writeJSON(w, http.StatusOK, conversationResponse(conversation))
+}
+
+// RenameConversationRequest sets a conversation's title
+type RenameConversationRequest struct {
+	Title string `json:"title"`
+}
+
+// HandleRenameConversation changes the title of one of the user's conversations
+func (h *APIHandler) HandleRenameConversation(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req RenameConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	conversation, err := h.chatService.RenameConversation(r.Context(), userClaims.UserID, chi.URLParam(r, "conversationID"), req.Title)
+	if err != nil {
+		log.Printf("ERROR: Failed to rename conversation for user %s: %v", userClaims.UserID, err)
+		writeConversationError(w, err, "Failed to rename conversation")
+		return
+	}
+	writeJSON(w, http.StatusOK, conversationResponse(conversation))
+}
+
+// HandleDeleteConversation removes one of the user's conversations
+func (h *APIHandler) HandleDeleteConversation(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.chatService.DeleteConversation(r.Context(), userClaims.UserID, chi.URLParam(r, "conversationID"))
+	if err != nil {
+		log.Printf("ERROR: Failed to delete conversation for user %s: %v", userClaims.UserID, err)
+		writeConversationError(w, err, "Failed to delete conversation")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Conversation deleted successfully"})
+}
+
+// RateAnswerRequest rates a chat answer
+type RateAnswerRequest struct {
+	Rating string `json:"rating"` // "up" or "down"
+	Reason string `json:"reason,omitempty"`
+}
+
+// HandleRateAnswer records the user's thumbs up or down on an answer in one of their conversations
+func (h *APIHandler) HandleRateAnswer(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var req RateAnswerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	feedback, err := h.feedbackService.RateAnswer(r.Context(), userClaims.UserID, chi.URLParam(r, "conversationID"), chi.URLParam(r, "messageID"), req.Rating, req.Reason)
+	if err != nil {
+		log.Printf("ERROR: Failed to rate answer for user %s: %v", userClaims.UserID, err)
+		switch {
+		case err.Error() == "answer not found":
+			writeError(w, "Answer not found", http.StatusNotFound)
+		case strings.HasPrefix(err.Error(), "invalid"):
+			writeError(w, err.Error(), http.StatusBadRequest)
+		default:
+			writeConversationError(w, err, "Failed to save feedback")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"rating": feedback.Rating, "reason": feedback.Reason})
+}
+
+// HandleExportFeedback downloads rated exchanges as JSON Lines, one exchange per line, for
+// evaluating prompt and model changes (?rating=up|down&since=2006-01-02 or RFC 3339)
+func (h *APIHandler) HandleExportFeedback(w http.ResponseWriter, r *http.Request) {
+	filter := repository.FeedbackFilter{Rating: r.URL.Query().Get("rating")}
+	if since := r.URL.Query().Get("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", since)
+		}
+		if err != nil {
+			writeError(w, "since must be a date (YYYY-MM-DD) or RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		filter.Since = parsed
+	}
+
+	feedback, err := h.feedbackService.ListFeedback(r.Context(), filter)
+	if err != nil {
+		log.Printf("ERROR: Failed to export answer feedback: %v", err)
+		if strings.HasPrefix(err.Error(), "invalid") {
+			writeError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			writeError(w, "Failed to export feedback", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "feedback-"+time.Now().Format("2006-01-02")+".jsonl"))
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	for _, entry := range feedback {
+		if err := encoder.Encode(entry); err != nil {
+			log.Printf("ERROR: Failed to write feedback export: %v", err)
+			return
+		}
+	}
+}
+
+// exportFormat reads ?format=md|json, defaulting to Markdown
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		return "md", true
+	case "md", "json":
+		return format, true
+	}
+	writeError(w, "format must be md or json", http.StatusBadRequest)
+	return "", false
+}
+
+// renderExport renders a conversation export in the chosen format
+func renderExport(export service.ConversationExport, format string) ([]byte, error) {
+	if format == "json" {
+		return json.MarshalIndent(export, "", "  ")
+	}
+	return []byte(export.Markdown()), nil
+}
+
+// HandleExportConversation downloads one of the user's conversations as Markdown or JSON (?format=md|json)
+func (h *APIHandler) HandleExportConversation(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.chatService.ExportConversation(r.Context(), userClaims.UserID, chi.URLParam(r, "conversationID"))
+	if err != nil {
+		log.Printf("ERROR: Failed to export conversation for user %s: %v", userClaims.UserID, err)
+		writeConversationError(w, err, "Failed to export conversation")
+		return
+	}
+	body, err := renderExport(export, format)
+	if err != nil {
+		log.Printf("ERROR: Failed to render conversation %s: %v", export.ID, err)
+		writeError(w, "Failed to export conversation", http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "text/markdown; charset=utf-8"
+	if format == "json" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName(format)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// HandleExportAllConversations downloads every one of the user's conversations as a zip
+// archive with one Markdown or JSON file per conversation (?format=md|json)
+func (h *APIHandler) HandleExportAllConversations(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	exports, err := h.chatService.ExportAllConversations(r.Context(), userClaims.UserID)
+	if err != nil {
+		log.Printf("ERROR: Failed to export conversations for user %s: %v", userClaims.UserID, err)
+		writeError(w, "Failed to export conversations", http.StatusInternalServerError)
+		return
+	}
+
+	// Build the archive in memory so a failure can still be reported as an error
+	var archive bytes.Buffer
+	zipWriter := zip.NewWriter(&archive)
+	used := make(map[string]bool)
+	for _, export := range exports {
+		body, err := renderExport(export, format)
+		if err != nil {
+			log.Printf("ERROR: Failed to render conversation %s: %v", export.ID, err)
+			writeError(w, "Failed to export conversations", http.StatusInternalServerError)
+			return
+		}
+		name := export.FileName(format)
+		if used[name] {
+			name = strings.TrimSuffix(name, "."+format) + "-" + export.ID[:8] + "." + format
+		}
+		used[name] = true
+
+		file, err := zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: export.UpdatedAt})
+		if err == nil {
+			_, err = file.Write(body)
+		}
+		if err != nil {
+			log.Printf("ERROR: Failed to add conversation %s to the archive: %v", export.ID, err)
+			writeError(w, "Failed to export conversations", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		log.Printf("ERROR: Failed to finish conversation archive for user %s: %v", userClaims.UserID, err)
+		writeError(w, "Failed to export conversations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "conversations-"+time.Now().Format("2006-01-02")+".zip"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(archive.Bytes())
+}
+
+// writeConversationError maps chat service conversation errors to HTTP responses
+func writeConversationError(w http.ResponseWriter, err error, fallbackMessage string) {
+	switch {
+	case err.Error() == "conversation not found":
+		writeError(w, "Conversation not found", http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "invalid title"):
+		writeError(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeError(w, fallbackMessage, http.StatusInternalServerError)
+	}
+}
+
+// --- Usage Handlers ---
+
+// AdminUsageResponse is the LLM usage of every user and the month's budget
+type AdminUsageResponse struct {
+	service.UsageReport
+	Budget service.BudgetStatus `json:"budget"`
+}
+
+// usageDays reads ?days=, defaulting to 30
+func usageDays(w http.ResponseWriter, r *http.Request) (int, bool) {
+	days := 30
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 365 {
+			writeError(w, "days must be between 1 and 365", http.StatusBadRequest)
+			return 0, false
+		}
+		days = parsed
+	}
+	return days, true
+}
+
+// HandleGetMyUsage returns the tokens and cost of the logged-in user's LLM requests per day and feature
+func (h *APIHandler) HandleGetMyUsage(w http.ResponseWriter, r *http.Request) {
+	userClaims, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	days, ok := usageDays(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.usageService.UserUsage(r.Context(), userClaims.UserID, days)
+	if err != nil {
+		log.Printf("ERROR: Failed to get LLM usage of user %s: %v", userClaims.UserID, err)
+		writeError(w, "Failed to retrieve usage", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// HandleGetUsageTotals returns the tokens and cost of all LLM requests per day and feature, with the monthly budget
+func (h *APIHandler) HandleGetUsageTotals(w http.ResponseWriter, r *http.Request) {
+	days, ok := usageDays(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.usageService.TotalUsage(r.Context(), days)
+	if err != nil {
+		log.Printf("ERROR: Failed to get LLM usage totals: %v", err)
+		writeError(w, "Failed to retrieve usage", http.StatusInternalServerError)
+		return
+	}
+	budget, err := h.usageService.Budget(r.Context())
+	if err != nil {
+		log.Printf("ERROR: Failed to get LLM budget status: %v", err)
+		writeError(w, "Failed to retrieve usage", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, AdminUsageResponse{UsageReport: report, Budget: budget})
+}
+
+// --- Helper Functions ---
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		if err := json.NewEncoder(w).Encode(data); err != nil {
+			log.Printf("ERROR: Failed to write JSON response: %v", err)
+			http.Error(w, `{"error":"Failed to encode response"}`, http.StatusInternalServerError)
+		}
+	}
+}
+
+// planDayFromURL reads the {id} and {day} URL parameters that address a plan day,
+// writing a 400 response when the day is invalid
+func planDayFromURL(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	dayNumber, err := strconv.Atoi(chi.URLParam(r, "day"))
+	if err != nil || dayNumber < 1 {
+		writeError(w, "A positive day number is required", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return chi.URLParam(r, "id"), dayNumber, true
+}
+
+// dateFromQuery reads an optional ?date=YYYY-MM-DD parameter, defaulting to now.
+// It writes a 400 response when the date is malformed.
+func dateFromQuery(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	raw := r.URL.Query().Get("date")
+	if raw == "" {
+		return time.Now(), true
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		writeError(w, "date must be formatted as YYYY-MM-DD", http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// writeStudyError maps study service errors to responses
+func writeStudyError(w http.ResponseWriter, err error, fallbackMessage string) {
+	switch {
+	case errors.Is(err, repository.ErrDayOutOfRange):
+		writeError(w, "Day not found in plan", http.StatusNotFound)
+	case err.Error() == "quiz not found":
+		writeError(w, "This day has no quiz yet", http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "invalid answers"):
+		writeError(w, err.Error(), http.StatusBadRequest)
+	default:
+		writePlanServiceError(w, err, fallbackMessage)
+	}
+}
+
+// writePlanServiceError maps the plan service's not-found and ownership errors to HTTP statuses
+func writePlanServiceError(w http.ResponseWriter, err error, fallbackMessage string) {
+	switch {
+	case err.Error() == "plan not found":
+		writeError(w, "Plan not found", http.StatusNotFound)
+	case err.Error() == "revision not found":
+		writeError(w, "Revision not found", http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "default plans cannot"), strings.HasPrefix(err.Error(), "invalid plan"):
+		writeError(w, err.Error(), http.StatusBadRequest)
+	case strings.Contains(err.Error(), "unauthorized"):
+		writeError(w, "Unauthorized to access this plan", http.StatusForbidden)
+	default:
+		writeError(w, fallbackMessage, http.StatusInternalServerError)
 	}
 }
 
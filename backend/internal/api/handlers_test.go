@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingUserService fails every user lookup with err
+type failingUserService struct {
+	service.UserService
+	err error
+}
+
+func (s failingUserService) GetUser(ctx context.Context, userID string) (domain.User, error) {
+	return domain.User{}, s.err
+}
+
+func TestAuthMiddlewareUserLookupErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "Unknown user", err: errors.New("user not found"), expectedStatus: http.StatusUnauthorized},
+		{name: "Subject that isn't a user ID", err: errors.New("error finding user: invalid user ID format"), expectedStatus: http.StatusUnauthorized},
+		{name: "Database failure", err: errors.New("error finding user: connection refused"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "not-an-object-id",
+		"iss": "bibleapp",
+		"aud": "bibleapp_users",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &APIHandler{
+				authService: service.NewAuthService(nil, nil, "secret", nil),
+				userService: failingUserService{err: tc.err},
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+
+			h.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("request should not reach the handler")
+			})).ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name           string
+		claims         *UserClaims // Nil when the request isn't authenticated
+		perm           domain.Permission
+		expectedStatus int
+	}{
+		{
+			name:           "Granted permission passes",
+			claims:         &UserClaims{UserID: "user-1", Roles: []string{domain.RoleUser}},
+			perm:           domain.PermUseChat,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing permission is forbidden",
+			claims:         &UserClaims{UserID: "user-1", Roles: []string{domain.RoleUser}},
+			perm:           domain.PermManageUsers,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Admin passes every check",
+			claims:         &UserClaims{UserID: "admin-1", Roles: []string{domain.RoleAdmin}},
+			perm:           domain.PermManageJobs,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unauthenticated request is rejected",
+			perm:           domain.PermUseChat,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	h := &APIHandler{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+			if tc.claims != nil {
+				req = req.WithContext(context.WithValue(req.Context(), userContextKey, *tc.claims))
+			}
+			rec := httptest.NewRecorder()
+
+			h.RequirePermission(tc.perm)(next).ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestPlanDayAndDateParameters(t *testing.T) {
+	tests := []struct {
+		name           string
+		day            string
+		query          string
+		expectedDay    int
+		expectedDate   time.Time // Zero for today
+		expectedStatus int       // 0 when the parameters are accepted
+	}{
+		{name: "Day and date", day: "3", query: "?date=2026-10-18", expectedDay: 3, expectedDate: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{name: "Date defaults to today", day: "1", expectedDay: 1},
+		{name: "Day zero", day: "0", expectedStatus: http.StatusBadRequest},
+		{name: "Day that isn't a number", day: "first", expectedStatus: http.StatusBadRequest},
+		{name: "Malformed date", day: "1", query: "?date=18.10.2026", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", "plan-1")
+			routeCtx.URLParams.Add("day", tc.day)
+			req := httptest.NewRequest(http.MethodGet, "/api/plans/plan-1/days/"+tc.day+tc.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+			rec := httptest.NewRecorder()
+
+			planID, day, ok := planDayFromURL(rec, req)
+			if ok {
+				var date time.Time
+				date, ok = dateFromQuery(rec, req)
+				if ok {
+					assert.Equal(t, "plan-1", planID)
+					assert.Equal(t, tc.expectedDay, day)
+					if tc.expectedDate.IsZero() {
+						assert.WithinDuration(t, time.Now(), date, time.Minute)
+					} else {
+						assert.Equal(t, tc.expectedDate, date)
+					}
+				}
+			}
+			if tc.expectedStatus == 0 {
+				assert.True(t, ok)
+			} else {
+				assert.False(t, ok)
+				assert.Equal(t, tc.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bibleapp/backend/internal/domain"
 	"net/http"
 	"time"
 
@@ -9,6 +10,9 @@ import (
 	"github.com/go-chi/cors"
 )
 
+// chatStreamTimeout bounds a streamed chat answer, including any tool calls
+const chatStreamTimeout = 5 * time.Minute
+
 func NewRouter(h *APIHandler, allowedOrigin string) http.Handler {
 	r := chi.NewRouter()
 
@@ -17,7 +21,8 @@ func NewRouter(h *APIHandler, allowedOrigin string) http.Handler {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger) // Consider structured logging for production
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second)) // General request timeout
+	// The general request timeout is applied per group below, so streamed chat answers can have a longer one
+	requestTimeout := middleware.Timeout(60 * time.Second)
 
 	// --- CORS Configuration (applied before routing groups) ---
 	// Allow requests from the frontend origin, allow credentials (cookies)
@@ -28,7 +33,7 @@ func NewRouter(h *APIHandler, allowedOrigin string) http.Handler {
 		// Allow headers the frontend might send, including Authorization for JWT
 		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
 		// Headers the browser is allowed to access in responses
-		ExposedHeaders: []string{"Link"},
+		ExposedHeaders: []string{"Link", "Content-Disposition"}, // Content-Disposition names exported files
 		// Crucial for sending/receiving cookies (like the auth_token)
 		AllowCredentials: true,
 		MaxAge:           86400, // Cache CORS preflight response for 1 day
@@ -42,6 +47,7 @@ func NewRouter(h *APIHandler, allowedOrigin string) http.Handler {
 
 	// Authentication routes - don't need auth middleware applied *before* them
 	r.Route("/auth", func(r chi.Router) {
+		r.Use(requestTimeout)
 		r.Get("/google/login", h.HandleGoogleLogin)
 		r.Get("/google/callback", h.HandleGoogleCallback)
 		// Logout might need auth middleware *if* it needs to know *who* is logging out
@@ -54,29 +60,110 @@ func NewRouter(h *APIHandler, allowedOrigin string) http.Handler {
 		// Apply the AuthMiddleware to all routes within this group
 		r.Use(h.AuthMiddleware)
 
-		// Get current user info
-		r.Get("/me", h.HandleGetCurrentUser)
+		// Streamed answers stay open while the model writes, past the general timeout
+		r.With(h.RequirePermission(domain.PermUseChat), middleware.Timeout(chatStreamTimeout)).
+			Post("/chat/stream", h.HandleChatStream) // POST /api/chat/stream (server-sent events)
 
-		// Reading Plan routes
-		r.Route("/plans", func(r chi.Router) {
-			r.Post("/", h.HandleCreatePlan)            // POST /api/plans
-			r.Get("/", h.HandleListPlans)              // GET /api/plans
-			r.Get("/today", h.HandleGetPlanVerseToday) // GET /api/plans/today
-			r.Put("/", h.HandleUpdatePlan)             // PUT /api/plans
-			r.Delete("/", h.HandleDeletePlan)          // DELETE /api/plans?id=planID
-		})
+		r.Group(func(r chi.Router) {
+			r.Use(requestTimeout)
 
-		// Chat routes
-		r.Route("/chat", func(r chi.Router) {
-			r.Post("/", h.HandleChat)           // POST /api/chat
-			r.Post("/reset", h.HandleResetChat) // POST /api/chat/reset
-		})
+			// Get current user info
+			r.Get("/me", h.HandleGetCurrentUser)
+			r.Put("/me/track", h.HandleSelectTrack)    // PUT /api/me/track
+			r.Get("/me/audience", h.HandleGetAudience) // GET /api/me/audience
+			r.Put("/me/audience", h.HandleSetAudience) // PUT /api/me/audience
+			r.Put("/me/timezone", h.HandleSetTimezone) // PUT /api/me/timezone
+			r.Get("/me/usage", h.HandleGetMyUsage)     // GET /api/me/usage[?days=30]
+
+			// Default plan tracks
+			r.Get("/tracks", h.HandleListTracks) // GET /api/tracks
+
+			// Reading Plan routes - ownership of individual plans is checked in the plan service
+			r.Route("/plans", func(r chi.Router) {
+				r.Use(h.RequirePermission(domain.PermManageOwnPlans))
+				r.Post("/", h.HandleCreatePlan)                    // POST /api/plans
+				r.Get("/", h.HandleListPlans)                      // GET /api/plans
+				r.Get("/today", h.HandleGetPlanVerseToday)         // GET /api/plans/today[?date=YYYY-MM-DD&scope=all]
+				r.Get("/today/study", h.HandleGetTodayStudy)       // GET /api/plans/today/study[?date=YYYY-MM-DD]
+				r.Get("/suggestions", h.HandleGetTopicSuggestions) // GET /api/plans/suggestions[?limit=5]
+				r.Route("/{id}", func(r chi.Router) {
+					r.Get("/", h.HandleGetPlan)                         // GET /api/plans/{id}
+					r.Put("/", h.HandleUpdatePlan)                      // PUT /api/plans/{id}
+					r.Delete("/", h.HandleDeletePlan)                   // DELETE /api/plans/{id}
+					r.Get("/revisions", h.HandleListPlanRevisions)      // GET /api/plans/{id}/revisions
+					r.Get("/revisions/diff", h.HandleDiffPlanRevisions) // GET /api/plans/{id}/revisions/diff?from=1&to=2
+					r.Post("/rollback", h.HandleRollbackPlan)           // POST /api/plans/{id}/rollback?revision=1
+					r.Post("/activate", h.HandleActivatePlan)           // POST /api/plans/{id}/activate
+					r.Put("/priority", h.HandleSetPlanPriority)         // PUT /api/plans/{id}/priority
+					r.Route("/days/{day}", func(r chi.Router) {
+						r.Get("/", h.HandleGetPlanDay)       // GET /api/plans/{id}/days/{day}
+						r.Get("/study", h.HandleGetDayStudy) // GET /api/plans/{id}/days/{day}/study
+						r.Post("/quiz", h.HandleSubmitQuiz)  // POST /api/plans/{id}/days/{day}/quiz
+						r.With(h.RequirePermission(domain.PermEditAnyPlan)).
+							Post("/devotional/regenerate", h.HandleRegenerateDevotional) // POST /api/plans/{id}/days/{day}/devotional/regenerate
+					})
+				})
+			})
+
+			// Guardian routes - access to each ward is checked in the services
+			r.Route("/guardian", func(r chi.Router) {
+				r.Use(h.RequirePermission(domain.PermViewWards))
+				r.Get("/wards", h.HandleListWards)                                  // GET /api/guardian/wards
+				r.Get("/wards/{userID}/engagement", h.HandleGetWardEngagement)      // GET /api/guardian/wards/{userID}/engagement?days=30
+				r.Put("/wards/{userID}/audience", h.HandleSetWardAudience)          // PUT /api/guardian/wards/{userID}/audience
+				r.Get("/moderation", h.HandleListModerationFlags)                   // GET /api/guardian/moderation[?status=open]
+				r.Post("/moderation/{flagID}/review", h.HandleReviewModerationFlag) // POST /api/guardian/moderation/{flagID}/review
+			})
 
-		// NOTE: The previous `/api/admin` group is removed as we now
-		// integrate plan creation/listing into the main `/api/plans`
-		// protected by the AuthMiddleware. Access control (admin vs user)
-		// would typically be handled *within* the handlers based on user roles/permissions
-		// if needed later.
+			// Chat routes
+			r.Route("/chat", func(r chi.Router) {
+				r.Use(h.RequirePermission(domain.PermUseChat))
+				r.Post("/", h.HandleChat)                                                                   // POST /api/chat
+				r.Post("/reset", h.HandleResetChat)                                                         // POST /api/chat/reset
+				r.Get("/conversations", h.HandleListConversations)                                          // GET /api/chat/conversations[?plan_id=&day=]
+				r.Get("/conversations/export", h.HandleExportAllConversations)                              // GET /api/chat/conversations/export[?format=md|json] (zip)
+				r.Get("/conversations/{conversationID}", h.HandleGetConversation)                           // GET /api/chat/conversations/{conversationID}
+				r.Get("/conversations/{conversationID}/export", h.HandleExportConversation)                 // GET /api/chat/conversations/{conversationID}/export[?format=md|json]
+				r.Put("/conversations/{conversationID}", h.HandleRenameConversation)                        // PUT /api/chat/conversations/{conversationID}
+				r.Delete("/conversations/{conversationID}", h.HandleDeleteConversation)                     // DELETE /api/chat/conversations/{conversationID}
+				r.Put("/conversations/{conversationID}/branch", h.HandleSwitchBranch)                       // PUT /api/chat/conversations/{conversationID}/branch
+				r.Post("/conversations/{conversationID}/messages/{messageID}/feedback", h.HandleRateAnswer) // POST /api/chat/conversations/{conversationID}/messages/{messageID}/feedback
+			})
+
+			// Admin routes - each group requires its own permission
+			r.Route("/admin", func(r chi.Router) {
+				r.Group(func(r chi.Router) {
+					r.Use(h.RequirePermission(domain.PermManagePlans))
+					r.Post("/plans/revalidate", h.HandleRevalidatePlans)     // POST /api/admin/plans/revalidate
+					r.Post("/plans/{id}/revalidate", h.HandleRevalidatePlan) // POST /api/admin/plans/{id}/revalidate
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(h.RequirePermission(domain.PermManageUsers))
+					r.Get("/users", h.HandleListUsers)                            // GET /api/admin/users
+					r.Put("/users/{userID}/roles", h.HandleSetUserRoles)          // PUT /api/admin/users/{userID}/roles
+					r.Put("/users/{userID}/guardians", h.HandleSetUserGuardians)  // PUT /api/admin/users/{userID}/guardians
+					r.Put("/users/{userID}/audience", h.HandleSetUserAudience)    // PUT /api/admin/users/{userID}/audience
+					r.Put("/users/{userID}/chat-limit", h.HandleSetUserChatLimit) // PUT /api/admin/users/{userID}/chat-limit
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(h.RequirePermission(domain.PermManageJobs))
+					r.Get("/jobs", h.HandleListJobs)               // GET /api/admin/jobs
+					r.Post("/jobs/{name}/run", h.HandleTriggerJob) // POST /api/admin/jobs/{name}/run
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(h.RequirePermission(domain.PermExportFeedback))
+					r.Get("/feedback/export", h.HandleExportFeedback) // GET /api/admin/feedback/export[?rating=up|down&since=]
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(h.RequirePermission(domain.PermViewUsage))
+					r.Get("/usage", h.HandleGetUsageTotals) // GET /api/admin/usage[?days=30]
+				})
+			})
+		})
 	})
 
 	return r
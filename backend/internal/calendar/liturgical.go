@@ -0,0 +1,133 @@
+package calendar
+
+import "time"
+
+// Season is a season of the church year
+type Season string
+
+const (
+	SeasonAdvent       Season = "advent"
+	SeasonChristmas    Season = "christmas"
+	SeasonLent         Season = "lent"
+	SeasonHolyWeek     Season = "holy_week"
+	SeasonEaster       Season = "easter"
+	SeasonPentecost    Season = "pentecost"
+	SeasonOrdinaryTime Season = "ordinary_time"
+)
+
+// seasonNames are the display names used in prompts and API responses
+var seasonNames = map[Season]string{
+	SeasonAdvent:       "Advent",
+	SeasonChristmas:    "Christmas",
+	SeasonLent:         "Lent",
+	SeasonHolyWeek:     "Holy Week",
+	SeasonEaster:       "Easter",
+	SeasonPentecost:    "Pentecost",
+	SeasonOrdinaryTime: "Ordinary Time",
+}
+
+// defaultSeasonThemes are used for liturgical seasons the theme calendar doesn't override.
+// Ordinary Time has no default so the track or yearly theme applies.
+var defaultSeasonThemes = map[Season]string{
+	SeasonAdvent:    "Waiting and Preparing for the Coming of Christ",
+	SeasonChristmas: "The Word Became Flesh",
+	SeasonLent:      "Repentance, Prayer and Returning to God",
+	SeasonHolyWeek:  "The Passion and the Cross of Christ",
+	SeasonEaster:    "The Resurrection and New Life in Christ",
+	SeasonPentecost: "The Gift of the Holy Spirit and the Church",
+}
+
+// Name returns the display name of the season
+func (s Season) Name() string {
+	if name, ok := seasonNames[s]; ok {
+		return name
+	}
+	return string(s)
+}
+
+// DefaultTheme returns the built-in theme of the season, or "" for Ordinary Time
+func (s Season) DefaultTheme() string {
+	return defaultSeasonThemes[s]
+}
+
+// IsValidSeason reports whether s names a known season
+func IsValidSeason(s Season) bool {
+	_, ok := seasonNames[s]
+	return ok
+}
+
+// EasterSunday computes the date of Western Easter for a year using the
+// anonymous Gregorian algorithm (Meeus/Jones/Butcher)
+func EasterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// FirstSundayOfAdvent returns the fourth Sunday before Christmas Day
+func FirstSundayOfAdvent(year int) time.Time {
+	christmas := time.Date(year, time.December, 25, 0, 0, 0, 0, time.UTC)
+	daysBack := int(christmas.Weekday())
+	if daysBack == 0 {
+		daysBack = 7
+	}
+	// The last Sunday before Christmas is the fourth Sunday of Advent
+	return christmas.AddDate(0, 0, -daysBack-21)
+}
+
+// SeasonFor returns the liturgical season a date falls in.
+//
+//   - Advent: first Sunday of Advent to December 24
+//   - Christmas: December 25 to January 5 (the eve of Epiphany)
+//   - Lent: Ash Wednesday to the day before Palm Sunday
+//   - Holy Week: Palm Sunday to Holy Saturday
+//   - Easter: Easter Sunday to the day before Pentecost
+//   - Pentecost: the week from Pentecost Sunday to the eve of Trinity Sunday
+//   - Ordinary Time: everything else
+func SeasonFor(date time.Time) Season {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	year := day.Year()
+
+	if day.Month() == time.January && day.Day() <= 5 {
+		return SeasonChristmas
+	}
+	if day.Month() == time.December && day.Day() >= 25 {
+		return SeasonChristmas
+	}
+	if !day.Before(FirstSundayOfAdvent(year)) {
+		return SeasonAdvent
+	}
+
+	easter := EasterSunday(year)
+	ashWednesday := easter.AddDate(0, 0, -46)
+	palmSunday := easter.AddDate(0, 0, -7)
+	pentecost := easter.AddDate(0, 0, 49)
+	trinitySunday := pentecost.AddDate(0, 0, 7)
+
+	switch {
+	case day.Before(ashWednesday):
+		return SeasonOrdinaryTime
+	case day.Before(palmSunday):
+		return SeasonLent
+	case day.Before(easter):
+		return SeasonHolyWeek
+	case day.Before(pentecost):
+		return SeasonEaster
+	case day.Before(trinitySunday):
+		return SeasonPentecost
+	default:
+		return SeasonOrdinaryTime
+	}
+}
@@ -0,0 +1,69 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEasterSunday(t *testing.T) {
+	tests := []struct {
+		year     int
+		expected time.Time
+	}{
+		{2019, date(2019, time.April, 21)},
+		{2024, date(2024, time.March, 31)},
+		{2025, date(2025, time.April, 20)},
+		{2026, date(2026, time.April, 5)},
+		{2038, date(2038, time.April, 25)},
+		{2285, date(2285, time.March, 22)},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, EasterSunday(tt.year), "year %d", tt.year)
+	}
+}
+
+func TestFirstSundayOfAdvent(t *testing.T) {
+	assert.Equal(t, date(2022, time.November, 27), FirstSundayOfAdvent(2022)) // Christmas on a Sunday
+	assert.Equal(t, date(2023, time.December, 3), FirstSundayOfAdvent(2023))
+	assert.Equal(t, date(2024, time.December, 1), FirstSundayOfAdvent(2024))
+	assert.Equal(t, date(2025, time.November, 30), FirstSundayOfAdvent(2025))
+}
+
+func TestSeasonFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     time.Time
+		expected Season
+	}{
+		{"New year is Christmas", date(2025, time.January, 1), SeasonChristmas},
+		{"Epiphany is Ordinary Time", date(2025, time.January, 6), SeasonOrdinaryTime},
+		{"Day before Ash Wednesday", date(2025, time.March, 4), SeasonOrdinaryTime},
+		{"Ash Wednesday", date(2025, time.March, 5), SeasonLent},
+		{"Saturday before Palm Sunday", date(2025, time.April, 12), SeasonLent},
+		{"Palm Sunday", date(2025, time.April, 13), SeasonHolyWeek},
+		{"Holy Saturday", date(2025, time.April, 19), SeasonHolyWeek},
+		{"Easter Sunday", date(2025, time.April, 20), SeasonEaster},
+		{"Day before Pentecost", date(2025, time.June, 7), SeasonEaster},
+		{"Pentecost Sunday", date(2025, time.June, 8), SeasonPentecost},
+		{"Trinity Sunday", date(2025, time.June, 15), SeasonOrdinaryTime},
+		{"Summer", date(2025, time.August, 1), SeasonOrdinaryTime},
+		{"Day before Advent", date(2025, time.November, 29), SeasonOrdinaryTime},
+		{"First Sunday of Advent", date(2025, time.November, 30), SeasonAdvent},
+		{"Christmas Eve", date(2025, time.December, 24), SeasonAdvent},
+		{"Christmas Day", date(2025, time.December, 25), SeasonChristmas},
+		{"Time of day is ignored", time.Date(2025, time.April, 20, 23, 59, 0, 0, time.UTC), SeasonEaster},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SeasonFor(tt.date))
+		})
+	}
+}
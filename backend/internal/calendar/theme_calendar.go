@@ -0,0 +1,167 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ThemeEntry assigns a theme to a date range, a liturgical season or a set of months.
+// Exactly one of From/To, Season or Months should be set. Tracks limits the entry
+// to the listed default plan tracks; an empty list applies it to every track.
+type ThemeEntry struct {
+	Theme  string   `json:"theme"`
+	From   string   `json:"from,omitempty"` // Start of a date range as MM-DD, inclusive
+	To     string   `json:"to,omitempty"`   // End of a date range as MM-DD, inclusive; may wrap past new year
+	Season Season   `json:"season,omitempty"`
+	Months []int    `json:"months,omitempty"` // 1-12
+	Tracks []string `json:"tracks,omitempty"`
+}
+
+// Theme is the theme chosen for a date and where it came from
+type Theme struct {
+	Name   string `json:"name"`
+	Season Season `json:"season"`
+	Source string `json:"source"` // "date_range", "season", "liturgical", "month" or "fallback"
+}
+
+// ThemeCalendar picks the theme for a default plan from its start date.
+// Precedence: configured date ranges, configured seasons, the built-in
+// liturgical themes (when enabled), configured months, then the fallback theme.
+type ThemeCalendar struct {
+	entries    []ThemeEntry
+	liturgical bool
+}
+
+// NewThemeCalendar creates a theme calendar. When liturgical is true the
+// built-in season themes apply to seasons the entries don't cover.
+func NewThemeCalendar(entries []ThemeEntry, liturgical bool) *ThemeCalendar {
+	return &ThemeCalendar{entries: entries, liturgical: liturgical}
+}
+
+// ParseThemeEntries reads theme calendar entries from a JSON array such as
+// [{"theme":"Hope","months":[1,2]},{"theme":"Harvest","from":"09-15","to":"10-15"},{"theme":"The Cross","season":"lent"}]
+func ParseThemeEntries(raw string) ([]ThemeEntry, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var entries []ThemeEntry
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	for i, entry := range entries {
+		if strings.TrimSpace(entry.Theme) == "" {
+			return nil, fmt.Errorf("entry #%d has no theme", i+1)
+		}
+
+		kinds := 0
+		if entry.From != "" || entry.To != "" {
+			kinds++
+			if _, _, err := parseMonthDay(entry.From); err != nil {
+				return nil, fmt.Errorf("entry #%d has invalid from: %w", i+1, err)
+			}
+			if _, _, err := parseMonthDay(entry.To); err != nil {
+				return nil, fmt.Errorf("entry #%d has invalid to: %w", i+1, err)
+			}
+		}
+		if entry.Season != "" {
+			kinds++
+			if !IsValidSeason(entry.Season) {
+				return nil, fmt.Errorf("entry #%d has unknown season '%s'", i+1, entry.Season)
+			}
+		}
+		if len(entry.Months) > 0 {
+			kinds++
+			for _, month := range entry.Months {
+				if month < 1 || month > 12 {
+					return nil, fmt.Errorf("entry #%d has invalid month %d", i+1, month)
+				}
+			}
+		}
+		if kinds != 1 {
+			return nil, fmt.Errorf("entry #%d must set exactly one of from/to, season or months", i+1)
+		}
+	}
+	return entries, nil
+}
+
+// ThemeFor returns the theme for a date on a track, using fallback when nothing else applies
+func (c *ThemeCalendar) ThemeFor(date time.Time, trackID string, fallback string) Theme {
+	season := SeasonFor(date)
+
+	for _, entry := range c.entriesFor(trackID) {
+		if entry.From != "" && inDateRange(date, entry.From, entry.To) {
+			return Theme{Name: entry.Theme, Season: season, Source: "date_range"}
+		}
+	}
+	for _, entry := range c.entriesFor(trackID) {
+		if entry.Season != "" && entry.Season == season {
+			return Theme{Name: entry.Theme, Season: season, Source: "season"}
+		}
+	}
+	if c != nil && c.liturgical {
+		if theme, ok := defaultSeasonThemes[season]; ok {
+			return Theme{Name: theme, Season: season, Source: "liturgical"}
+		}
+	}
+	for _, entry := range c.entriesFor(trackID) {
+		for _, month := range entry.Months {
+			if time.Month(month) == date.Month() {
+				return Theme{Name: entry.Theme, Season: season, Source: "month"}
+			}
+		}
+	}
+	return Theme{Name: fallback, Season: season, Source: "fallback"}
+}
+
+func (c *ThemeCalendar) entriesFor(trackID string) []ThemeEntry {
+	if c == nil {
+		return nil
+	}
+	var entries []ThemeEntry
+	for _, entry := range c.entries {
+		if len(entry.Tracks) == 0 {
+			entries = append(entries, entry)
+			continue
+		}
+		for _, track := range entry.Tracks {
+			if track == trackID {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// inDateRange reports whether date falls between two MM-DD bounds, wrapping past new year when from > to
+func inDateRange(date time.Time, from string, to string) bool {
+	fromMonth, fromDay, err := parseMonthDay(from)
+	if err != nil {
+		return false
+	}
+	toMonth, toDay, err := parseMonthDay(to)
+	if err != nil {
+		return false
+	}
+
+	current := int(date.Month())*100 + date.Day()
+	start := fromMonth*100 + fromDay
+	end := toMonth*100 + toDay
+	if start <= end {
+		return current >= start && current <= end
+	}
+	return current >= start || current <= end
+}
+
+func parseMonthDay(value string) (int, int, error) {
+	// Parse against a leap year so 02-29 is accepted
+	parsed, err := time.Parse("2006-01-02", "2024-"+value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("expected MM-DD, got '%s'", value)
+	}
+	return int(parsed.Month()), parsed.Day(), nil
+}
@@ -0,0 +1,72 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseThemeEntries(t *testing.T) {
+	entries, err := ParseThemeEntries("")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entries, err = ParseThemeEntries(`[{"theme":"Hope","months":[1,2]},{"theme":"Harvest","from":"09-15","to":"10-15"},{"theme":"The Cross","season":"lent"}]`)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	invalid := []string{
+		`not json`,
+		`[{"months":[1]}]`,
+		`[{"theme":"Hope","months":[13]}]`,
+		`[{"theme":"Hope","season":"summer"}]`,
+		`[{"theme":"Hope","from":"02-30","to":"03-01"}]`,
+		`[{"theme":"Hope","from":"01-01"}]`,
+		`[{"theme":"Hope"}]`,
+		`[{"theme":"Hope","months":[1],"season":"lent"}]`,
+	}
+	for _, raw := range invalid {
+		_, err := ParseThemeEntries(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestThemeFor(t *testing.T) {
+	entries := []ThemeEntry{
+		{Theme: "Harvest", From: "09-15", To: "10-15"},
+		{Theme: "New Beginnings", From: "12-28", To: "01-03"},
+		{Theme: "The Cross", Season: SeasonLent},
+		{Theme: "Hope", Months: []int{1, 2, 3}},
+		{Theme: "Kids Summer", Months: []int{7}, Tracks: []string{"kids"}},
+	}
+
+	tests := []struct {
+		name       string
+		liturgical bool
+		date       time.Time
+		trackID    string
+		theme      string
+		source     string
+	}{
+		{"Date range", false, date(2025, time.October, 1), "default", "Harvest", "date_range"},
+		{"Date range wrapping new year", true, date(2026, time.January, 2), "default", "New Beginnings", "date_range"},
+		{"Configured season beats month", false, date(2025, time.March, 10), "default", "The Cross", "season"},
+		{"Month", false, date(2025, time.February, 1), "default", "Hope", "month"},
+		{"Liturgical season beats month", true, date(2025, time.January, 4), "default", "The Word Became Flesh", "liturgical"},
+		{"Month without liturgical themes", false, date(2025, time.January, 4), "default", "Hope", "month"},
+		{"Track specific month", false, date(2025, time.July, 1), "kids", "Kids Summer", "month"},
+		{"Other tracks fall back", false, date(2025, time.July, 1), "default", "Faith", "fallback"},
+		{"Ordinary Time falls back", true, date(2025, time.August, 1), "default", "Faith", "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme := NewThemeCalendar(entries, tt.liturgical).ThemeFor(tt.date, tt.trackID, "Faith")
+			assert.Equal(t, tt.theme, theme.Name)
+			assert.Equal(t, tt.source, theme.Source)
+			assert.Equal(t, SeasonFor(tt.date), theme.Season)
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package config
 
 import (
+	"bibleapp/backend/internal/calendar"
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/metering"
+	"encoding/json"
+	"fmt"
 	"log"
 	"strings"
 
@@ -13,16 +19,34 @@ type Config struct {
 	OpenRouterAPIKey      string
 	OpenRouterBaseURL     string
 	LLMModelName          string
-	MongoDBURI            string // Added for MongoDB connection
-	GoogleClientID        string // Added for Google OAuth
-	GoogleClientSecret    string // Added for Google OAuth
-	GoogleRedirectURL     string // Added for Google OAuth Callback
-	JWTSecret             string // Added for signing our application's JWTs
-	BibleDBPath           string // Path to the Bible SQLite database
-	ChatRateLimitEnabled  bool   // Whether chat rate limiting is enabled
-	ChatRateLimitPerDay   int    // Maximum number of chat requests per user per day
-	YearlyTheme           string // Theme of the year for Bible reading plans
-	DefaultTargetAudience string // Default target audience for Bible reading plans
+	MongoDBURI            string                        // Added for MongoDB connection
+	GoogleClientID        string                        // Added for Google OAuth
+	GoogleClientSecret    string                        // Added for Google OAuth
+	GoogleRedirectURL     string                        // Added for Google OAuth Callback
+	JWTSecret             string                        // Added for signing our application's JWTs
+	BibleDBPath           string                        // Path to the Bible SQLite database
+	ChatRateLimitEnabled  bool                          // Whether chat rate limiting is enabled
+	ChatRateLimitPerDay   int                           // Maximum number of chat requests per user per day
+	ChatRateLimitPerRole  map[string]int                // Daily chat limit per role, overriding ChatRateLimitPerDay; 0 means unlimited
+	YearlyTheme           string                        // Theme of the year for Bible reading plans
+	DefaultTargetAudience string                        // Default target audience for Bible reading plans
+	BootstrapAdminEmails  []string                      // Emails granted the admin role when they sign in
+	DefaultPlanTracks     []domain.PlanTrack            // Default plan tracks; the first is used when a user hasn't picked one
+	ThemeCalendar         []calendar.ThemeEntry         // Themes per date range, season or month for default plans
+	LiturgicalThemes      bool                          // Whether liturgical seasons get their built-in themes
+	DefaultPlanSchedule   string                        // Cron expression for default plan generation
+	SchedulerTimezone     string                        // Time zone job schedules are evaluated in
+	ChatHistoryMaxTokens  int                           // Most tokens of conversation history sent with a chat question
+	ChatModelContextSizes map[string]int                // Context window per model name prefix, overriding the built-in table
+	ChatToolsEnabled      bool                          // Whether the chat assistant may call tools to look up verses
+	ModerationEnabled     bool                          // Whether chat questions and answers are screened
+	ModerationModel       string                        // Model of the optional LLM classifier; empty uses the rule lists only
+	ModerationBlockTerms  []string                      // Extra terms blocked in questions and answers
+	LLMPrices             metering.PriceTable           // US dollars per million tokens per model name prefix
+	LLMMonthlyBudget      float64                       // US dollars LLM requests may cost per month; 0 means no budget
+	LLMBudgetFallback     string                        // Cheaper model of the budget route's steps that name none; without a budget route it goes to the default route's first provider
+	LLMProviders          map[string]llm.ProviderConfig // LLM providers by name; "openrouter" is configured from OPENROUTER_* unless overridden
+	LLMRoutes             map[string][]llm.RouteStep    // Providers each feature's requests try in turn; "default" covers the other features
 }
 
 // Load uses Viper to load configuration from .env file and environment variables.
@@ -48,6 +72,12 @@ func Load() *Config {
 	viper.SetDefault("BIBLE_DB_PATH", "./data/bible.db")                                  // Default Bible database path
 	viper.SetDefault("YEARLY_THEME", "Faith and Perseverance")                            // Default yearly theme
 	viper.SetDefault("DEFAULT_TARGET_AUDIENCE", "adult believer")                         // Default target audience
+	viper.SetDefault("LITURGICAL_THEMES_ENABLED", "false")                                // Follow the church year for default plan themes
+	viper.SetDefault("DEFAULT_PLAN_SCHEDULE", "0 2 * * *")                                // Check default plans daily at 2 AM
+	viper.SetDefault("SCHEDULER_TIMEZONE", "Local")                                       // Server local time
+	viper.SetDefault("CHAT_HISTORY_MAX_TOKENS", "3000")                                   // Bounds the cost of long conversations
+	viper.SetDefault("MODERATION_ENABLED", "true")                                        // The app serves minors
+	viper.SetDefault("CHAT_TOOLS_ENABLED", "true")                                        // Needs a model that supports tool calling
 
 	// Enable Viper to read Environment Variables
 	viper.AutomaticEnv()
@@ -69,7 +99,7 @@ func Load() *Config {
 		viper.SetDefault("CHAT_RATE_LIMIT_PER_DAY", "5")
 	}
 
-	return &Config{
+	cfg := &Config{
 		Port:                  viper.GetString("PORT"),
 		CorsAllowedOrigin:     viper.GetString("CORS_ALLOWED_ORIGIN"),
 		OpenRouterAPIKey:      viper.GetString("OPENROUTER_API_KEY"),
@@ -85,5 +115,179 @@ func Load() *Config {
 		ChatRateLimitPerDay:   viper.GetInt("CHAT_RATE_LIMIT_PER_DAY"),
 		YearlyTheme:           viper.GetString("YEARLY_THEME"),
 		DefaultTargetAudience: viper.GetString("DEFAULT_TARGET_AUDIENCE"),
+		BootstrapAdminEmails:  splitList(viper.GetString("BOOTSTRAP_ADMIN_EMAILS")),
+		LiturgicalThemes:      strings.ToLower(viper.GetString("LITURGICAL_THEMES_ENABLED")) == "true",
+		DefaultPlanSchedule:   viper.GetString("DEFAULT_PLAN_SCHEDULE"),
+		SchedulerTimezone:     viper.GetString("SCHEDULER_TIMEZONE"),
+		ChatHistoryMaxTokens:  viper.GetInt("CHAT_HISTORY_MAX_TOKENS"),
+		ChatToolsEnabled:      strings.ToLower(viper.GetString("CHAT_TOOLS_ENABLED")) == "true",
+		ModerationEnabled:     strings.ToLower(viper.GetString("MODERATION_ENABLED")) == "true",
+		ModerationModel:       viper.GetString("MODERATION_LLM_MODEL"),
+		ModerationBlockTerms:  splitList(viper.GetString("MODERATION_BLOCKED_TERMS")),
+		LLMMonthlyBudget:      viper.GetFloat64("LLM_MONTHLY_BUDGET"),
+		LLMBudgetFallback:     viper.GetString("LLM_BUDGET_FALLBACK_MODEL"),
 	}
+
+	tracks, err := parsePlanTracks(viper.GetString("DEFAULT_PLAN_TRACKS"), cfg.YearlyTheme, cfg.DefaultTargetAudience)
+	if err != nil {
+		log.Fatalf("FATAL: Invalid DEFAULT_PLAN_TRACKS: %v", err)
+	}
+	cfg.DefaultPlanTracks = tracks
+
+	themeCalendar, err := calendar.ParseThemeEntries(viper.GetString("THEME_CALENDAR"))
+	if err != nil {
+		log.Fatalf("FATAL: Invalid THEME_CALENDAR: %v", err)
+	}
+	cfg.ThemeCalendar = themeCalendar
+
+	if raw := strings.TrimSpace(viper.GetString("CHAT_RATE_LIMIT_ROLES")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.ChatRateLimitPerRole); err != nil {
+			log.Fatalf("FATAL: Invalid CHAT_RATE_LIMIT_ROLES: %v", err)
+		}
+		for role, limit := range cfg.ChatRateLimitPerRole {
+			if !domain.IsValidRole(role) || limit < 0 {
+				log.Fatalf("FATAL: Invalid CHAT_RATE_LIMIT_ROLES: role '%s' with limit %d", role, limit)
+			}
+		}
+	}
+
+	// e.g. {"openai/gpt-4o-mini": {"prompt": 0.15, "completion": 0.6}}
+	if raw := strings.TrimSpace(viper.GetString("LLM_PRICES")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.LLMPrices); err != nil {
+			log.Fatalf("FATAL: Invalid LLM_PRICES: %v", err)
+		}
+	}
+
+	if raw := strings.TrimSpace(viper.GetString("CHAT_MODEL_CONTEXT_SIZES")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.ChatModelContextSizes); err != nil {
+			log.Fatalf("FATAL: Invalid CHAT_MODEL_CONTEXT_SIZES: %v", err)
+		}
+	}
+
+	providers, routes, err := parseLLMRouting(viper.GetString("LLM_PROVIDERS"), viper.GetString("LLM_ROUTES"), cfg.OpenRouterAPIKey, cfg.OpenRouterBaseURL, cfg.LLMBudgetFallback)
+	if err != nil {
+		log.Fatalf("FATAL: Invalid LLM_PROVIDERS or LLM_ROUTES: %v", err)
+	}
+	cfg.LLMProviders, cfg.LLMRoutes = providers, routes
+
+	return cfg
+}
+
+// parseLLMRouting reads the LLM providers from a JSON object such as
+// {"local": {"type": "ollama", "base_url": "http://localhost:11434"}} and the routes from one such as
+// {"default": [{"provider": "openrouter"}, {"provider": "local", "model": "llama3.1"}], "chat": [{"provider": "local", "model": "llama3.1"}]}.
+// Routes are keyed by feature. Without any configuration every request goes to OpenRouter.
+// The "budget" route takes over once the monthly budget is spent; its steps default to the
+// budget fallback model, and without one configured that model goes to the default route's
+// first provider. Without either, requests are refused once the budget is spent.
+func parseLLMRouting(rawProviders string, rawRoutes string, openRouterAPIKey string, openRouterBaseURL string, budgetFallbackModel string) (map[string]llm.ProviderConfig, map[string][]llm.RouteStep, error) {
+	providers := map[string]llm.ProviderConfig{}
+	if strings.TrimSpace(rawProviders) != "" {
+		if err := json.Unmarshal([]byte(rawProviders), &providers); err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, ok := providers[llm.ProviderOpenRouter]; !ok {
+		providers[llm.ProviderOpenRouter] = llm.ProviderConfig{Type: llm.ProviderOpenRouter, APIKey: openRouterAPIKey, BaseURL: openRouterBaseURL}
+	}
+	for name, provider := range providers {
+		if !llm.IsProviderType(provider.Type) {
+			return nil, nil, fmt.Errorf("provider '%s' has unknown type '%s'", name, provider.Type)
+		}
+	}
+
+	routes := map[string][]llm.RouteStep{}
+	if strings.TrimSpace(rawRoutes) != "" {
+		if err := json.Unmarshal([]byte(rawRoutes), &routes); err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, ok := routes[llm.DefaultRoute]; !ok {
+		routes[llm.DefaultRoute] = []llm.RouteStep{{Provider: llm.ProviderOpenRouter}}
+	}
+	if steps, ok := routes[llm.BudgetRoute]; ok {
+		for i := range steps {
+			if steps[i].Model == "" {
+				steps[i].Model = budgetFallbackModel
+			}
+		}
+	} else if budgetFallbackModel != "" && len(routes[llm.DefaultRoute]) > 0 {
+		routes[llm.BudgetRoute] = []llm.RouteStep{{Provider: routes[llm.DefaultRoute][0].Provider, Model: budgetFallbackModel}}
+	}
+	for feature, steps := range routes {
+		if feature != llm.DefaultRoute && feature != llm.BudgetRoute && !metering.IsFeature(feature) {
+			return nil, nil, fmt.Errorf("route for unknown feature '%s'", feature)
+		}
+		if len(steps) == 0 {
+			return nil, nil, fmt.Errorf("route for '%s' has no providers", feature)
+		}
+		for _, step := range steps {
+			if _, ok := providers[step.Provider]; !ok {
+				return nil, nil, fmt.Errorf("route for '%s' uses unknown provider '%s'", feature, step.Provider)
+			}
+		}
+	}
+	return providers, routes, nil
+}
+
+// parsePlanTracks reads the default plan tracks from a JSON array such as
+// [{"id":"kids","name":"Kids","target_audience":"8-11 year old","theme":"God's Promises","cadence_days":7}].
+// Missing audiences and themes fall back to the global defaults. Without any
+// configuration a single "default" track reproduces the original behaviour.
+func parsePlanTracks(raw string, yearlyTheme string, targetAudience string) ([]domain.PlanTrack, error) {
+	if strings.TrimSpace(raw) == "" {
+		return []domain.PlanTrack{{
+			ID:             domain.DefaultPlanUserID,
+			Name:           "Default",
+			TargetAudience: targetAudience,
+			Theme:          yearlyTheme,
+			CadenceDays:    7,
+		}}, nil
+	}
+
+	var tracks []domain.PlanTrack
+	if err := json.Unmarshal([]byte(raw), &tracks); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if len(tracks) == 0 {
+		return nil, fmt.Errorf("at least one track is required")
+	}
+
+	seen := make(map[string]bool)
+	for i := range tracks {
+		track := &tracks[i]
+		track.ID = strings.TrimSpace(track.ID)
+		if track.ID == "" {
+			return nil, fmt.Errorf("track #%d has no id", i+1)
+		}
+		if seen[track.ID] {
+			return nil, fmt.Errorf("duplicate track id '%s'", track.ID)
+		}
+		seen[track.ID] = true
+
+		if track.Name == "" {
+			track.Name = track.ID
+		}
+		if track.TargetAudience == "" {
+			track.TargetAudience = targetAudience
+		}
+		if track.Theme == "" {
+			track.Theme = yearlyTheme
+		}
+		if track.CadenceDays <= 0 {
+			track.CadenceDays = 7
+		}
+	}
+	return tracks, nil
+}
+
+// splitList parses a comma-separated setting into trimmed, non-empty values
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
 }
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"bibleapp/backend/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlanTracks(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    []domain.PlanTrack
+		expectedErr string
+	}{
+		{
+			name:     "No configuration keeps a single default track",
+			raw:      "",
+			expected: []domain.PlanTrack{{ID: "default", Name: "Default", TargetAudience: "adult", Theme: "Hope", CadenceDays: 7}},
+		},
+		{
+			name: "Missing fields fall back to the defaults",
+			raw:  `[{"id":" kids ","target_audience":"8-11 year old","cadence_days":14},{"id":"adults","name":"Adults","theme":"Grace"}]`,
+			expected: []domain.PlanTrack{
+				{ID: "kids", Name: "kids", TargetAudience: "8-11 year old", Theme: "Hope", CadenceDays: 14},
+				{ID: "adults", Name: "Adults", TargetAudience: "adult", Theme: "Grace", CadenceDays: 7},
+			},
+		},
+		{name: "Invalid JSON", raw: `{"id":"kids"}`, expectedErr: "failed to parse JSON"},
+		{name: "Empty list", raw: `[]`, expectedErr: "at least one track is required"},
+		{name: "Track without an ID", raw: `[{"id":"kids"},{"name":"Teens"}]`, expectedErr: "track #2 has no id"},
+		{name: "Duplicate IDs", raw: `[{"id":"kids"},{"id":"kids"}]`, expectedErr: "duplicate track id 'kids'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tracks, err := parsePlanTracks(tc.raw, "Hope", "adult")
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, tracks)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Age bands an audience profile can target
+const (
+	AgeBandChild      = "child"       // 8-12
+	AgeBandTeen       = "teen"        // 13-17
+	AgeBandYoungAdult = "young_adult" // 18-25
+	AgeBandAdult      = "adult"
+	AgeBandSenior     = "senior"
+)
+
+// Reading levels an audience profile can target
+const (
+	ReadingLevelSimple   = "simple"
+	ReadingLevelStandard = "standard"
+	ReadingLevelAdvanced = "advanced"
+)
+
+// Tones an audience profile can ask for
+const (
+	ToneEncouraging    = "encouraging"
+	ToneConversational = "conversational"
+	TonePastoral       = "pastoral"
+	ToneScholarly      = "scholarly"
+)
+
+// maxAudienceTextLength caps the free-text fields of an audience profile
+const maxAudienceTextLength = 40
+
+// ageBandDescriptions are how each age band reads in a prompt
+var ageBandDescriptions = map[string]string{
+	AgeBandChild:      "child (ages 8-12)",
+	AgeBandTeen:       "teenager (ages 13-17)",
+	AgeBandYoungAdult: "young adult (ages 18-25)",
+	AgeBandAdult:      "adult",
+	AgeBandSenior:     "older adult",
+}
+
+// readingLevelInstructions tell the LLM how to write for each reading level
+var readingLevelInstructions = map[string]string{
+	ReadingLevelSimple:   "Use simple words and short sentences, and explain any church or Bible terms.",
+	ReadingLevelStandard: "Write clearly in everyday language.",
+	ReadingLevelAdvanced: "You may use theological vocabulary, historical context and the original languages where they help.",
+}
+
+// toneInstructions tell the LLM how each tone sounds
+var toneInstructions = map[string]string{
+	ToneEncouraging:    "Be warm and encouraging.",
+	ToneConversational: "Be relaxed and conversational.",
+	TonePastoral:       "Be gentle and pastoral, like a caring mentor.",
+	ToneScholarly:      "Be precise and even-handed, like a good study Bible.",
+}
+
+// AudienceProfile describes who chat answers and generated plans are written for.
+// Empty fields fall back to DefaultAudienceProfile.
+type AudienceProfile struct {
+	AgeBand      string `json:"age_band,omitempty" bson:"age_band,omitempty"`
+	ReadingLevel string `json:"reading_level,omitempty" bson:"reading_level,omitempty"`
+	Tradition    string `json:"tradition,omitempty" bson:"tradition,omitempty"` // e.g. "Catholic" or "Baptist"; empty means none in particular
+	Tone         string `json:"tone,omitempty" bson:"tone,omitempty"`
+	Language     string `json:"language,omitempty" bson:"language,omitempty"` // Language answers and plan titles are written in
+}
+
+// DefaultAudienceProfile is used for users who haven't chosen a profile
+var DefaultAudienceProfile = AudienceProfile{
+	AgeBand:      AgeBandAdult,
+	ReadingLevel: ReadingLevelStandard,
+	Tone:         ToneEncouraging,
+	Language:     "English",
+}
+
+// Validate checks the profile's fields, allowing empty ones
+func (p AudienceProfile) Validate() error {
+	if _, ok := ageBandDescriptions[p.AgeBand]; p.AgeBand != "" && !ok {
+		return fmt.Errorf("invalid audience: unknown age band '%s'", p.AgeBand)
+	}
+	if _, ok := readingLevelInstructions[p.ReadingLevel]; p.ReadingLevel != "" && !ok {
+		return fmt.Errorf("invalid audience: unknown reading level '%s'", p.ReadingLevel)
+	}
+	if _, ok := toneInstructions[p.Tone]; p.Tone != "" && !ok {
+		return fmt.Errorf("invalid audience: unknown tone '%s'", p.Tone)
+	}
+	if len(p.Tradition) > maxAudienceTextLength || len(p.Language) > maxAudienceTextLength {
+		return fmt.Errorf("invalid audience: tradition and language must be at most %d characters", maxAudienceTextLength)
+	}
+	return nil
+}
+
+// Merge returns the profile with its empty fields taken from fallback
+func (p AudienceProfile) Merge(fallback AudienceProfile) AudienceProfile {
+	merged := p
+	if merged.AgeBand == "" {
+		merged.AgeBand = fallback.AgeBand
+	}
+	if merged.ReadingLevel == "" {
+		merged.ReadingLevel = fallback.ReadingLevel
+	}
+	if merged.Tradition == "" {
+		merged.Tradition = fallback.Tradition
+	}
+	if merged.Tone == "" {
+		merged.Tone = fallback.Tone
+	}
+	if merged.Language == "" {
+		merged.Language = fallback.Language
+	}
+	return merged
+}
+
+// Describe names the audience for plan prompts, e.g. "teenager (ages 13-17) in the Catholic tradition"
+func (p AudienceProfile) Describe() string {
+	p = p.Merge(DefaultAudienceProfile)
+	description := ageBandDescriptions[p.AgeBand]
+	if p.ReadingLevel != ReadingLevelStandard {
+		description += fmt.Sprintf(" at a %s reading level", p.ReadingLevel)
+	}
+	if p.Tradition != "" {
+		description += fmt.Sprintf(" in the %s tradition", p.Tradition)
+	}
+	if !strings.EqualFold(p.Language, "English") {
+		description += fmt.Sprintf(" who reads %s", p.Language)
+	}
+	return description
+}
+
+// PromptInstructions renders the profile as instructions for a chat system prompt
+func (p AudienceProfile) PromptInstructions() string {
+	p = p.Merge(DefaultAudienceProfile)
+	instructions := []string{
+		fmt.Sprintf("You are talking with %s.", withArticle(ageBandDescriptions[p.AgeBand])),
+		readingLevelInstructions[p.ReadingLevel],
+		toneInstructions[p.Tone],
+	}
+	if p.Tradition != "" {
+		instructions = append(instructions, fmt.Sprintf("They belong to the %s tradition; respect its teaching and practices without disparaging others.", p.Tradition))
+	}
+	instructions = append(instructions, fmt.Sprintf("Always answer in %s.", p.Language))
+	return strings.Join(instructions, " ")
+}
+
+// withArticle puts "a" or "an" in front of a description
+func withArticle(description string) string {
+	if description != "" && strings.ContainsRune("aeiouAEIOU", rune(description[0])) {
+		return "an " + description
+	}
+	return "a " + description
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudienceProfileValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     AudienceProfile
+		expectedErr string
+	}{
+		{
+			name:    "Empty profile",
+			profile: AudienceProfile{},
+		},
+		{
+			name:    "Full profile",
+			profile: AudienceProfile{AgeBand: AgeBandTeen, ReadingLevel: ReadingLevelSimple, Tradition: "Catholic", Tone: TonePastoral, Language: "Spanish"},
+		},
+		{
+			name:        "Unknown age band",
+			profile:     AudienceProfile{AgeBand: "toddler"},
+			expectedErr: "invalid audience: unknown age band 'toddler'",
+		},
+		{
+			name:        "Unknown reading level",
+			profile:     AudienceProfile{ReadingLevel: "expert"},
+			expectedErr: "invalid audience: unknown reading level 'expert'",
+		},
+		{
+			name:        "Unknown tone",
+			profile:     AudienceProfile{Tone: "stern"},
+			expectedErr: "invalid audience: unknown tone 'stern'",
+		},
+		{
+			name:        "Tradition too long",
+			profile:     AudienceProfile{Tradition: strings.Repeat("a", maxAudienceTextLength+1)},
+			expectedErr: "invalid audience: tradition and language must be at most 40 characters",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.profile.Validate()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestAudienceProfileMerge(t *testing.T) {
+	guardianSet := AudienceProfile{AgeBand: AgeBandChild, ReadingLevel: ReadingLevelSimple}
+	tests := []struct {
+		name     string
+		profile  AudienceProfile
+		fallback AudienceProfile
+		expected AudienceProfile
+	}{
+		{
+			name:     "Empty profile takes the defaults",
+			profile:  AudienceProfile{},
+			fallback: DefaultAudienceProfile,
+			expected: DefaultAudienceProfile,
+		},
+		{
+			name:     "Set fields win over the fallback",
+			profile:  AudienceProfile{Tone: ToneScholarly, Language: "German"},
+			fallback: DefaultAudienceProfile,
+			expected: AudienceProfile{AgeBand: AgeBandAdult, ReadingLevel: ReadingLevelStandard, Tone: ToneScholarly, Language: "German"},
+		},
+		{
+			name:     "Locked profile wins over the user's own choices",
+			profile:  guardianSet,
+			fallback: AudienceProfile{AgeBand: AgeBandAdult, ReadingLevel: ReadingLevelAdvanced, Tradition: "Baptist"},
+			expected: AudienceProfile{AgeBand: AgeBandChild, ReadingLevel: ReadingLevelSimple, Tradition: "Baptist"},
+		},
+		{
+			name:     "Chained merges fill the rest from the defaults",
+			profile:  guardianSet.Merge(AudienceProfile{AgeBand: AgeBandAdult, Tone: TonePastoral}),
+			fallback: DefaultAudienceProfile,
+			expected: AudienceProfile{AgeBand: AgeBandChild, ReadingLevel: ReadingLevelSimple, Tone: TonePastoral, Language: "English"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.profile.Merge(tc.fallback))
+		})
+	}
+}
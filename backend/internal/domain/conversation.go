@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatMessage is one turn of a conversation with the Bible study assistant. Messages form a
+// tree: regenerating an answer or editing a question adds a sibling, starting a new branch.
+type ChatMessage struct {
+	ID        string        `json:"id" bson:"id,omitempty"`
+	ParentID  string        `json:"parent_id,omitempty" bson:"parent_id,omitempty"` // Empty for a first question
+	Role      string        `json:"role" bson:"role"`                               // "user" or "assistant"
+	Content   string        `json:"content" bson:"content"`
+	Citations []Citation    `json:"citations,omitempty" bson:"citations,omitempty"` // Passages an answer cites
+	Model     string        `json:"model,omitempty" bson:"model,omitempty"`         // Model that generated an answer
+	Prompt    *PromptRecord `json:"-" bson:"prompt,omitempty"`                      // How an answer's prompt was built
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// PromptRecord keeps what an answer's prompt was built from, so the exact prompt can be
+// rebuilt from the conversation when the answer is rated
+type PromptRecord struct {
+	System      []string `bson:"system"`       // System messages, in order
+	HistoryFrom string   `bson:"history_from"` // First message of the branch sent after them
+}
+
+// Citation is a Bible passage an answer cites, with its text so it can be shown as a source
+type Citation struct {
+	Reference string `json:"reference" bson:"reference"`
+	Text      string `json:"text,omitempty" bson:"text,omitempty"`
+}
+
+// Conversation is a user's chat session, optionally about a plan day or passage
+type Conversation struct {
+	ID           uuid.UUID     `json:"id" bson:"_id"`
+	UserID       string        `json:"user_id" bson:"user_id"`
+	Title        string        `json:"title" bson:"title"`
+	PlanID       string        `json:"plan_id,omitempty" bson:"plan_id,omitempty"`     // Plan of the day the conversation is about
+	DayNumber    int           `json:"day,omitempty" bson:"day,omitempty"`             // Day within that plan
+	Reference    string        `json:"reference,omitempty" bson:"reference,omitempty"` // Passage the conversation is about
+	Messages     []ChatMessage `json:"messages,omitempty" bson:"messages"`             // Every branch, in the order written; left out of conversation lists
+	LeafID       string        `json:"leaf_id,omitempty" bson:"leaf_id,omitempty"`     // Last message of the branch being shown
+	MessageCount int           `json:"message_count" bson:"message_count"`
+	// Summary condenses the branch up to and including SummaryThrough, which no longer fits in the prompt.
+	// It only applies to branches that contain that message.
+	Summary        string `json:"summary,omitempty" bson:"summary,omitempty"`
+	SummaryThrough string `json:"-" bson:"summary_through,omitempty"`
+	// SummarizedCount is how summaries were tracked before conversations could branch
+	SummarizedCount int       `json:"-" bson:"summarized_count,omitempty"`
+	CreatedAt       time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// BranchMessage is a message of the branch being shown, with the messages written in its place
+type BranchMessage struct {
+	ChatMessage
+	Siblings []string `json:"siblings,omitempty"` // IDs of the alternatives, itself included, oldest first; left out without alternatives
+}
+
+// EnsureTree gives the messages of conversations stored before they could branch an ID and a
+// parent. Those conversations were one branch, so each message follows the one before it. The
+// IDs are derived from the positions, which never change, so the tree doesn't need to be stored.
+func (c *Conversation) EnsureTree() {
+	for i := range c.Messages {
+		if c.Messages[i].ID != "" {
+			continue
+		}
+		c.Messages[i].ID = fmt.Sprintf("m%d", i)
+		if i > 0 && c.Messages[i].ParentID == "" {
+			c.Messages[i].ParentID = c.Messages[i-1].ID
+		}
+	}
+	if c.LeafID == "" && len(c.Messages) > 0 {
+		c.LeafID = c.Messages[len(c.Messages)-1].ID
+	}
+	if c.SummaryThrough == "" && c.SummarizedCount > 0 && c.SummarizedCount <= len(c.Messages) {
+		c.SummaryThrough = c.Messages[c.SummarizedCount-1].ID
+	}
+}
+
+// Message returns a message by ID, or nil if the conversation has none with that ID
+func (c *Conversation) Message(id string) *ChatMessage {
+	for i := range c.Messages {
+		if c.Messages[i].ID == id {
+			return &c.Messages[i]
+		}
+	}
+	return nil
+}
+
+// PathTo returns the messages from the first question down to a message, in order.
+// An empty ID is the root, whose path is empty.
+func (c *Conversation) PathTo(id string) []ChatMessage {
+	var path []ChatMessage
+	for id != "" && len(path) <= len(c.Messages) {
+		message := c.Message(id)
+		if message == nil {
+			break
+		}
+		path = append(path, *message)
+		id = message.ParentID
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Branch returns the messages of the branch being shown
+func (c *Conversation) Branch() []ChatMessage {
+	return c.PathTo(c.LeafID)
+}
+
+// Children returns the replies to a message, oldest first. An empty ID returns the first questions.
+func (c *Conversation) Children(parentID string) []ChatMessage {
+	var children []ChatMessage
+	for _, message := range c.Messages {
+		if message.ParentID == parentID {
+			children = append(children, message)
+		}
+	}
+	return children
+}
+
+// LatestLeaf follows the newest reply down from a message and returns the last message reached
+func (c *Conversation) LatestLeaf(id string) string {
+	for range c.Messages {
+		children := c.Children(id)
+		if len(children) == 0 {
+			break
+		}
+		id = children[len(children)-1].ID
+	}
+	return id
+}
+
+// BranchMessages returns the branch being shown with the alternatives to each message
+func (c *Conversation) BranchMessages() []BranchMessage {
+	branch := c.Branch()
+	messages := make([]BranchMessage, len(branch))
+	for i, message := range branch {
+		messages[i] = BranchMessage{ChatMessage: message}
+		if siblings := c.Children(message.ParentID); len(siblings) > 1 {
+			for _, sibling := range siblings {
+				messages[i].Siblings = append(messages[i].Siblings, sibling.ID)
+			}
+		}
+	}
+	return messages
+}
+
+// SummaryFor returns the summary and the messages after it for a branch. A summary of
+// another branch doesn't apply, leaving the whole branch unsummarized.
+func (c *Conversation) SummaryFor(branch []ChatMessage) (string, []ChatMessage) {
+	if c.Summary == "" || c.SummaryThrough == "" {
+		return "", branch
+	}
+	for i, message := range branch {
+		if message.ID == c.SummaryThrough {
+			return c.Summary, branch[i+1:]
+		}
+	}
+	return "", branch
+}
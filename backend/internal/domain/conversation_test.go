@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// branchedConversation asks one question, answers it twice, and edits it into a second question:
+//
+//	q1 ─ a1
+//	   └ a2 (shown)
+//	q2 ─ a3
+func branchedConversation() *Conversation {
+	return &Conversation{
+		Messages: []ChatMessage{
+			{ID: "q1", Role: "user", Content: "Who wrote Psalm 23?"},
+			{ID: "a1", ParentID: "q1", Role: "assistant", Content: "David"},
+			{ID: "a2", ParentID: "q1", Role: "assistant", Content: "King David"},
+			{ID: "q2", Role: "user", Content: "Who wrote Psalm 90?"},
+			{ID: "a3", ParentID: "q2", Role: "assistant", Content: "Moses"},
+		},
+		LeafID: "a2",
+	}
+}
+
+func messageIDs(messages []ChatMessage) []string {
+	ids := []string{}
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func TestConversationEnsureTree(t *testing.T) {
+	tests := []struct {
+		name            string
+		conversation    Conversation
+		expectedIDs     []string
+		expectedParents []string
+		expectedLeaf    string
+		expectedThrough string
+	}{
+		{
+			name: "Legacy flat list becomes one branch",
+			conversation: Conversation{Messages: []ChatMessage{
+				{Role: "user", Content: "Who wrote it?"},
+				{Role: "assistant", Content: "David"},
+				{Role: "user", Content: "When?"},
+			}, SummarizedCount: 2},
+			expectedIDs:     []string{"m0", "m1", "m2"},
+			expectedParents: []string{"", "m0", "m1"},
+			expectedLeaf:    "m2",
+			expectedThrough: "m1",
+		},
+		{
+			name:            "Tree is left alone",
+			conversation:    *branchedConversation(),
+			expectedIDs:     []string{"q1", "a1", "a2", "q2", "a3"},
+			expectedParents: []string{"", "q1", "q1", "", "q2"},
+			expectedLeaf:    "a2",
+		},
+		{
+			name:            "Summary count past the messages is dropped",
+			conversation:    Conversation{Messages: []ChatMessage{{Role: "user", Content: "Who wrote it?"}}, SummarizedCount: 3},
+			expectedIDs:     []string{"m0"},
+			expectedParents: []string{""},
+			expectedLeaf:    "m0",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conversation := tc.conversation
+			conversation.EnsureTree()
+
+			assert.Equal(t, tc.expectedIDs, messageIDs(conversation.Messages))
+			parents := []string{}
+			for _, message := range conversation.Messages {
+				parents = append(parents, message.ParentID)
+			}
+			assert.Equal(t, tc.expectedParents, parents)
+			assert.Equal(t, tc.expectedLeaf, conversation.LeafID)
+			assert.Equal(t, tc.expectedThrough, conversation.SummaryThrough)
+		})
+	}
+}
+
+func TestConversationPathTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		expected []string
+	}{
+		{name: "Answer", id: "a2", expected: []string{"q1", "a2"}},
+		{name: "Answer on the edited branch", id: "a3", expected: []string{"q2", "a3"}},
+		{name: "First question", id: "q1", expected: []string{"q1"}},
+		{name: "Root", id: "", expected: []string{}},
+		{name: "Unknown ID", id: "missing", expected: []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, messageIDs(branchedConversation().PathTo(tc.id)))
+		})
+	}
+}
+
+func TestConversationBranches(t *testing.T) {
+	tests := []struct {
+		name             string
+		add              *ChatMessage // Written before looking at the branch, becoming the leaf
+		expectedBranch   []string
+		expectedSiblings [][]string // Per message of the branch
+	}{
+		{
+			name:             "Regenerated answer is shown with its alternative",
+			expectedBranch:   []string{"q1", "a2"},
+			expectedSiblings: [][]string{{"q1", "q2"}, {"a1", "a2"}},
+		},
+		{
+			name:             "Regenerating again replaces the leaf",
+			add:              &ChatMessage{ID: "a4", ParentID: "q1", Role: "assistant", Content: "David, the shepherd king"},
+			expectedBranch:   []string{"q1", "a4"},
+			expectedSiblings: [][]string{{"q1", "q2"}, {"a1", "a2", "a4"}},
+		},
+		{
+			name:             "Editing a question starts a sibling branch",
+			add:              &ChatMessage{ID: "q3", Role: "user", Content: "Who wrote Psalm 1?"},
+			expectedBranch:   []string{"q3"},
+			expectedSiblings: [][]string{{"q1", "q2", "q3"}},
+		},
+		{
+			name:             "Following up extends the branch without alternatives",
+			add:              &ChatMessage{ID: "q4", ParentID: "a2", Role: "user", Content: "When?"},
+			expectedBranch:   []string{"q1", "a2", "q4"},
+			expectedSiblings: [][]string{{"q1", "q2"}, {"a1", "a2"}, nil},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conversation := branchedConversation()
+			if tc.add != nil {
+				conversation.Messages = append(conversation.Messages, *tc.add)
+				conversation.LeafID = tc.add.ID
+			}
+
+			assert.Equal(t, tc.expectedBranch, messageIDs(conversation.Branch()))
+			var siblings [][]string
+			for _, message := range conversation.BranchMessages() {
+				siblings = append(siblings, message.Siblings)
+			}
+			assert.Equal(t, tc.expectedSiblings, siblings)
+		})
+	}
+}
+
+func TestConversationLatestLeaf(t *testing.T) {
+	conversation := branchedConversation()
+
+	assert.Equal(t, "a2", conversation.LatestLeaf("q1"))
+	assert.Equal(t, "a3", conversation.LatestLeaf("q2"))
+	assert.Equal(t, "a3", conversation.LatestLeaf(""))
+	assert.Equal(t, "a1", conversation.LatestLeaf("a1"))
+}
+
+func TestConversationSummaryFor(t *testing.T) {
+	tests := []struct {
+		name              string
+		summary           string
+		through           string
+		leaf              string
+		expectedSummary   string
+		expectedRemaining []string
+	}{
+		{
+			name:              "Summary of the branch applies",
+			summary:           "David wrote it.",
+			through:           "q1",
+			leaf:              "a2",
+			expectedSummary:   "David wrote it.",
+			expectedRemaining: []string{"a2"},
+		},
+		{
+			name:              "Summary of a sibling answer doesn't apply",
+			summary:           "David wrote it.",
+			through:           "a1",
+			leaf:              "a2",
+			expectedRemaining: []string{"q1", "a2"},
+		},
+		{
+			name:              "Summary of another question's branch doesn't apply",
+			summary:           "Moses wrote it.",
+			through:           "a3",
+			leaf:              "a2",
+			expectedRemaining: []string{"q1", "a2"},
+		},
+		{
+			name:              "No summary",
+			leaf:              "a3",
+			expectedRemaining: []string{"q2", "a3"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conversation := branchedConversation()
+			conversation.Summary, conversation.SummaryThrough, conversation.LeafID = tc.summary, tc.through, tc.leaf
+
+			summary, remaining := conversation.SummaryFor(conversation.Branch())
+			assert.Equal(t, tc.expectedSummary, summary)
+			assert.Equal(t, tc.expectedRemaining, messageIDs(remaining))
+		})
+	}
+}
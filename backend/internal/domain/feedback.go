@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ratings a user can give a chat answer
+const (
+	RatingUp   = "up"
+	RatingDown = "down"
+)
+
+// IsValidRating reports whether a rating is known
+func IsValidRating(rating string) bool {
+	return rating == RatingUp || rating == RatingDown
+}
+
+// PromptMessage is one message of the prompt an answer was generated from
+type PromptMessage struct {
+	Role    string `json:"role" bson:"role"`
+	Content string `json:"content" bson:"content"`
+}
+
+// AnswerFeedback is a user's rating of a chat answer, stored with everything needed to replay
+// the exchange against another prompt or model. It is a snapshot, so it outlives edits to the
+// conversation.
+type AnswerFeedback struct {
+	ID             uuid.UUID       `json:"id" bson:"_id"`
+	UserID         string          `json:"user_id" bson:"user_id"`
+	ConversationID string          `json:"conversation_id" bson:"conversation_id"`
+	MessageID      string          `json:"message_id" bson:"message_id"` // Answer within the conversation
+	Rating         string          `json:"rating" bson:"rating"`         // "up" or "down"
+	Reason         string          `json:"reason,omitempty" bson:"reason,omitempty"`
+	Model          string          `json:"model,omitempty" bson:"model,omitempty"`   // Empty for answers older than prompt recording
+	Prompt         []PromptMessage `json:"prompt,omitempty" bson:"prompt,omitempty"` // Exact messages sent to the model
+	Question       string          `json:"question" bson:"question"`
+	Answer         string          `json:"answer" bson:"answer"`
+	Citations      []Citation      `json:"citations,omitempty" bson:"citations,omitempty"`
+	Conversation   []ChatMessage   `json:"conversation" bson:"conversation"` // Branch up to and including the answer
+	Reference      string          `json:"reference,omitempty" bson:"reference,omitempty"`
+	VerseText      string          `json:"verse_text,omitempty" bson:"verse_text,omitempty"`
+	CreatedAt      time.Time       `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// JobState is the persisted state of a scheduled background job, shared by all replicas.
+// The lock fields make sure only one replica runs a job at a time.
+type JobState struct {
+	Name          string    `json:"name" bson:"_id"`
+	Schedule      string    `json:"schedule" bson:"schedule"`
+	NextRunAt     time.Time `json:"next_run_at" bson:"next_run_at"`
+	LastRunAt     time.Time `json:"last_run_at,omitempty" bson:"last_run_at,omitempty"`
+	LastSuccessAt time.Time `json:"last_success_at,omitempty" bson:"last_success_at,omitempty"`
+	LastError     string    `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	LastDuration  string    `json:"last_duration,omitempty" bson:"last_duration,omitempty"`
+	RunCount      int       `json:"run_count" bson:"run_count"`
+	LockOwner     string    `json:"lock_owner,omitempty" bson:"lock_owner,omitempty"`
+	LockedUntil   time.Time `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// IsRunning reports whether a replica currently holds the job's lock
+func (j *JobState) IsRunning(now time.Time) bool {
+	return j.LockOwner != "" && now.Before(j.LockedUntil)
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LLMUsage is the token count and cost of one LLM request
+type LLMUsage struct {
+	ID               uuid.UUID `json:"id" bson:"_id"`
+	UserID           string    `json:"user_id,omitempty" bson:"user_id,omitempty"` // Empty for background jobs
+	Feature          string    `json:"feature" bson:"feature"`                     // e.g. "chat", "plan" or "topic"
+	Model            string    `json:"model" bson:"model"`
+	PromptTokens     int       `json:"prompt_tokens" bson:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens" bson:"completion_tokens"`
+	Cost             float64   `json:"cost" bson:"cost"`           // US dollars, from the configured price table
+	Estimated        bool      `json:"estimated" bson:"estimated"` // Token counts were estimated because the provider sent none
+	CreatedAt        time.Time `json:"created_at" bson:"created_at"`
+}
+
+// LLMUsageTotal sums the usage of one feature on one day
+type LLMUsageTotal struct {
+	Day              string  `json:"day"` // YYYY-MM-DD, UTC
+	Feature          string  `json:"feature"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+}
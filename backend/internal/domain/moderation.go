@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review statuses of a moderation flag
+const (
+	FlagStatusOpen      = "open"      // Waiting for a guardian or admin
+	FlagStatusResolved  = "resolved"  // Reviewed and followed up
+	FlagStatusDismissed = "dismissed" // Reviewed and found harmless
+)
+
+// IsValidFlagStatus reports whether a review status is known
+func IsValidFlagStatus(status string) bool {
+	return status == FlagStatusOpen || status == FlagStatusResolved || status == FlagStatusDismissed
+}
+
+// ModerationFlag is a chat exchange the moderation pipeline flagged, queued for review
+// by the user's guardians and admins
+type ModerationFlag struct {
+	ID              uuid.UUID              `json:"id" bson:"_id"`
+	UserID          string                 `json:"user_id" bson:"user_id"`
+	ConversationID  string                 `json:"conversation_id,omitempty" bson:"conversation_id,omitempty"` // Empty when a blocked question started no conversation
+	Stage           string                 `json:"stage" bson:"stage"`                                         // "question" or "answer"
+	Action          string                 `json:"action" bson:"action"`                                       // "soften" or "block"
+	Categories      []string               `json:"categories" bson:"categories"`
+	Reasons         []string               `json:"reasons,omitempty" bson:"reasons,omitempty"`
+	Question        string                 `json:"question" bson:"question"`
+	Answer          string                 `json:"answer,omitempty" bson:"answer,omitempty"`                     // The answer as generated, before softening
+	DeliveredAnswer string                 `json:"delivered_answer,omitempty" bson:"delivered_answer,omitempty"` // What the user was shown
+	Status          string                 `json:"status" bson:"status"`
+	Audit           []ModerationAuditEntry `json:"audit" bson:"audit"`
+	CreatedAt       time.Time              `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at" bson:"updated_at"`
+}
+
+// ModerationAuditEntry records who did what to a flag and when
+type ModerationAuditEntry struct {
+	ActorID string    `json:"actor_id" bson:"actor_id"` // "system" for the moderation pipeline
+	Status  string    `json:"status" bson:"status"`     // Status the flag was left in
+	Note    string    `json:"note,omitempty" bson:"note,omitempty"`
+	At      time.Time `json:"at" bson:"at"`
+}
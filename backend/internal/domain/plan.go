@@ -6,12 +6,31 @@ import (
 	"github.com/google/uuid"
 )
 
+// Resolution statuses for a day's reference, recorded when the plan is generated or re-validated
+const (
+	ResolutionStatusResolved   = "resolved"   // Every part of the reference was found in the verse repository
+	ResolutionStatusUnresolved = "unresolved" // At least one part of the reference has no verse text
+	ResolutionStatusUnverified = "unverified" // The check could not be performed (e.g., database error)
+)
+
 type DailyVerse struct {
-	DayNumber   int    `json:"day" bson:"day"`                                     // Day number within the plan (1-based)
-	Reference   string `json:"reference" bson:"reference"`                         // e.g., "John 3:16-18"
-	Text        string `json:"text" bson:"text"`                                   // The actual verse text (fetched later)
-	Title       string `json:"title" bson:"title"`                                 // Short title for the day's reading
-	Explanation string `json:"explanation,omitempty" bson:"explanation,omitempty"` // Optional explanation (fetched later)
+	DayNumber        int     `json:"day" bson:"day"`                                                 // Day number within the plan (1-based)
+	Reference        string  `json:"reference" bson:"reference"`                                     // e.g., "John 3:16-18"
+	Text             string  `json:"text" bson:"text"`                                               // The actual verse text (fetched later)
+	Title            string  `json:"title" bson:"title"`                                             // Short title for the day's reading
+	Explanation      string  `json:"explanation,omitempty" bson:"explanation,omitempty"`             // Optional explanation (fetched later)
+	ResolutionStatus string  `json:"resolution_status,omitempty" bson:"resolution_status,omitempty"` // Whether the reference resolves to verse text
+	ResolutionError  string  `json:"resolution_error,omitempty" bson:"resolution_error,omitempty"`   // Unresolved reference parts, if any
+	WordCount        int     `json:"word_count,omitempty" bson:"word_count,omitempty"`               // Words in the day's passage
+	EstimatedMinutes float64 `json:"estimated_minutes,omitempty" bson:"estimated_minutes,omitempty"` // Estimated reading time for the day
+
+	ExplanationGeneratedAt time.Time `json:"explanation_generated_at,omitempty" bson:"explanation_generated_at,omitempty"` // When the devotional was generated
+	PlanID                 string    `json:"plan_id,omitempty" bson:"-"`                                                   // Plan the day belongs to, set when served
+	PlanTopic              string    `json:"plan_topic,omitempty" bson:"-"`                                                // Topic of that plan, set when served
+
+	ReflectionQuestions []string       `json:"reflection_questions,omitempty" bson:"reflection_questions,omitempty"` // Open questions to think about after reading
+	Quiz                []QuizQuestion `json:"quiz,omitempty" bson:"quiz,omitempty"`                                 // Comprehension quiz on the day's passage
+	StudyGeneratedAt    time.Time      `json:"study_generated_at,omitempty" bson:"study_generated_at,omitempty"`     // When the questions and quiz were generated
 }
 
 type ReadingPlan struct {
@@ -21,9 +40,15 @@ type ReadingPlan struct {
 	DurationDays   int          `json:"duration_days" bson:"duration_days"`
 	TargetAudience string       `json:"target_audience" bson:"target_audience"` // Store the audience for context
 	CreatedAt      time.Time    `json:"created_at" bson:"created_at"`
-	StartDate      time.Time    `json:"start_date" bson:"start_date"`     // Calendar start date for the plan
-	EndDate        time.Time    `json:"end_date" bson:"end_date"`         // Calendar end date for the plan
-	DailyVerses    []DailyVerse `json:"daily_verses" bson:"daily_verses"` // Ordered list of verses for the plan
+	StartDate      time.Time    `json:"start_date" bson:"start_date"`                               // Calendar start date for the plan
+	EndDate        time.Time    `json:"end_date" bson:"end_date"`                                   // Calendar end date for the plan
+	DailyVerses    []DailyVerse `json:"daily_verses" bson:"daily_verses"`                           // Ordered list of verses for the plan
+	MinutesPerDay  int          `json:"minutes_per_day,omitempty" bson:"minutes_per_day,omitempty"` // Optional daily reading time target
+	TrackID        string       `json:"track_id,omitempty" bson:"track_id,omitempty"`               // Default plan track, only set on default plans
+	Theme          string       `json:"theme,omitempty" bson:"theme,omitempty"`                     // Calendar theme the default plan's topic was drawn from
+	Season         string       `json:"season,omitempty" bson:"season,omitempty"`                   // Liturgical season of the plan's start date
+	IsActive       bool         `json:"is_active" bson:"is_active"`                                 // The user's main plan, served first by /today
+	Priority       int          `json:"priority" bson:"priority"`                                   // Order among the user's other concurrent plans, highest first
 }
 
 // Helper to get verse for a specific day (1-based index)
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revision actions describe what produced a plan revision
+const (
+	RevisionActionCreate   = "create"   // Plan was generated
+	RevisionActionBaseline = "baseline" // State of a plan that existed before revisions were recorded
+	RevisionActionUpdate   = "update"   // Plan was edited
+	RevisionActionRollback = "rollback" // Plan was restored from an earlier revision
+)
+
+// RevisionAuthorSystem is recorded as the author of changes not made by a user
+const RevisionAuthorSystem = "system"
+
+// PlanRevision is an immutable snapshot of a plan taken after each change
+type PlanRevision struct {
+	ID        uuid.UUID   `json:"id" bson:"_id"`
+	PlanID    uuid.UUID   `json:"plan_id" bson:"plan_id"`
+	Revision  int         `json:"revision" bson:"revision"` // 1-based, increasing per plan
+	Author    string      `json:"author" bson:"author"`     // User ID that made the change
+	Action    string      `json:"action" bson:"action"`
+	Note      string      `json:"note,omitempty" bson:"note,omitempty"`
+	CreatedAt time.Time   `json:"created_at" bson:"created_at"`
+	Snapshot  ReadingPlan `json:"snapshot" bson:"snapshot"` // Full plan state after the change
+}
+
+// Day-level change kinds in a PlanDiff
+const (
+	DayAdded    = "added"
+	DayRemoved  = "removed"
+	DayModified = "modified"
+)
+
+// FieldChange records a changed value
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// DayDiff describes how a single plan day changed between two revisions
+type DayDiff struct {
+	Day    int           `json:"day"`
+	Change string        `json:"change"`
+	Fields []FieldChange `json:"fields,omitempty"` // Only set for modified days
+	Before *DailyVerse   `json:"before,omitempty"`
+	After  *DailyVerse   `json:"after,omitempty"`
+}
+
+// PlanDiff is the difference between two revisions of a plan
+type PlanDiff struct {
+	PlanID       string        `json:"plan_id"`
+	FromRevision int           `json:"from_revision"`
+	ToRevision   int           `json:"to_revision"`
+	PlanChanges  []FieldChange `json:"plan_changes"`
+	Days         []DayDiff     `json:"days"`
+}
+
+// DiffPlans compares two plan snapshots field by field and day by day.
+// Days are matched by day number; derived data (verse text, resolution status,
+// reading estimates) is ignored since it isn't edited by hand.
+func DiffPlans(from, to ReadingPlan) PlanDiff {
+	diff := PlanDiff{
+		PlanID:      to.ID.String(),
+		PlanChanges: []FieldChange{},
+		Days:        []DayDiff{},
+	}
+
+	diff.PlanChanges = appendIfChanged(diff.PlanChanges, "topic", from.Topic, to.Topic)
+	diff.PlanChanges = appendIfChanged(diff.PlanChanges, "duration_days", strconv.Itoa(from.DurationDays), strconv.Itoa(to.DurationDays))
+	diff.PlanChanges = appendIfChanged(diff.PlanChanges, "target_audience", from.TargetAudience, to.TargetAudience)
+	diff.PlanChanges = appendIfChanged(diff.PlanChanges, "minutes_per_day", strconv.Itoa(from.MinutesPerDay), strconv.Itoa(to.MinutesPerDay))
+
+	fromDays := make(map[int]DailyVerse, len(from.DailyVerses))
+	maxDay := 0
+	for _, dv := range from.DailyVerses {
+		fromDays[dv.DayNumber] = dv
+		if dv.DayNumber > maxDay {
+			maxDay = dv.DayNumber
+		}
+	}
+	toDays := make(map[int]DailyVerse, len(to.DailyVerses))
+	for _, dv := range to.DailyVerses {
+		toDays[dv.DayNumber] = dv
+		if dv.DayNumber > maxDay {
+			maxDay = dv.DayNumber
+		}
+	}
+
+	for day := 0; day <= maxDay; day++ {
+		before, inFrom := fromDays[day]
+		after, inTo := toDays[day]
+
+		switch {
+		case inFrom && !inTo:
+			diff.Days = append(diff.Days, DayDiff{Day: day, Change: DayRemoved, Before: &before})
+		case !inFrom && inTo:
+			diff.Days = append(diff.Days, DayDiff{Day: day, Change: DayAdded, After: &after})
+		case inFrom && inTo:
+			var fields []FieldChange
+			fields = appendIfChanged(fields, "reference", before.Reference, after.Reference)
+			fields = appendIfChanged(fields, "title", before.Title, after.Title)
+			fields = appendIfChanged(fields, "explanation", before.Explanation, after.Explanation)
+			if len(fields) > 0 {
+				diff.Days = append(diff.Days, DayDiff{Day: day, Change: DayModified, Fields: fields, Before: &before, After: &after})
+			}
+		}
+	}
+
+	return diff
+}
+
+func appendIfChanged(changes []FieldChange, field, from, to string) []FieldChange {
+	if from == to {
+		return changes
+	}
+	return append(changes, FieldChange{Field: field, From: from, To: to})
+}
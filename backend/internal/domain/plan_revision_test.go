@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffPlans(t *testing.T) {
+	base := ReadingPlan{
+		Topic:          "Hope",
+		DurationDays:   2,
+		TargetAudience: "adult",
+		DailyVerses: []DailyVerse{
+			{DayNumber: 1, Reference: "Romans 15:13", Title: "God of hope"},
+			{DayNumber: 2, Reference: "Psalms 42:1-11", Title: "Hope in God"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		edit            func(plan *ReadingPlan)
+		expectedChanges []FieldChange
+		expectedDays    []DayDiff // Compared without Before and After
+	}{
+		{
+			name:            "Unchanged plan",
+			edit:            func(plan *ReadingPlan) {},
+			expectedChanges: []FieldChange{},
+			expectedDays:    []DayDiff{},
+		},
+		{
+			name: "Derived data is ignored",
+			edit: func(plan *ReadingPlan) {
+				plan.DailyVerses[0].Text = "Now the God of hope fill you with all joy"
+				plan.DailyVerses[0].ResolutionStatus = ResolutionStatusResolved
+				plan.DailyVerses[1].WordCount = 240
+			},
+			expectedChanges: []FieldChange{},
+			expectedDays:    []DayDiff{},
+		},
+		{
+			name: "Added day",
+			edit: func(plan *ReadingPlan) {
+				plan.DurationDays = 3
+				plan.DailyVerses = append(plan.DailyVerses, DailyVerse{DayNumber: 3, Reference: "Hebrews 11:1", Title: "Faith"})
+			},
+			expectedChanges: []FieldChange{{Field: "duration_days", From: "2", To: "3"}},
+			expectedDays:    []DayDiff{{Day: 3, Change: DayAdded}},
+		},
+		{
+			name: "Removed day",
+			edit: func(plan *ReadingPlan) {
+				plan.DurationDays = 1
+				plan.DailyVerses = plan.DailyVerses[:1]
+			},
+			expectedChanges: []FieldChange{{Field: "duration_days", From: "2", To: "1"}},
+			expectedDays:    []DayDiff{{Day: 2, Change: DayRemoved}},
+		},
+		{
+			name: "Changed day and topic",
+			edit: func(plan *ReadingPlan) {
+				plan.Topic = "Hope in hard times"
+				plan.DailyVerses[1].Reference = "Lamentations 3:21-26"
+				plan.DailyVerses[1].Title = "New every morning"
+			},
+			expectedChanges: []FieldChange{{Field: "topic", From: "Hope", To: "Hope in hard times"}},
+			expectedDays: []DayDiff{{Day: 2, Change: DayModified, Fields: []FieldChange{
+				{Field: "reference", From: "Psalms 42:1-11", To: "Lamentations 3:21-26"},
+				{Field: "title", From: "Hope in God", To: "New every morning"},
+			}}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			to := base
+			to.DailyVerses = append([]DailyVerse(nil), base.DailyVerses...)
+			tc.edit(&to)
+
+			diff := DiffPlans(base, to)
+			assert.Equal(t, tc.expectedChanges, diff.PlanChanges)
+
+			days := []DayDiff{}
+			for _, day := range diff.Days {
+				switch day.Change {
+				case DayAdded:
+					assert.Nil(t, day.Before)
+					assert.Equal(t, to.DailyVerses[day.Day-1], *day.After)
+				case DayRemoved:
+					assert.Nil(t, day.After)
+					assert.Equal(t, base.DailyVerses[day.Day-1], *day.Before)
+				case DayModified:
+					assert.Equal(t, base.DailyVerses[day.Day-1], *day.Before)
+					assert.Equal(t, to.DailyVerses[day.Day-1], *day.After)
+				}
+				day.Before, day.After = nil, nil
+				days = append(days, day)
+			}
+			assert.Equal(t, tc.expectedDays, days)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package domain
+
+// Roles a user can hold. Roles are additive: a guardian is usually also a user.
+const (
+	RoleUser     = "user"     // Reads plans and chats
+	RoleGuardian = "guardian" // Oversees linked children's activity
+	RoleEditor   = "editor"   // Curates plan content, including other users' and default plans
+	RoleAdmin    = "admin"    // Full access, including user management
+)
+
+// Permission is a single capability checked by the API and services
+type Permission string
+
+const (
+	PermManageOwnPlans Permission = "plans:own"       // Create, read, edit and delete one's own plans
+	PermUseChat        Permission = "chat:use"        // Ask the chat assistant questions
+	PermViewWards      Permission = "wards:view"      // See the activity of linked children
+	PermEditAnyPlan    Permission = "plans:edit_any"  // Edit the content of any plan
+	PermManagePlans    Permission = "plans:manage"    // Delete any plan and run plan maintenance
+	PermManageUsers    Permission = "users:manage"    // Assign roles to users
+	PermManageJobs     Permission = "jobs:manage"     // View and trigger background jobs
+	PermExportFeedback Permission = "feedback:export" // Export rated chat answers for evaluation
+	PermViewUsage      Permission = "usage:view"      // See LLM usage and cost of all users
+)
+
+// rolePermissions lists what each role grants. Admins are granted everything.
+var rolePermissions = map[string][]Permission{
+	RoleUser:     {PermManageOwnPlans, PermUseChat},
+	RoleGuardian: {PermManageOwnPlans, PermUseChat, PermViewWards},
+	RoleEditor:   {PermManageOwnPlans, PermUseChat, PermEditAnyPlan},
+}
+
+// IsValidRole reports whether a role name is known
+func IsValidRole(role string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RolesAllow reports whether any of the roles grants the permission
+func RolesAllow(roles []string, perm Permission) bool {
+	for _, role := range roles {
+		if role == RoleAdmin {
+			return true
+		}
+		for _, p := range rolePermissions[role] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PermissionsForRoles returns the distinct permissions granted by a set of roles
+func PermissionsForRoles(roles []string) []Permission {
+	all := []Permission{PermManageOwnPlans, PermUseChat, PermViewWards, PermEditAnyPlan, PermManagePlans, PermManageUsers, PermManageJobs, PermExportFeedback, PermViewUsage}
+
+	var granted []Permission
+	for _, perm := range all {
+		if RolesAllow(roles, perm) {
+			granted = append(granted, perm)
+		}
+	}
+	return granted
+}
+
+// Actor identifies who is performing an operation, for permission checks in services
+type Actor struct {
+	UserID string
+	Roles  []string
+}
+
+// Can reports whether the actor holds a permission
+func (a Actor) Can(perm Permission) bool {
+	return RolesAllow(a.Roles, perm)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolesAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []string
+		perm     Permission
+		expected bool
+	}{
+		{name: "User manages own plans", roles: []string{RoleUser}, perm: PermManageOwnPlans, expected: true},
+		{name: "User can't view wards", roles: []string{RoleUser}, perm: PermViewWards, expected: false},
+		{name: "Guardian views wards", roles: []string{RoleGuardian}, perm: PermViewWards, expected: true},
+		{name: "Guardian can't edit any plan", roles: []string{RoleGuardian}, perm: PermEditAnyPlan, expected: false},
+		{name: "Editor edits any plan", roles: []string{RoleEditor}, perm: PermEditAnyPlan, expected: true},
+		{name: "Editor can't manage users", roles: []string{RoleEditor}, perm: PermManageUsers, expected: false},
+		{name: "Admin is granted everything", roles: []string{RoleAdmin}, perm: PermViewUsage, expected: true},
+		{name: "Roles add up", roles: []string{RoleGuardian, RoleEditor}, perm: PermEditAnyPlan, expected: true},
+		{name: "Unknown role grants nothing", roles: []string{"superuser"}, perm: PermUseChat, expected: false},
+		{name: "No roles grant nothing", roles: nil, perm: PermUseChat, expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RolesAllow(tc.roles, tc.perm))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuizQuestion is a multiple-choice question on a day's passage.
+// The answer is never sent to readers before they submit.
+type QuizQuestion struct {
+	Question    string   `json:"question" bson:"question"`
+	Options     []string `json:"options" bson:"options"`
+	AnswerIndex int      `json:"-" bson:"answer_index"`          // Index of the correct option
+	Explanation string   `json:"-" bson:"explanation,omitempty"` // Why the answer is correct, shown after submitting
+}
+
+// QuizAttempt records one submission of a day's quiz
+type QuizAttempt struct {
+	ID          uuid.UUID `json:"id" bson:"_id"`
+	UserID      string    `json:"user_id" bson:"user_id"`
+	PlanID      string    `json:"plan_id" bson:"plan_id"`
+	DayNumber   int       `json:"day" bson:"day"`
+	Reference   string    `json:"reference" bson:"reference"`
+	Answers     []int     `json:"answers" bson:"answers"`
+	Correct     int       `json:"correct" bson:"correct"`
+	Total       int       `json:"total" bson:"total"`
+	Score       float64   `json:"score" bson:"score"` // Fraction of correct answers, 0-1
+	SubmittedAt time.Time `json:"submitted_at" bson:"submitted_at"`
+}
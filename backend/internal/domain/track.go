@@ -0,0 +1,14 @@
+package domain
+
+// DefaultPlanUserID is the owner of system-generated default plans
+const DefaultPlanUserID = "default"
+
+// PlanTrack is a named series of default plans generated for one audience.
+// Users who haven't created their own plan read from the track they picked.
+type PlanTrack struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	TargetAudience string `json:"target_audience"`
+	Theme          string `json:"theme"`        // Theme new topics are drawn from
+	CadenceDays    int    `json:"cadence_days"` // Length of each generated plan, e.g. 7 for weekly
+}
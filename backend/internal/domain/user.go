@@ -7,11 +7,60 @@ import (
 // User represents a user in the system.
 // We store minimal info obtained from OAuth and our internal ID.
 type User struct {
-	ID        string    `bson:"_id,omitempty" json:"id"`         // Our internal MongoDB ID (_id)
-	GoogleID  string    `bson:"google_id" json:"google_id"` // Google's unique user ID
-	Email     string    `bson:"email" json:"email"`           // User's email
-	Name      string    `bson:"name" json:"name"`             // User's display name
-	Picture   string    `bson:"picture" json:"picture"`       // URL to profile picture
-	CreatedAt time.Time `bson:"created_at" json:"created_at"` // Timestamp of user creation
-	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"` // Timestamp of last update
+	ID          string           `bson:"_id,omitempty" json:"id"`                              // Our internal MongoDB ID (_id)
+	GoogleID    string           `bson:"google_id" json:"google_id"`                           // Google's unique user ID
+	Email       string           `bson:"email" json:"email"`                                   // User's email
+	Name        string           `bson:"name" json:"name"`                                     // User's display name
+	Picture     string           `bson:"picture" json:"picture"`                               // URL to profile picture
+	Roles       []string         `bson:"roles,omitempty" json:"roles"`                         // Assigned roles (see role.go); empty means RoleUser
+	TrackID     string           `bson:"track_id,omitempty" json:"track_id,omitempty"`         // Chosen default plan track; empty means the first configured track
+	GuardianIDs []string         `bson:"guardian_ids,omitempty" json:"guardian_ids,omitempty"` // Guardians who may see this user's activity
+	Audience    *AudienceProfile `bson:"audience,omitempty" json:"audience,omitempty"`         // Who answers and plans are written for; nil means the default profile
+	// AudienceLocked marks a profile set by an admin or guardian, which the user can neither change nor override
+	AudienceLocked bool   `bson:"audience_locked,omitempty" json:"audience_locked,omitempty"`
+	Timezone       string `bson:"timezone,omitempty" json:"timezone,omitempty"` // IANA time zone daily chat limits reset in; empty means UTC
+	// PreviousTimezone is the zone days are still counted in until TimezoneFrom, when Timezone takes over
+	PreviousTimezone string    `bson:"previous_timezone,omitempty" json:"-"`
+	TimezoneFrom     time.Time `bson:"timezone_from,omitempty" json:"-"`
+	ChatLimit        *int      `bson:"chat_limit,omitempty" json:"chat_limit,omitempty"` // Daily chat requests, overriding the role limits; 0 means unlimited
+	CreatedAt        time.Time `bson:"created_at" json:"created_at"`                     // Timestamp of user creation
+	UpdatedAt        time.Time `bson:"updated_at" json:"updated_at"`                     // Timestamp of last update
+}
+
+// TimezoneAt returns the time zone the user's day is counted in at a time. A changed zone only
+// takes over at the next midnight of the zone it replaces, so changing zones never starts a day early.
+func (u *User) TimezoneAt(t time.Time) string {
+	if t.Before(u.TimezoneFrom) {
+		return u.PreviousTimezone
+	}
+	return u.Timezone
+}
+
+// EffectiveRoles returns the user's roles, defaulting to RoleUser for
+// accounts created before roles existed
+func (u *User) EffectiveRoles() []string {
+	if len(u.Roles) == 0 {
+		return []string{RoleUser}
+	}
+	return u.Roles
+}
+
+// HasGuardian reports whether a user is linked to the guardian
+func (u *User) HasGuardian(guardianID string) bool {
+	for _, id := range u.GuardianIDs {
+		if id == guardianID {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the user holds a role
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.EffectiveRoles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserEffectiveRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []string
+		expected []string
+	}{
+		{name: "No stored roles means a plain user", roles: nil, expected: []string{RoleUser}},
+		{name: "Empty stored roles means a plain user", roles: []string{}, expected: []string{RoleUser}},
+		{name: "Stored roles are used as they are", roles: []string{RoleGuardian, RoleEditor}, expected: []string{RoleGuardian, RoleEditor}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user := User{Roles: tc.roles}
+			assert.Equal(t, tc.expected, user.EffectiveRoles())
+		})
+	}
+}
+
+func TestUserTimezoneAt(t *testing.T) {
+	takeover := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		user     User
+		at       time.Time
+		expected string
+	}{
+		{name: "No zone is UTC", user: User{}, at: takeover, expected: ""},
+		{name: "Settled zone", user: User{Timezone: "Europe/Berlin"}, at: takeover, expected: "Europe/Berlin"},
+		{
+			name:     "Previous zone until the change takes over",
+			user:     User{Timezone: "Asia/Tokyo", PreviousTimezone: "Europe/Berlin", TimezoneFrom: takeover},
+			at:       takeover.Add(-time.Second),
+			expected: "Europe/Berlin",
+		},
+		{
+			name:     "New zone from the takeover on",
+			user:     User{Timezone: "Asia/Tokyo", PreviousTimezone: "Europe/Berlin", TimezoneFrom: takeover},
+			at:       takeover,
+			expected: "Asia/Tokyo",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.user.TimezoneAt(tc.at))
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// --- Anthropic Implementation ---
+
+const (
+	// anthropicVersion is the Messages API version requests are written for
+	anthropicVersion = "2023-06-01"
+	// defaultAnthropicMaxTokens is used when a request sets no limit, as the API requires one
+	defaultAnthropicMaxTokens = 1024
+	// anthropicJSONInstruction stands in for the JSON response format, which the API lacks
+	anthropicJSONInstruction = "Respond only with a valid JSON object, without any other text."
+)
+
+// AnthropicClient talks to Anthropic's Messages API, converting to and from the chat completions format
+type AnthropicClient struct {
+	apiKey       string
+	baseURL      string
+	httpClient   *http.Client
+	streamClient *http.Client
+}
+
+// Ensure AnthropicClient implements LLMClient
+var _ LLMClient = (*AnthropicClient)(nil)
+
+// NewAnthropicClient creates a client for the Messages API, e.g. with the base URL "https://api.anthropic.com"
+func NewAnthropicClient(apiKey, baseURL string) *AnthropicClient {
+	httpClient, streamClient := newHTTPClients(60 * time.Second)
+	return &AnthropicClient{
+		apiKey:       apiKey,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		httpClient:   httpClient,
+		streamClient: streamClient,
+	}
+}
+
+type anthropicRequest struct {
+	Model       string               `json:"model"`
+	System      string               `json:"system,omitempty"`
+	Messages    []anthropicMessage   `json:"messages"`
+	MaxTokens   int                  `json:"max_tokens"`
+	Temperature float64              `json:"temperature,omitempty"`
+	Stream      bool                 `json:"stream,omitempty"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"` // "user" or "assistant"
+	Content []anthropicBlock `json:"content"`
+}
+
+// anthropicBlock is a content block: "text", "tool_use" or "tool_result"
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`          // tool_use
+	Name      string          `json:"name,omitempty"`        // tool_use
+	Input     json.RawMessage `json:"input,omitempty"`       // tool_use
+	ToolUseID string          `json:"tool_use_id,omitempty"` // tool_result
+	Content   string          `json:"content,omitempty"`     // tool_result
+}
+
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"` // "auto" or "none"
+}
+
+type anthropicResponse struct {
+	ID         string           `json:"id"`
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+	Error      *APIError        `json:"error,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicEvent is the data of one server-sent event of a streamed response
+type anthropicEvent struct {
+	Type         string            `json:"type"`
+	Index        int               `json:"index"`
+	Message      anthropicResponse `json:"message"`       // message_start
+	ContentBlock anthropicBlock    `json:"content_block"` // content_block_start
+	Delta        struct {
+		Type        string `json:"type"` // "text_delta" or "input_json_delta"
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"` // message_delta
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`           // message_delta
+	Error *APIError      `json:"error,omitempty"` // error
+}
+
+// CreateChatCompletion sends the request to /v1/messages and waits for the whole answer
+func (c *AnthropicClient) CreateChatCompletion(ctx context.Context, req ChatCompletionRequest) (ChatCompletionResponse, error) {
+	httpResp, err := c.send(ctx, c.httpClient, toAnthropicRequest(req, false))
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer httpResp.Body.Close()
+
+	var result anthropicResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&result); err != nil {
+		return ChatCompletionResponse{}, fmt.Errorf("failed to unmarshal Anthropic response: %w", err)
+	}
+	return result.toResponse(), nil
+}
+
+// CreateChatCompletionStream sends the request with "stream": true and reads the event stream
+func (c *AnthropicClient) CreateChatCompletionStream(ctx context.Context, req ChatCompletionRequest, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	httpResp, err := c.send(ctx, c.streamClient, toAnthropicRequest(req, true))
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer httpResp.Body.Close()
+	return readAnthropicStream(httpResp.Body, onDelta)
+}
+
+// send posts a Messages API request, turning error statuses into errors
+func (c *AnthropicClient) send(ctx context.Context, client *http.Client, req anthropicRequest) (*http.Response, error) {
+	if c.apiKey == "" {
+		return nil, errors.New("Anthropic API key is not configured")
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/messages", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Anthropic: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+		respBytes, _ := io.ReadAll(httpResp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", httpResp.StatusCode, string(respBytes))
+	}
+	return httpResp, nil
+}
+
+// toAnthropicRequest converts a chat completion request to the Messages API. System messages
+// move to the system prompt, tool results become user messages and consecutive messages of
+// the same role are merged, as the API expects the roles to alternate.
+func toAnthropicRequest(req ChatCompletionRequest, stream bool) anthropicRequest {
+	converted := anthropicRequest{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      stream,
+	}
+	if converted.MaxTokens <= 0 {
+		converted.MaxTokens = defaultAnthropicMaxTokens
+	}
+
+	var system []string
+	for _, message := range req.Messages {
+		role := message.Role
+		var blocks []anthropicBlock
+		switch message.Role {
+		case "system":
+			system = append(system, message.Content)
+			continue
+		case "tool":
+			role = "user"
+			blocks = append(blocks, anthropicBlock{Type: "tool_result", ToolUseID: message.ToolCallID, Content: message.Content})
+		default:
+			if message.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: message.Content})
+			}
+			for _, call := range message.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: input})
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if last := len(converted.Messages) - 1; last >= 0 && converted.Messages[last].Role == role {
+			converted.Messages[last].Content = append(converted.Messages[last].Content, blocks...)
+			continue
+		}
+		converted.Messages = append(converted.Messages, anthropicMessage{Role: role, Content: blocks})
+	}
+
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" {
+		system = append(system, anthropicJSONInstruction)
+	}
+	converted.System = strings.Join(system, "\n\n")
+
+	for _, tool := range req.Tools {
+		converted.Tools = append(converted.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: tool.Function.Parameters,
+		})
+	}
+	if len(converted.Tools) > 0 && req.ToolChoice != "" {
+		converted.ToolChoice = &anthropicToolChoice{Type: req.ToolChoice}
+	}
+	return converted
+}
+
+// toResponse converts a Messages API response to the chat completions format
+func (r anthropicResponse) toResponse() ChatCompletionResponse {
+	message := Message{Role: "assistant"}
+	var text strings.Builder
+	for _, block := range r.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "tool_use":
+			arguments := string(block.Input)
+			if arguments == "" {
+				arguments = "{}"
+			}
+			message.ToolCalls = append(message.ToolCalls, ToolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: ToolCallFunction{Name: block.Name, Arguments: arguments},
+			})
+		}
+	}
+	message.Content = text.String()
+	return ChatCompletionResponse{
+		ID:      r.ID,
+		Object:  "chat.completion",
+		Model:   r.Model,
+		Choices: []ChatChoice{{Message: message, FinishReason: anthropicFinishReason(r.StopReason)}},
+		Usage: &Usage{
+			PromptTokens:     r.Usage.InputTokens,
+			CompletionTokens: r.Usage.OutputTokens,
+			TotalTokens:      r.Usage.InputTokens + r.Usage.OutputTokens,
+		},
+	}
+}
+
+// anthropicFinishReason maps a stop reason to the matching finish reason
+func anthropicFinishReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return "stop"
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	}
+	return stopReason
+}
+
+// readAnthropicStream parses a Messages API event stream into a single response. Content
+// blocks are assembled by index, and the stream ends at message_stop or EOF.
+func readAnthropicStream(body io.Reader, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	var message anthropicResponse
+	var inputs []string // Partial JSON input of each tool_use block
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+
+	started, done := false, false
+	for !done && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // Event names are repeated in the data
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return ChatCompletionResponse{}, fmt.Errorf("failed to parse stream chunk: %w. Data: %s", err, data)
+		}
+
+		switch event.Type {
+		case "message_start":
+			message = event.Message
+			message.Content = nil
+			started = true
+		case "content_block_start":
+			for len(message.Content) <= event.Index {
+				message.Content = append(message.Content, anthropicBlock{})
+				inputs = append(inputs, "")
+			}
+			message.Content[event.Index] = event.ContentBlock
+			message.Content[event.Index].Input = nil
+		case "content_block_delta":
+			if event.Index >= len(message.Content) {
+				continue
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				message.Content[event.Index].Text += event.Delta.Text
+				if onDelta != nil && event.Delta.Text != "" {
+					if err := onDelta(event.Delta.Text); err != nil {
+						return ChatCompletionResponse{}, err
+					}
+				}
+			case "input_json_delta":
+				inputs[event.Index] += event.Delta.PartialJSON
+			}
+		case "message_delta":
+			if event.Delta.StopReason != "" {
+				message.StopReason = event.Delta.StopReason
+			}
+			message.Usage.OutputTokens = event.Usage.OutputTokens
+		case "message_stop":
+			done = true
+		case "error":
+			if event.Error != nil {
+				return ChatCompletionResponse{}, fmt.Errorf("Anthropic API error: type=%s, message=%s", event.Error.Type, event.Error.Message)
+			}
+			return ChatCompletionResponse{}, fmt.Errorf("Anthropic API error: %s", data)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ChatCompletionResponse{}, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if !started {
+		return ChatCompletionResponse{}, errors.New("stream ended before the completion started")
+	}
+
+	for i := range message.Content {
+		if message.Content[i].Type == "tool_use" && inputs[i] != "" {
+			message.Content[i].Input = json.RawMessage(inputs[i])
+		}
+	}
+	return message.toResponse(), nil
+}
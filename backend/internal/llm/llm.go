@@ -0,0 +1,77 @@
+package llm
+
+import "context"
+
+// --- Interfaces ---
+
+// LLMClient defines the interface for interacting with a language model.
+type LLMClient interface {
+	CreateChatCompletion(ctx context.Context, req ChatCompletionRequest) (ChatCompletionResponse, error)
+	// CreateChatCompletionStream streams the completion, calling onDelta with each piece of content
+	// as it arrives, and returns the assembled response once the stream ends.
+	// An error returned by onDelta stops the stream.
+	CreateChatCompletionStream(ctx context.Context, req ChatCompletionRequest, onDelta func(delta string) error) (ChatCompletionResponse, error)
+}
+
+// --- Structs (Copied from your example) ---
+
+type Message struct {
+	Role       string     `json:"role"` // "system", "user", "assistant" or "tool"
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Tools an assistant message calls
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Call a "tool" message returns the result of
+}
+
+type ChatCompletionRequest struct {
+	Model          string          `json:"model"` // e.g., "openai/gpt-3.5-turbo" or "google/gemini-pro" via OpenRouter
+	Messages       []Message       `json:"messages"`
+	Temperature    float64         `json:"temperature,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"` // Add this field
+	Stream         bool            `json:"stream,omitempty"`          // Set by CreateChatCompletionStream
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`  // Set by CreateChatCompletionStream
+	Tools          []Tool          `json:"tools,omitempty"`           // Functions the model may call
+	ToolChoice     string          `json:"tool_choice,omitempty"`     // ToolChoiceAuto or ToolChoiceNone; empty leaves it to the provider
+	// Add other OpenRouter specific fields if needed (e.g., transforms, route)
+}
+
+// StreamOptions asks OpenAI-compatible servers to end a stream with the token usage
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type ResponseFormat struct {
+	Type string `json:"type"` // e.g., "json_object"
+}
+
+type ChatCompletionResponse struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   *Usage       `json:"usage,omitempty"` // Use pointer for optional field
+	// Add Error field if OpenRouter returns errors within the JSON body
+	Error *APIError `json:"error,omitempty"`
+}
+
+// ChatChoice is one generated answer of a completion
+type ChatChoice struct {
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+	Index        int     `json:"index"`
+}
+
+// Usage reports the tokens a completion consumed
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// APIError is an error reported inside a response body
+type APIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    any    `json:"code"` // Can be string or int
+}
@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// --- Ollama Implementation ---
+
+// OllamaClient talks to Ollama's native chat API, for running a local model
+type OllamaClient struct {
+	baseURL      string
+	httpClient   *http.Client
+	streamClient *http.Client
+}
+
+// Ensure OllamaClient implements LLMClient
+var _ LLMClient = (*OllamaClient)(nil)
+
+// NewOllamaClient creates a client for an Ollama server, e.g. at "http://localhost:11434"
+func NewOllamaClient(baseURL string) *OllamaClient {
+	// Local models can take a while to load and answer on modest hardware
+	httpClient, streamClient := newHTTPClients(2 * time.Minute)
+	return &OllamaClient{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		httpClient:   httpClient,
+		streamClient: streamClient,
+	}
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   string          `json:"format,omitempty"` // "json" constrains the answer to JSON
+	Options  ollamaOptions   `json:"options,omitempty"`
+	Tools    []Tool          `json:"tools,omitempty"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature,omitempty"`
+	NumPredict  int     `json:"num_predict,omitempty"` // Most tokens to generate
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+// ollamaToolCall is a tool call, whose arguments are an object rather than a JSON string
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// ollamaResponse is a whole response, or one line of a streamed one
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	CreatedAt       time.Time     `json:"created_at"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+// CreateChatCompletion sends the request to /api/chat and waits for the whole answer
+func (c *OllamaClient) CreateChatCompletion(ctx context.Context, req ChatCompletionRequest) (ChatCompletionResponse, error) {
+	httpResp, err := c.send(ctx, c.httpClient, req, false)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer httpResp.Body.Close()
+
+	var result ollamaResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&result); err != nil {
+		return ChatCompletionResponse{}, fmt.Errorf("failed to unmarshal Ollama response: %w", err)
+	}
+	if result.Error != "" {
+		return ChatCompletionResponse{}, fmt.Errorf("Ollama API error: %s", result.Error)
+	}
+	return result.toResponse(result.Message.Content, result.Message.ToolCalls), nil
+}
+
+// CreateChatCompletionStream sends the request with "stream": true and reads the answer line by line
+func (c *OllamaClient) CreateChatCompletionStream(ctx context.Context, req ChatCompletionRequest, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	httpResp, err := c.send(ctx, c.streamClient, req, true)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer httpResp.Body.Close()
+	return readOllamaStream(httpResp.Body, onDelta)
+}
+
+// send posts a chat request, turning error statuses into errors
+func (c *OllamaClient) send(ctx context.Context, client *http.Client, req ChatCompletionRequest, stream bool) (*http.Response, error) {
+	body, err := json.Marshal(toOllamaRequest(req, stream))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+		respBytes, _ := io.ReadAll(httpResp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", httpResp.StatusCode, string(respBytes))
+	}
+	return httpResp, nil
+}
+
+// toOllamaRequest converts a chat completion request to Ollama's format
+func toOllamaRequest(req ChatCompletionRequest, stream bool) ollamaRequest {
+	converted := ollamaRequest{
+		Model:    req.Model,
+		Messages: make([]ollamaMessage, len(req.Messages)),
+		Stream:   stream,
+		Options:  ollamaOptions{Temperature: req.Temperature, NumPredict: req.MaxTokens},
+	}
+	if req.ToolChoice != ToolChoiceNone {
+		converted.Tools = req.Tools
+	}
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" {
+		converted.Format = "json"
+	}
+	for i, message := range req.Messages {
+		converted.Messages[i] = ollamaMessage{Role: message.Role, Content: message.Content}
+		for _, call := range message.ToolCalls {
+			var toolCall ollamaToolCall
+			toolCall.Function.Name = call.Function.Name
+			toolCall.Function.Arguments = json.RawMessage(call.Function.Arguments)
+			if !json.Valid(toolCall.Function.Arguments) {
+				toolCall.Function.Arguments = json.RawMessage("{}")
+			}
+			converted.Messages[i].ToolCalls = append(converted.Messages[i].ToolCalls, toolCall)
+		}
+	}
+	return converted
+}
+
+// toResponse converts the final Ollama response, carrying the assembled content and tool calls.
+// Ollama doesn't give tool calls IDs, so they are numbered.
+func (r ollamaResponse) toResponse(content string, calls []ollamaToolCall) ChatCompletionResponse {
+	message := Message{Role: "assistant", Content: content}
+	for i, call := range calls {
+		message.ToolCalls = append(message.ToolCalls, ToolCall{
+			ID:       fmt.Sprintf("call_%d", i),
+			Type:     "function",
+			Function: ToolCallFunction{Name: call.Function.Name, Arguments: string(call.Function.Arguments)},
+		})
+	}
+	finishReason := r.DoneReason
+	if len(message.ToolCalls) > 0 {
+		finishReason = "tool_calls"
+	}
+	return ChatCompletionResponse{
+		Object:  "chat.completion",
+		Created: r.CreatedAt.Unix(),
+		Model:   r.Model,
+		Choices: []ChatChoice{{Message: message, FinishReason: finishReason}},
+		Usage: &Usage{
+			PromptTokens:     r.PromptEvalCount,
+			CompletionTokens: r.EvalCount,
+			TotalTokens:      r.PromptEvalCount + r.EvalCount,
+		},
+	}
+}
+
+// readOllamaStream parses Ollama's newline-delimited JSON stream into a single response.
+// The last line has "done": true and carries the token counts.
+func readOllamaStream(body io.Reader, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	var content strings.Builder
+	var calls []ollamaToolCall
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var chunk ollamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return ChatCompletionResponse{}, fmt.Errorf("failed to parse stream chunk: %w. Data: %s", err, line)
+		}
+		if chunk.Error != "" {
+			return ChatCompletionResponse{}, fmt.Errorf("Ollama API error: %s", chunk.Error)
+		}
+
+		calls = append(calls, chunk.Message.ToolCalls...)
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if onDelta != nil {
+				if err := onDelta(chunk.Message.Content); err != nil {
+					return ChatCompletionResponse{}, err
+				}
+			}
+		}
+		if chunk.Done {
+			return chunk.toResponse(content.String(), calls), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ChatCompletionResponse{}, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return ChatCompletionResponse{}, errors.New("stream ended before the completion finished")
+}
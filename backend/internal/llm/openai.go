@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// --- OpenAI-Compatible Implementation ---
+
+// OpenAIClient talks to any server with an OpenAI-style chat completions API: OpenRouter,
+// OpenAI itself, or local servers such as vLLM, llama.cpp and LM Studio
+type OpenAIClient struct {
+	name         string // Provider name for errors
+	apiKey       string
+	endpoint     string
+	headers      map[string]string // Extra headers the provider expects
+	requireKey   bool              // Whether requests fail without an API key; local servers don't need one
+	httpClient   *http.Client
+	streamClient *http.Client // No overall timeout; streams are bounded by the request context
+}
+
+// Ensure OpenAIClient implements LLMClient
+var _ LLMClient = (*OpenAIClient)(nil)
+
+// NewOpenAIClient creates a client for an OpenAI-compatible server. The base URL includes
+// the API version, e.g. "https://api.openai.com/v1" or "http://localhost:8000/v1".
+func NewOpenAIClient(apiKey, baseURL string) *OpenAIClient {
+	httpClient, streamClient := newHTTPClients(30 * time.Second)
+	return &OpenAIClient{
+		name:         "OpenAI-compatible",
+		apiKey:       apiKey,
+		endpoint:     strings.TrimRight(baseURL, "/") + "/chat/completions",
+		httpClient:   httpClient,
+		streamClient: streamClient,
+	}
+}
+
+// NewOpenRouterClient creates a client for OpenRouter, e.g. with the base URL "https://openrouter.ai"
+func NewOpenRouterClient(apiKey, baseURL string) *OpenAIClient {
+	httpClient, streamClient := newHTTPClients(30 * time.Second)
+	return &OpenAIClient{
+		name:     "OpenRouter",
+		apiKey:   apiKey,
+		endpoint: fmt.Sprintf("%s/api/v1/chat/completions", strings.TrimRight(baseURL, "/")), // OpenRouter usually uses /api/v1/
+		// IMPORTANT: OpenRouter requires these headers
+		headers: map[string]string{
+			"HTTP-Referer": "urn:app://bible-app", // Replace with your app URL if deployed
+			"X-Title":      "Bible App Niece",     // Replace with your app name
+		},
+		requireKey:   true,
+		httpClient:   httpClient,
+		streamClient: streamClient,
+	}
+}
+
+// newHTTPClients returns a client for whole responses, which must arrive within the timeout,
+// and one for streams, whose first byte must
+func newHTTPClients(timeout time.Duration) (*http.Client, *http.Client) {
+	return &http.Client{Timeout: timeout}, &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: timeout,
+		},
+	}
+}
+
+// --- Client Method (Copied and slightly adapted from your example) ---
+
+func (c *OpenAIClient) CreateChatCompletion(ctx context.Context, req ChatCompletionRequest) (ChatCompletionResponse, error) {
+	var response ChatCompletionResponse
+
+	if c.requireKey && c.apiKey == "" {
+		return response, fmt.Errorf("%s API key is not configured", c.name)
+	}
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return response, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := c.newRequest(ctx, reqBytes)
+	if err != nil {
+		return response, err
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return response, fmt.Errorf("failed to send request to %s: %w", c.name, err)
+	}
+	defer httpResp.Body.Close()
+
+	respBytes, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return response, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Try unmarshalling first, as errors might be in the JSON body
+	if err := json.Unmarshal(respBytes, &response); err != nil {
+		// If unmarshalling fails, return the raw body for context, especially on non-200 status
+		if httpResp.StatusCode != http.StatusOK {
+			return response, fmt.Errorf("API request failed with status %d: %s", httpResp.StatusCode, string(respBytes))
+		}
+		// If status is OK but unmarshal failed, it's a different issue
+		return response, fmt.Errorf("failed to unmarshal response (status %d): %w. Body: %s", httpResp.StatusCode, err, string(respBytes))
+	}
+
+	// Check for API errors *within* the JSON response
+	if response.Error != nil {
+		return response, fmt.Errorf("%s API error: type=%s, code=%v, message=%s", c.name, response.Error.Type, response.Error.Code, response.Error.Message)
+	}
+
+	// Check status code after attempting to parse potential JSON error messages
+	if httpResp.StatusCode != http.StatusOK {
+		// We already tried unmarshalling, so the error might be structured or just plain text
+		errMsg := string(respBytes)
+		if response.Error != nil { // If we *did* parse an error object
+			errMsg = fmt.Sprintf("type=%s, code=%v, message=%s", response.Error.Type, response.Error.Code, response.Error.Message)
+		}
+		return response, fmt.Errorf("API request failed with status %d: %s", httpResp.StatusCode, errMsg)
+	}
+
+	return response, nil
+}
+
+// newRequest builds a chat completions request with the headers the provider expects
+func (c *OpenAIClient) newRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	for name, value := range c.headers {
+		httpReq.Header.Set(name, value)
+	}
+	return httpReq, nil
+}
@@ -0,0 +1,237 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToAnthropicRequestConvertsMessages(t *testing.T) {
+	req := ChatCompletionRequest{
+		Model: "claude",
+		Messages: []Message{
+			{Role: "system", Content: "Be kind."},
+			{Role: "system", Content: "Cite verses."},
+			{Role: "user", Content: "What is John 3:16?"},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: ToolCallFunction{Name: "lookup_passage", Arguments: `{"reference":"John 3:16"}`}}}},
+			{Role: "tool", ToolCallID: "call_1", Content: "For God so loved the world"},
+		},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+		Tools:          []Tool{NewFunctionTool("lookup_passage", "Look up a passage.", `{"type":"object"}`)},
+		ToolChoice:     ToolChoiceNone,
+	}
+
+	converted := toAnthropicRequest(req, true)
+
+	assert.Equal(t, "Be kind.\n\nCite verses.\n\n"+anthropicJSONInstruction, converted.System)
+	assert.Equal(t, defaultAnthropicMaxTokens, converted.MaxTokens)
+	assert.True(t, converted.Stream)
+	require.Len(t, converted.Messages, 3)
+	assert.Equal(t, "user", converted.Messages[0].Role)
+	assert.Equal(t, "assistant", converted.Messages[1].Role)
+	require.Len(t, converted.Messages[1].Content, 1)
+	assert.Equal(t, "tool_use", converted.Messages[1].Content[0].Type)
+	assert.JSONEq(t, `{"reference":"John 3:16"}`, string(converted.Messages[1].Content[0].Input))
+	assert.Equal(t, "user", converted.Messages[2].Role)
+	assert.Equal(t, "call_1", converted.Messages[2].Content[0].ToolUseID)
+	require.Len(t, converted.Tools, 1)
+	assert.Equal(t, "lookup_passage", converted.Tools[0].Name)
+	require.NotNil(t, converted.ToolChoice)
+	assert.Equal(t, "none", converted.ToolChoice.Type)
+}
+
+func TestReadAnthropicStreamAssemblesBlocks(t *testing.T) {
+	body := strings.Join([]string{
+		"event: message_start",
+		`data: {"type":"message_start","message":{"id":"msg_1","model":"claude","content":[],"usage":{"input_tokens":12,"output_tokens":1}}}`,
+		"",
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me "}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"check."}}`,
+		`data: {"type":"ping"}`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"search_verses","input":{}}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"query\":"}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"love\"}"}}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+		`data: {"type":"message_stop"}`,
+	}, "\n")
+
+	var deltas []string
+	response, err := readAnthropicStream(strings.NewReader(body), func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Let me ", "check."}, deltas)
+	require.Len(t, response.Choices, 1)
+	message := response.Choices[0].Message
+	assert.Equal(t, "Let me check.", message.Content)
+	assert.Equal(t, "tool_calls", response.Choices[0].FinishReason)
+	require.Len(t, message.ToolCalls, 1)
+	assert.Equal(t, "toolu_1", message.ToolCalls[0].ID)
+	assert.Equal(t, "search_verses", message.ToolCalls[0].Function.Name)
+	assert.JSONEq(t, `{"query":"love"}`, message.ToolCalls[0].Function.Arguments)
+	require.NotNil(t, response.Usage)
+	assert.Equal(t, 12, response.Usage.PromptTokens)
+	assert.Equal(t, 20, response.Usage.CompletionTokens)
+}
+
+func TestReadAnthropicStreamReportsErrors(t *testing.T) {
+	body := `data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`
+
+	_, err := readAnthropicStream(strings.NewReader(body), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Overloaded")
+}
+
+func TestReadOllamaStreamAssemblesLines(t *testing.T) {
+	body := strings.Join([]string{
+		`{"model":"llama3.1","message":{"role":"assistant","content":"Grace "},"done":false}`,
+		`{"model":"llama3.1","message":{"role":"assistant","content":"and peace"},"done":false}`,
+		`{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":9,"eval_count":4}`,
+	}, "\n")
+
+	var deltas []string
+	response, err := readOllamaStream(strings.NewReader(body), func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Grace ", "and peace"}, deltas)
+	assert.Equal(t, "llama3.1", response.Model)
+	require.Len(t, response.Choices, 1)
+	assert.Equal(t, "Grace and peace", response.Choices[0].Message.Content)
+	assert.Equal(t, "stop", response.Choices[0].FinishReason)
+	require.NotNil(t, response.Usage)
+	assert.Equal(t, 13, response.Usage.TotalTokens)
+}
+
+func TestReadOllamaStreamConvertsToolCalls(t *testing.T) {
+	body := strings.Join([]string{
+		`{"model":"llama3.1","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"lookup_passage","arguments":{"reference":"Psalm 23"}}}]},"done":false}`,
+		`{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}`,
+	}, "\n")
+
+	response, err := readOllamaStream(strings.NewReader(body), nil)
+	require.NoError(t, err)
+
+	require.Len(t, response.Choices, 1)
+	assert.Equal(t, "tool_calls", response.Choices[0].FinishReason)
+	require.Len(t, response.Choices[0].Message.ToolCalls, 1)
+	call := response.Choices[0].Message.ToolCalls[0]
+	assert.Equal(t, "call_0", call.ID)
+	assert.Equal(t, "lookup_passage", call.Function.Name)
+	assert.JSONEq(t, `{"reference":"Psalm 23"}`, call.Function.Arguments)
+}
+
+func TestReadOllamaStreamFailsWhenCutShort(t *testing.T) {
+	body := `{"model":"llama3.1","message":{"role":"assistant","content":"Grace "},"done":false}`
+
+	_, err := readOllamaStream(strings.NewReader(body), nil)
+	assert.Error(t, err)
+}
+
+// stubClient answers with a fixed response or error, streaming the content first
+type stubClient struct {
+	response ChatCompletionResponse
+	err      error
+	models   []string // Models requested, in order
+}
+
+func (c *stubClient) CreateChatCompletion(ctx context.Context, req ChatCompletionRequest) (ChatCompletionResponse, error) {
+	c.models = append(c.models, req.Model)
+	return c.response, c.err
+}
+
+func (c *stubClient) CreateChatCompletionStream(ctx context.Context, req ChatCompletionRequest, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	c.models = append(c.models, req.Model)
+	if len(c.response.Choices) > 0 {
+		if err := onDelta(c.response.Choices[0].Message.Content); err != nil {
+			return ChatCompletionResponse{}, err
+		}
+	}
+	return c.response, c.err
+}
+
+func answer(content string) ChatCompletionResponse {
+	return ChatCompletionResponse{Choices: []ChatChoice{{Message: Message{Role: "assistant", Content: content}}}}
+}
+
+func TestFallbackClientFallsBackToNextProvider(t *testing.T) {
+	remote := &stubClient{err: errors.New("unavailable")}
+	local := &stubClient{response: answer("Amen")}
+	registry := &Registry{providers: map[string]LLMClient{"remote": remote, "local": local}}
+	client, err := registry.Route([]RouteStep{{Provider: "remote"}, {Provider: "local", Model: "llama3.1"}})
+	require.NoError(t, err)
+
+	response, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{Model: "openai/gpt-4o"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Amen", response.Choices[0].Message.Content)
+	assert.Equal(t, "llama3.1", response.Model)
+	assert.Equal(t, []string{"openai/gpt-4o"}, remote.models)
+	assert.Equal(t, []string{"llama3.1"}, local.models)
+}
+
+func TestFallbackClientReturnsAllErrors(t *testing.T) {
+	registry := &Registry{providers: map[string]LLMClient{
+		"remote": &stubClient{err: errors.New("unavailable")},
+		"local":  &stubClient{err: errors.New("not running")},
+	}}
+	client, err := registry.Route([]RouteStep{{Provider: "remote"}, {Provider: "local"}})
+	require.NoError(t, err)
+
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unavailable")
+	assert.Contains(t, err.Error(), "not running")
+}
+
+func TestFallbackClientKeepsStreamThatStarted(t *testing.T) {
+	remote := &stubClient{response: answer("In the "), err: errors.New("connection reset")}
+	local := &stubClient{response: answer("Amen")}
+	registry := &Registry{providers: map[string]LLMClient{"remote": remote, "local": local}}
+	client, err := registry.Route([]RouteStep{{Provider: "remote"}, {Provider: "local"}})
+	require.NoError(t, err)
+
+	var deltas []string
+	_, err = client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"In the "}, deltas)
+	assert.Empty(t, local.models)
+}
+
+func TestRegistryRouteRejectsUnknownProvider(t *testing.T) {
+	registry, err := NewRegistry(map[string]ProviderConfig{"local": {Type: ProviderOllama}})
+	require.NoError(t, err)
+
+	_, err = registry.Route([]RouteStep{{Provider: "remote"}})
+	assert.Error(t, err)
+}
+
+func TestFeatureRouterUsesFeatureRoute(t *testing.T) {
+	chat := &stubClient{response: answer("chat")}
+	other := &stubClient{response: answer("default")}
+	feature := ""
+	router := NewFeatureRouter(map[string]LLMClient{"chat": chat}, other, func(ctx context.Context) string { return feature })
+
+	feature = "chat"
+	response, err := router.CreateChatCompletion(context.Background(), ChatCompletionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "chat", response.Choices[0].Message.Content)
+
+	feature = "plan"
+	response, err = router.CreateChatCompletion(context.Background(), ChatCompletionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "default", response.Choices[0].Message.Content)
+}
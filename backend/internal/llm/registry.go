@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Provider types a ProviderConfig can name
+const (
+	ProviderOpenRouter = "openrouter" // OpenRouter
+	ProviderOpenAI     = "openai"     // Any OpenAI-compatible server, e.g. OpenAI, vLLM, llama.cpp or LM Studio
+	ProviderOllama     = "ollama"     // Ollama's native API
+	ProviderAnthropic  = "anthropic"  // Anthropic's Messages API
+)
+
+// DefaultRoute is the route of features without one of their own
+const DefaultRoute = "default"
+
+// BudgetRoute is the route every request takes once the monthly LLM budget is spent
+const BudgetRoute = "budget"
+
+// defaultBaseURLs are used for providers configured without a base URL
+var defaultBaseURLs = map[string]string{
+	ProviderOpenRouter: "https://openrouter.ai",
+	ProviderOpenAI:     "https://api.openai.com/v1",
+	ProviderOllama:     "http://localhost:11434",
+	ProviderAnthropic:  "https://api.anthropic.com",
+}
+
+// ProviderConfig configures an LLM provider
+type ProviderConfig struct {
+	Type    string `json:"type"`               // One of the Provider types
+	BaseURL string `json:"base_url,omitempty"` // Empty for the provider's usual URL
+	APIKey  string `json:"api_key,omitempty"`
+}
+
+// RouteStep names a provider to send requests to, and optionally the model to use there.
+// Without a model the request's own model is sent.
+type RouteStep struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+}
+
+// IsProviderType reports whether a provider type is supported
+func IsProviderType(providerType string) bool {
+	_, ok := defaultBaseURLs[providerType]
+	return ok
+}
+
+// NewProvider creates the client for a provider
+func NewProvider(cfg ProviderConfig) (LLMClient, error) {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURLs[cfg.Type]
+	}
+	switch cfg.Type {
+	case ProviderOpenRouter:
+		return NewOpenRouterClient(cfg.APIKey, baseURL), nil
+	case ProviderOpenAI:
+		return NewOpenAIClient(cfg.APIKey, baseURL), nil
+	case ProviderOllama:
+		return NewOllamaClient(baseURL), nil
+	case ProviderAnthropic:
+		return NewAnthropicClient(cfg.APIKey, baseURL), nil
+	}
+	return nil, fmt.Errorf("unknown LLM provider type %q", cfg.Type)
+}
+
+// Registry holds the configured providers by name
+type Registry struct {
+	providers map[string]LLMClient
+}
+
+// NewRegistry creates the clients of the configured providers
+func NewRegistry(configs map[string]ProviderConfig) (*Registry, error) {
+	registry := &Registry{providers: make(map[string]LLMClient, len(configs))}
+	for name, cfg := range configs {
+		client, err := NewProvider(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", name, err)
+		}
+		registry.providers[name] = client
+	}
+	return registry, nil
+}
+
+// Route returns a client sending requests along the steps, falling back to each next step
+// when one fails
+func (r *Registry) Route(steps []RouteStep) (LLMClient, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("a route needs at least one provider")
+	}
+	fallback := &FallbackClient{}
+	for _, step := range steps {
+		client, ok := r.providers[step.Provider]
+		if !ok {
+			return nil, fmt.Errorf("unknown LLM provider %q", step.Provider)
+		}
+		fallback.steps = append(fallback.steps, routeStep{RouteStep: step, client: client})
+	}
+	return fallback, nil
+}
+
+type routeStep struct {
+	RouteStep
+	client LLMClient
+}
+
+// FallbackClient sends a request to each step of a route in turn until one succeeds. It doesn't
+// fall back once a stream has sent content, as that can't be taken back, nor once the request's
+// context is done.
+type FallbackClient struct {
+	steps []routeStep
+}
+
+// Ensure FallbackClient implements LLMClient
+var _ LLMClient = (*FallbackClient)(nil)
+
+// CreateChatCompletion tries each step until one answers
+func (c *FallbackClient) CreateChatCompletion(ctx context.Context, req ChatCompletionRequest) (ChatCompletionResponse, error) {
+	return c.try(ctx, req, func(step routeStep, stepReq ChatCompletionRequest) (ChatCompletionResponse, bool, error) {
+		response, err := step.client.CreateChatCompletion(ctx, stepReq)
+		return response, false, err
+	})
+}
+
+// CreateChatCompletionStream tries each step until one answers or starts streaming
+func (c *FallbackClient) CreateChatCompletionStream(ctx context.Context, req ChatCompletionRequest, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	return c.try(ctx, req, func(step routeStep, stepReq ChatCompletionRequest) (ChatCompletionResponse, bool, error) {
+		started := false
+		response, err := step.client.CreateChatCompletionStream(ctx, stepReq, func(delta string) error {
+			started = true
+			if onDelta == nil {
+				return nil
+			}
+			return onDelta(delta)
+		})
+		return response, started, err
+	})
+}
+
+// try runs a request on each step in turn. send reports whether the step got far enough that
+// falling back would repeat output.
+func (c *FallbackClient) try(ctx context.Context, req ChatCompletionRequest, send func(step routeStep, stepReq ChatCompletionRequest) (ChatCompletionResponse, bool, error)) (ChatCompletionResponse, error) {
+	var errs []string
+	for i, step := range c.steps {
+		stepReq := req
+		if step.Model != "" {
+			stepReq.Model = step.Model
+		}
+		response, started, err := send(step, stepReq)
+		if err == nil {
+			if response.Model == "" {
+				response.Model = stepReq.Model
+			}
+			return response, nil
+		}
+		if started || ctx.Err() != nil || i == len(c.steps)-1 {
+			if len(errs) == 0 {
+				return response, err
+			}
+			return response, fmt.Errorf("%s; %s: %w", strings.Join(errs, "; "), step.Provider, err)
+		}
+		log.Printf("WARN: LLM provider %s failed for model %s, falling back to %s: %v", step.Provider, stepReq.Model, c.steps[i+1].Provider, err)
+		errs = append(errs, fmt.Sprintf("%s: %v", step.Provider, err))
+	}
+	return ChatCompletionResponse{}, errors.New("a route needs at least one provider")
+}
+
+// FeatureRouter sends each request to the route of the feature it is made for, or the default route
+type FeatureRouter struct {
+	routes    map[string]LLMClient
+	fallback  LLMClient
+	featureOf func(ctx context.Context) string
+}
+
+// Ensure FeatureRouter implements LLMClient
+var _ LLMClient = (*FeatureRouter)(nil)
+
+// NewFeatureRouter creates a router; featureOf tells the feature a request's context is tagged with
+func NewFeatureRouter(routes map[string]LLMClient, defaultRoute LLMClient, featureOf func(ctx context.Context) string) *FeatureRouter {
+	return &FeatureRouter{routes: routes, fallback: defaultRoute, featureOf: featureOf}
+}
+
+// CreateChatCompletion sends the request along its feature's route
+func (r *FeatureRouter) CreateChatCompletion(ctx context.Context, req ChatCompletionRequest) (ChatCompletionResponse, error) {
+	return r.route(ctx).CreateChatCompletion(ctx, req)
+}
+
+// CreateChatCompletionStream streams the request along its feature's route
+func (r *FeatureRouter) CreateChatCompletionStream(ctx context.Context, req ChatCompletionRequest, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	return r.route(ctx).CreateChatCompletionStream(ctx, req, onDelta)
+}
+
+func (r *FeatureRouter) route(ctx context.Context) LLMClient {
+	if client, ok := r.routes[r.featureOf(ctx)]; ok {
+		return client
+	}
+	return r.fallback
+}
@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// streamChunk is one server-sent event of an OpenAI-style streamed completion
+type streamChunk struct {
+	ID      string `json:"id"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Role      string          `json:"role"`
+			Content   string          `json:"content"`
+			ToolCalls []toolCallDelta `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+		Index        int     `json:"index"`
+	} `json:"choices"`
+	Usage *Usage    `json:"usage,omitempty"`
+	Error *APIError `json:"error,omitempty"`
+}
+
+// toolCallDelta is a piece of a streamed tool call. The first piece of each call carries its
+// ID and name; the arguments arrive in fragments.
+type toolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// maxStreamLineBytes bounds a single SSE line; chunks are small but usage lines can be long
+const maxStreamLineBytes = 1024 * 1024
+
+// CreateChatCompletionStream sends the request with "stream": true and reads the event stream
+func (c *OpenAIClient) CreateChatCompletionStream(ctx context.Context, req ChatCompletionRequest, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	if c.requireKey && c.apiKey == "" {
+		return ChatCompletionResponse{}, fmt.Errorf("%s API key is not configured", c.name)
+	}
+
+	req.Stream = true
+	req.StreamOptions = &StreamOptions{IncludeUsage: true}
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return ChatCompletionResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := c.newRequest(ctx, reqBytes)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	httpResp, err := c.streamClient.Do(httpReq)
+	if err != nil {
+		return ChatCompletionResponse{}, fmt.Errorf("failed to send request to %s: %w", c.name, err)
+	}
+	defer httpResp.Body.Close()
+
+	// Errors are reported as a regular JSON body rather than a stream
+	if httpResp.StatusCode != http.StatusOK {
+		respBytes, _ := io.ReadAll(httpResp.Body)
+		return ChatCompletionResponse{}, fmt.Errorf("API request failed with status %d: %s", httpResp.StatusCode, string(respBytes))
+	}
+
+	return readStream(httpResp.Body, onDelta)
+}
+
+// readStream parses an OpenAI-style event stream into a single response, assembling any tool calls.
+// Comment lines (such as OpenRouter's keep-alive ": OPENROUTER PROCESSING") are skipped,
+// and the stream ends at "data: [DONE]" or EOF.
+func readStream(body io.Reader, onDelta func(delta string) error) (ChatCompletionResponse, error) {
+	var response ChatCompletionResponse
+	var content strings.Builder
+	var role, finishReason string
+	var toolCalls []ToolCall
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+
+	done := false
+	for !done && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return response, fmt.Errorf("failed to parse stream chunk: %w. Data: %s", err, data)
+		}
+		if chunk.Error != nil {
+			return response, fmt.Errorf("API error: type=%s, code=%v, message=%s", chunk.Error.Type, chunk.Error.Code, chunk.Error.Message)
+		}
+
+		if response.ID == "" {
+			response.ID = chunk.ID
+			response.Created = chunk.Created
+			response.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			response.Usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue // Only one choice is ever requested
+			}
+			if choice.Delta.Role != "" {
+				role = choice.Delta.Role
+			}
+			if choice.FinishReason != nil {
+				finishReason = *choice.FinishReason
+			}
+			for _, delta := range choice.Delta.ToolCalls {
+				toolCalls = appendToolCallDelta(toolCalls, delta)
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				if err := onDelta(choice.Delta.Content); err != nil {
+					return response, err
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return response, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if !done && finishReason == "" {
+		return response, errors.New("stream ended before the completion finished")
+	}
+
+	if role == "" {
+		role = "assistant"
+	}
+	response.Object = "chat.completion"
+	response.Choices = []ChatChoice{{
+		Message:      Message{Role: role, Content: content.String(), ToolCalls: toolCalls},
+		FinishReason: finishReason,
+	}}
+	return response, nil
+}
+
+// appendToolCallDelta adds a piece of a streamed tool call to the calls assembled so far
+func appendToolCallDelta(calls []ToolCall, delta toolCallDelta) []ToolCall {
+	if delta.Index < 0 {
+		return calls
+	}
+	for len(calls) <= delta.Index {
+		calls = append(calls, ToolCall{Type: "function"})
+	}
+	call := &calls[delta.Index]
+	if delta.ID != "" {
+		call.ID = delta.ID
+	}
+	if delta.Type != "" {
+		call.Type = delta.Type
+	}
+	if delta.Function.Name != "" {
+		call.Function.Name = delta.Function.Name
+	}
+	call.Function.Arguments += delta.Function.Arguments
+	return calls
+}
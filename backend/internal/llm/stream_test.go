@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStreamAssemblesDeltas(t *testing.T) {
+	body := strings.Join([]string{
+		": OPENROUTER PROCESSING",
+		"",
+		`data: {"id":"gen-1","model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`,
+		`data: {"id":"gen-1","choices":[{"index":0,"delta":{"content":"In the "}}]}`,
+		`data: {"id":"gen-1","choices":[{"index":0,"delta":{"content":"beginning"},"finish_reason":null}]}`,
+		`data: {"id":"gen-1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`,
+		"data: [DONE]",
+		"",
+	}, "\n")
+
+	var deltas []string
+	response, err := readStream(strings.NewReader(body), func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"In the ", "beginning"}, deltas)
+	require.Len(t, response.Choices, 1)
+	assert.Equal(t, "In the beginning", response.Choices[0].Message.Content)
+	assert.Equal(t, "assistant", response.Choices[0].Message.Role)
+	assert.Equal(t, "stop", response.Choices[0].FinishReason)
+	assert.Equal(t, "gen-1", response.ID)
+	require.NotNil(t, response.Usage)
+	assert.Equal(t, 8, response.Usage.TotalTokens)
+}
+
+func TestReadStreamReportsErrors(t *testing.T) {
+	_, err := readStream(strings.NewReader(`data: {"error":{"message":"overloaded","type":"server_error","code":502}}`+"\n"), nil)
+	assert.ErrorContains(t, err, "overloaded")
+
+	_, err = readStream(strings.NewReader(`data: {"choices":[{"index":0,"delta":{"content":"cut"}}]}`+"\n"), nil)
+	assert.ErrorContains(t, err, "ended before the completion finished")
+
+	stop := errors.New("client went away")
+	_, err = readStream(strings.NewReader(`data: {"choices":[{"index":0,"delta":{"content":"x"}}]}`+"\ndata: [DONE]\n"),
+		func(string) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestReadStreamAssemblesToolCalls(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"id":"gen-2","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup_passage","arguments":""}}]}}]}`,
+		`data: {"id":"gen-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"reference\":"}}]}}]}`,
+		`data: {"id":"gen-2","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"John 3:16\"}"}},{"index":1,"id":"call_2","type":"function","function":{"name":"get_todays_reading","arguments":"{}"}}]}}]}`,
+		`data: {"id":"gen-2","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		"data: [DONE]",
+	}, "\n")
+
+	response, err := readStream(strings.NewReader(body), nil)
+	require.NoError(t, err)
+
+	require.Len(t, response.Choices, 1)
+	assert.Equal(t, "tool_calls", response.Choices[0].FinishReason)
+	assert.Empty(t, response.Choices[0].Message.Content)
+	assert.Equal(t, []ToolCall{
+		{ID: "call_1", Type: "function", Function: ToolCallFunction{Name: "lookup_passage", Arguments: `{"reference":"John 3:16"}`}},
+		{ID: "call_2", Type: "function", Function: ToolCallFunction{Name: "get_todays_reading", Arguments: "{}"}},
+	}, response.Choices[0].Message.ToolCalls)
+}
@@ -0,0 +1,40 @@
+package llm
+
+import "encoding/json"
+
+// Tool choices for ChatCompletionRequest.ToolChoice
+const (
+	ToolChoiceAuto = "auto" // The model decides whether to call tools
+	ToolChoiceNone = "none" // The model must answer without calling tools
+)
+
+// Tool is a function the model may call, OpenAI style
+type Tool struct {
+	Type     string       `json:"type"` // Always "function"
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction describes a callable function with a JSON Schema of its arguments
+type ToolFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// NewFunctionTool describes a function tool
+func NewFunctionTool(name string, description string, parameters string) Tool {
+	return Tool{Type: "function", Function: ToolFunction{Name: name, Description: description, Parameters: json.RawMessage(parameters)}}
+}
+
+// ToolCall is the model's request to call a tool. Its result is sent back in a "tool" message with the call's ID.
+type ToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"` // Always "function"
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction names the function to call and its arguments
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON object, as generated by the model
+}
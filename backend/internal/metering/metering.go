@@ -0,0 +1,263 @@
+// Package metering records the tokens and cost of every LLM request and enforces a monthly budget.
+package metering
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/tokenbudget"
+)
+
+// Features LLM requests are tagged with
+const (
+	FeatureChat       = "chat"
+	FeaturePlan       = "plan"
+	FeatureTopic      = "topic"
+	FeatureDevotional = "devotional"
+	FeatureStudy      = "study"
+	FeatureModeration = "moderation"
+	FeatureOther      = "other" // Requests nobody tagged
+)
+
+// IsFeature reports whether a name is one of the features LLM requests are tagged with
+func IsFeature(name string) bool {
+	switch name {
+	case FeatureChat, FeaturePlan, FeatureTopic, FeatureDevotional, FeatureStudy, FeatureModeration, FeatureOther:
+		return true
+	}
+	return false
+}
+
+// spendRefreshInterval is how often the month's spend is reloaded, picking up other replicas' requests
+const spendRefreshInterval = time.Minute
+
+// ErrBudgetExceeded is returned instead of calling the LLM once the monthly budget is spent
+// and there is no cheaper route to fall back to
+var ErrBudgetExceeded = errors.New("monthly LLM budget exceeded")
+
+type tagsKey struct{}
+
+type tags struct {
+	userID  string
+	feature string
+}
+
+// WithUser tags the LLM requests made with the context as made for a user
+func WithUser(ctx context.Context, userID string) context.Context {
+	t := tagsFrom(ctx)
+	t.userID = userID
+	return context.WithValue(ctx, tagsKey{}, t)
+}
+
+// WithFeature tags the LLM requests made with the context as made for a feature
+func WithFeature(ctx context.Context, feature string) context.Context {
+	t := tagsFrom(ctx)
+	t.feature = feature
+	return context.WithValue(ctx, tagsKey{}, t)
+}
+
+func tagsFrom(ctx context.Context) tags {
+	t, _ := ctx.Value(tagsKey{}).(tags)
+	return t
+}
+
+// Price is what a model costs in US dollars per million tokens
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// PriceTable prices models by name prefix; the longest matching prefix wins
+type PriceTable map[string]Price
+
+// Cost prices a request. Models missing from the table cost nothing.
+func (t PriceTable) Cost(model string, promptTokens int, completionTokens int) float64 {
+	price, _ := t.lookup(model)
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1e6
+}
+
+// Has reports whether the table prices a model
+func (t PriceTable) Has(model string) bool {
+	_, ok := t.lookup(model)
+	return ok
+}
+
+func (t PriceTable) lookup(model string) (Price, bool) {
+	var price Price
+	bestLength := -1
+	for prefix, candidate := range t {
+		if strings.HasPrefix(model, prefix) && len(prefix) > bestLength {
+			price, bestLength = candidate, len(prefix)
+		}
+	}
+	return price, bestLength >= 0
+}
+
+// Budget caps what LLM requests may cost per calendar month (UTC)
+type Budget struct {
+	MonthlyLimit float64       // US dollars; 0 means no budget
+	Fallback     llm.LLMClient // Takes requests once the budget is spent, e.g. a route of cheaper models; nil refuses them instead
+}
+
+// Store keeps usage records
+type Store interface {
+	Record(ctx context.Context, usage *domain.LLMUsage) error
+	// CostSince sums the cost of the requests made since a time
+	CostSince(ctx context.Context, since time.Time) (float64, error)
+}
+
+// Client wraps an LLMClient, recording the usage of each request and applying the budget
+type Client struct {
+	inner  llm.LLMClient
+	store  Store
+	prices PriceTable
+	budget Budget
+
+	mu        sync.Mutex
+	month     time.Time // Start of the month spent was counted for
+	spent     float64
+	refreshed time.Time
+	unpriced  map[string]bool // Models already warned about costing nothing
+	now       func() time.Time
+}
+
+// Ensure Client implements LLMClient
+var _ llm.LLMClient = (*Client)(nil)
+
+// NewClient creates a metering client around another LLM client
+func NewClient(inner llm.LLMClient, store Store, prices PriceTable, budget Budget) *Client {
+	return &Client{inner: inner, store: store, prices: prices, budget: budget, unpriced: make(map[string]bool), now: time.Now}
+}
+
+// CreateChatCompletion applies the budget, makes the request and records its usage. A failed
+// request is recorded when the provider reported usage for it.
+func (c *Client) CreateChatCompletion(ctx context.Context, req llm.ChatCompletionRequest) (llm.ChatCompletionResponse, error) {
+	client, err := c.applyBudget(ctx)
+	if err != nil {
+		return llm.ChatCompletionResponse{}, err
+	}
+	response, err := client.CreateChatCompletion(ctx, req)
+	if err == nil || response.Usage != nil {
+		c.record(ctx, req, response)
+	}
+	return response, err
+}
+
+// CreateChatCompletionStream applies the budget, streams the request and records its usage once it
+// ends. A stream that fails part way is recorded with the content it sent, which is still billed.
+func (c *Client) CreateChatCompletionStream(ctx context.Context, req llm.ChatCompletionRequest, onDelta func(delta string) error) (llm.ChatCompletionResponse, error) {
+	client, err := c.applyBudget(ctx)
+	if err != nil {
+		return llm.ChatCompletionResponse{}, err
+	}
+	var streamed strings.Builder
+	response, err := client.CreateChatCompletionStream(ctx, req, func(delta string) error {
+		streamed.WriteString(delta)
+		if onDelta == nil {
+			return nil
+		}
+		return onDelta(delta)
+	})
+	if err == nil {
+		c.record(ctx, req, response)
+	} else if streamed.Len() > 0 || response.Usage != nil {
+		partial := response
+		if len(partial.Choices) == 0 {
+			partial.Choices = []llm.ChatChoice{{Message: llm.Message{Role: "assistant", Content: streamed.String()}}}
+		}
+		c.record(ctx, req, partial)
+	}
+	return response, err
+}
+
+// applyBudget picks the client for a request: the fallback once the month's budget is spent.
+// Without a fallback the request is refused. The fallback's routes choose their own models.
+func (c *Client) applyBudget(ctx context.Context) (llm.LLMClient, error) {
+	if c.budget.MonthlyLimit <= 0 || c.monthSpend(ctx) < c.budget.MonthlyLimit {
+		return c.inner, nil
+	}
+	if c.budget.Fallback == nil {
+		log.Printf("WARN: Refusing %s request: the monthly LLM budget of $%.2f is spent", FeatureOf(ctx), c.budget.MonthlyLimit)
+		return nil, ErrBudgetExceeded
+	}
+	log.Printf("INFO: Monthly LLM budget spent, sending %s request along the budget route", FeatureOf(ctx))
+	return c.budget.Fallback, nil
+}
+
+// monthSpend returns what this month's requests have cost, reloading it from the store
+// when the month changes or the last load is stale. A failed load keeps the last known spend.
+func (c *Client) monthSpend(ctx context.Context) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !month.Equal(c.month) {
+		c.month, c.spent, c.refreshed = month, 0, time.Time{}
+	}
+	if now.Sub(c.refreshed) >= spendRefreshInterval {
+		spent, err := c.store.CostSince(ctx, month)
+		if err != nil {
+			log.Printf("WARN: Failed to load this month's LLM spend: %v", err)
+		} else {
+			c.spent = spent
+		}
+		c.refreshed = now
+	}
+	return c.spent
+}
+
+// record stores the usage of a finished request. Token counts are estimated when the provider
+// sends none, as some do for streams. Failing to record never fails the request.
+func (c *Client) record(ctx context.Context, req llm.ChatCompletionRequest, response llm.ChatCompletionResponse) {
+	t := tagsFrom(ctx)
+	usage := &domain.LLMUsage{
+		UserID:  t.userID,
+		Feature: FeatureOf(ctx),
+		Model:   response.Model,
+	}
+	if usage.Model == "" {
+		usage.Model = req.Model
+	}
+	if response.Usage != nil {
+		usage.PromptTokens = response.Usage.PromptTokens
+		usage.CompletionTokens = response.Usage.CompletionTokens
+	} else {
+		usage.Estimated = true
+		usage.PromptTokens = tokenbudget.EstimateMessages(req.Messages)
+		if len(response.Choices) > 0 {
+			usage.CompletionTokens = tokenbudget.EstimateTokens(response.Choices[0].Message.Content)
+		}
+	}
+	usage.Cost = c.prices.Cost(usage.Model, usage.PromptTokens, usage.CompletionTokens)
+
+	c.mu.Lock()
+	c.spent += usage.Cost
+	warn := !c.prices.Has(usage.Model) && !c.unpriced[usage.Model]
+	if warn {
+		c.unpriced[usage.Model] = true
+	}
+	c.mu.Unlock()
+	if warn {
+		log.Printf("WARN: LLM model %s has no price, so its requests cost nothing against the budget", usage.Model)
+	}
+
+	// The request may be cancelled as soon as it returns, but its usage still counts
+	if err := c.store.Record(context.WithoutCancel(ctx), usage); err != nil {
+		log.Printf("WARN: Failed to record LLM usage of %s: %v", usage.Feature, err)
+	}
+}
+
+// FeatureOf returns the feature the context's LLM requests are made for, FeatureOther if untagged
+func FeatureOf(ctx context.Context) string {
+	if feature := tagsFrom(ctx).feature; feature != "" {
+		return feature
+	}
+	return FeatureOther
+}
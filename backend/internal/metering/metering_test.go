@@ -0,0 +1,219 @@
+package metering
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLLM struct {
+	models    []string
+	usage     *llm.Usage
+	streamErr error // Returned after streaming part of the answer
+}
+
+func (f *fakeLLM) CreateChatCompletion(ctx context.Context, req llm.ChatCompletionRequest) (llm.ChatCompletionResponse, error) {
+	f.models = append(f.models, req.Model)
+	return llm.ChatCompletionResponse{
+		Choices: []llm.ChatChoice{{Message: llm.Message{Role: "assistant", Content: "An answer"}}},
+		Usage:   f.usage,
+	}, nil
+}
+
+func (f *fakeLLM) CreateChatCompletionStream(ctx context.Context, req llm.ChatCompletionRequest, onDelta func(delta string) error) (llm.ChatCompletionResponse, error) {
+	if f.streamErr != nil {
+		f.models = append(f.models, req.Model)
+		if err := onDelta("In the beginning God created"); err != nil {
+			return llm.ChatCompletionResponse{}, err
+		}
+		return llm.ChatCompletionResponse{}, f.streamErr
+	}
+	return f.CreateChatCompletion(ctx, req)
+}
+
+type fakeStore struct {
+	records []*domain.LLMUsage
+	spent   float64
+}
+
+func (s *fakeStore) Record(ctx context.Context, usage *domain.LLMUsage) error {
+	s.records = append(s.records, usage)
+	return nil
+}
+
+func (s *fakeStore) CostSince(ctx context.Context, since time.Time) (float64, error) {
+	return s.spent, nil
+}
+
+func TestPriceTableCost(t *testing.T) {
+	prices := PriceTable{
+		"openai/gpt-4o":      {Prompt: 2.5, Completion: 10},
+		"openai/gpt-4o-mini": {Prompt: 0.15, Completion: 0.6},
+	}
+	assert.InDelta(t, 0.0035, prices.Cost("openai/gpt-4o-2024-08-06", 1000, 100), 1e-9)
+	assert.InDelta(t, 0.00021, prices.Cost("openai/gpt-4o-mini", 1000, 100), 1e-9)
+	assert.Equal(t, 0.0, prices.Cost("unknown/model", 1000, 100))
+	assert.True(t, prices.Has("openai/gpt-4o-mini"))
+	assert.False(t, prices.Has("unknown/model"))
+}
+
+func TestClientRecordsTaggedUsage(t *testing.T) {
+	inner := &fakeLLM{usage: &llm.Usage{PromptTokens: 1000, CompletionTokens: 500}}
+	store := &fakeStore{}
+	client := NewClient(inner, store, PriceTable{"cheap": {Prompt: 1, Completion: 2}}, Budget{})
+
+	ctx := WithFeature(WithUser(context.Background(), "user-1"), FeatureChat)
+	_, err := client.CreateChatCompletion(ctx, llm.ChatCompletionRequest{Model: "cheap/model"})
+	assert.NoError(t, err)
+
+	assert.Len(t, store.records, 1)
+	record := store.records[0]
+	assert.Equal(t, "user-1", record.UserID)
+	assert.Equal(t, FeatureChat, record.Feature)
+	assert.Equal(t, "cheap/model", record.Model)
+	assert.Equal(t, 1000, record.PromptTokens)
+	assert.False(t, record.Estimated)
+	assert.InDelta(t, 0.002, record.Cost, 1e-9)
+}
+
+func TestClientEstimatesMissingUsage(t *testing.T) {
+	store := &fakeStore{}
+	client := NewClient(&fakeLLM{}, store, nil, Budget{})
+
+	_, err := client.CreateChatCompletionStream(context.Background(), llm.ChatCompletionRequest{
+		Model:    "some/model",
+		Messages: []llm.Message{{Role: "user", Content: "Who was Moses?"}},
+	}, nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, store.records, 1)
+	assert.True(t, store.records[0].Estimated)
+	assert.Equal(t, FeatureOther, store.records[0].Feature)
+	assert.Greater(t, store.records[0].PromptTokens, 0)
+	assert.Greater(t, store.records[0].CompletionTokens, 0)
+}
+
+func TestClientRecordsFailedStream(t *testing.T) {
+	store := &fakeStore{}
+	client := NewClient(&fakeLLM{streamErr: errors.New("connection reset")}, store, nil, Budget{})
+
+	var deltas []string
+	_, err := client.CreateChatCompletionStream(context.Background(), llm.ChatCompletionRequest{
+		Model:    "some/model",
+		Messages: []llm.Message{{Role: "user", Content: "Who was Moses?"}},
+	}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"In the beginning God created"}, deltas)
+	assert.Len(t, store.records, 1)
+	assert.True(t, store.records[0].Estimated)
+	assert.Greater(t, store.records[0].CompletionTokens, 0)
+}
+
+func TestClientBudget(t *testing.T) {
+	request := llm.ChatCompletionRequest{Model: "expensive/model"}
+
+	t.Run("Under budget uses the requested model", func(t *testing.T) {
+		inner, fallback := &fakeLLM{}, &fakeLLM{}
+		client := NewClient(inner, &fakeStore{spent: 5}, nil, Budget{MonthlyLimit: 10, Fallback: fallback})
+		_, err := client.CreateChatCompletion(context.Background(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"expensive/model"}, inner.models)
+		assert.Empty(t, fallback.models)
+	})
+
+	t.Run("Spent budget falls back to the fallback client", func(t *testing.T) {
+		inner, fallback := &fakeLLM{}, &fakeLLM{}
+		client := NewClient(inner, &fakeStore{spent: 10}, nil, Budget{MonthlyLimit: 10, Fallback: fallback})
+		_, err := client.CreateChatCompletion(context.Background(), request)
+		assert.NoError(t, err)
+		assert.Empty(t, inner.models)
+		assert.Equal(t, []string{"expensive/model"}, fallback.models)
+	})
+
+	t.Run("Spent budget without a fallback refuses", func(t *testing.T) {
+		inner := &fakeLLM{}
+		client := NewClient(inner, &fakeStore{spent: 12}, nil, Budget{MonthlyLimit: 10})
+		_, err := client.CreateChatCompletion(context.Background(), request)
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.Empty(t, inner.models)
+	})
+
+	t.Run("Spend of this process counts before the next reload", func(t *testing.T) {
+		inner := &fakeLLM{usage: &llm.Usage{PromptTokens: 1_000_000}}
+		client := NewClient(inner, &fakeStore{}, PriceTable{"expensive": {Prompt: 20}}, Budget{MonthlyLimit: 10})
+		_, err := client.CreateChatCompletion(context.Background(), request)
+		assert.NoError(t, err)
+		_, err = client.CreateChatCompletion(context.Background(), request)
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+	})
+}
+
+// newModelServer answers OpenAI-style chat requests, recording the models asked for.
+// A failing server answers every request with an error.
+func newModelServer(t *testing.T, failing bool, models *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		*models = append(*models, req.Model)
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(llm.ChatCompletionResponse{
+			Model:   req.Model,
+			Choices: []llm.ChatChoice{{Message: llm.Message{Role: "assistant", Content: "Amen"}}},
+			Usage:   &llm.Usage{PromptTokens: 1_000_000},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientBudgetWithRoutes(t *testing.T) {
+	var remoteModels, localModels []string
+	remote := newModelServer(t, true, &remoteModels)
+	local := newModelServer(t, false, &localModels)
+	registry, err := llm.NewRegistry(map[string]llm.ProviderConfig{
+		"remote": {Type: llm.ProviderOpenAI, BaseURL: remote.URL},
+		"local":  {Type: llm.ProviderOpenAI, BaseURL: local.URL},
+	})
+	require.NoError(t, err)
+	route, err := registry.Route([]llm.RouteStep{{Provider: "remote", Model: "gpt-4o"}, {Provider: "local", Model: "llama3.1"}})
+	require.NoError(t, err)
+	budgetRoute, err := registry.Route([]llm.RouteStep{{Provider: "local", Model: "llama3.2:1b"}})
+	require.NoError(t, err)
+
+	store := &fakeStore{}
+	prices := PriceTable{"llama3.1": {Prompt: 10}, "llama3.2": {Prompt: 1}}
+	client := NewClient(route, store, prices, Budget{MonthlyLimit: 10, Fallback: budgetRoute})
+	request := llm.ChatCompletionRequest{Model: "openai/gpt-4o"}
+
+	// The route falls back to its local model, whose cost spends the budget
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	// The budget route then uses its own model rather than one the route's providers may not have
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"gpt-4o"}, remoteModels)
+	assert.Equal(t, []string{"llama3.1", "llama3.2:1b"}, localModels)
+	require.Len(t, store.records, 2)
+	assert.Equal(t, "llama3.1", store.records[0].Model)
+	assert.InDelta(t, 10, store.records[0].Cost, 1e-9)
+	assert.Equal(t, "llama3.2:1b", store.records[1].Model)
+	assert.InDelta(t, 1, store.records[1].Cost, 1e-9)
+}
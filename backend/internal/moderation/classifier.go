@@ -0,0 +1,85 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/metering"
+)
+
+// classifierPrompt asks the model for a verdict on one message
+const classifierPrompt = `You screen messages in a Bible study app used by children and teenagers.
+Classify the %s below.
+
+Use "block" for sexual content, instructions for weapons or violence, hate speech and anything else no young reader should see.
+Use "soften" for self-harm, threats, bullying, personal contact details, crude language and mature themes that need a careful answer.
+Use "allow" for everything else. Bible subjects such as war, death, sin, judgment and marriage are fine when discussed respectfully.
+
+Output ONLY JSON: {"action": "allow", "categories": [], "reason": ""}`
+
+// LLMClassifier asks an LLM to classify content the rule lists can't judge
+type LLMClassifier struct {
+	client llm.LLMClient
+	model  string
+}
+
+// NewLLMClassifier creates a classifier that uses the given model
+func NewLLMClassifier(client llm.LLMClient, model string) *LLMClassifier {
+	return &LLMClassifier{client: client, model: model}
+}
+
+// Check asks the model for a verdict. Answers the classifier would only soften are flagged
+// for review but not masked, since there are no matched terms to mask.
+func (c *LLMClassifier) Check(ctx context.Context, stage Stage, text string) (Verdict, error) {
+	subject := "question from a user"
+	if stage == StageAnswer {
+		subject = "answer from the study assistant"
+	}
+
+	request := llm.ChatCompletionRequest{
+		Model: c.model,
+		Messages: []llm.Message{
+			{Role: "system", Content: fmt.Sprintf(classifierPrompt, subject)},
+			{Role: "user", Content: text},
+		},
+		MaxTokens:   100,
+		Temperature: 0,
+	}
+	response, err := c.client.CreateChatCompletion(metering.WithFeature(ctx, metering.FeatureModeration), request)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("LLM completion failed during moderation: %w", err)
+	}
+	if len(response.Choices) == 0 || response.Choices[0].Message.Content == "" {
+		return Verdict{}, errors.New("LLM returned an empty moderation verdict")
+	}
+
+	rawJson := strings.TrimSpace(response.Choices[0].Message.Content)
+	if strings.HasPrefix(rawJson, "```json") {
+		rawJson = strings.TrimPrefix(rawJson, "```json")
+		rawJson = strings.TrimSuffix(rawJson, "```")
+		rawJson = strings.TrimSpace(rawJson)
+	}
+
+	var parsed struct {
+		Action     Action   `json:"action"`
+		Categories []string `json:"categories"`
+		Reason     string   `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(rawJson), &parsed); err != nil {
+		return Verdict{}, fmt.Errorf("failed to parse moderation JSON: %w", err)
+	}
+	if _, ok := severity[parsed.Action]; !ok {
+		return Verdict{}, fmt.Errorf("unknown moderation action '%s'", parsed.Action)
+	}
+
+	verdict := Verdict{Action: parsed.Action}
+	if verdict.Flagged() {
+		verdict.Categories = parsed.Categories
+		verdict.Reasons = []string{"classifier: " + parsed.Reason}
+	}
+	return verdict, nil
+}
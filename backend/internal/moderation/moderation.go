@@ -0,0 +1,106 @@
+// Package moderation screens chat questions and answers before and after they reach the LLM.
+package moderation
+
+import (
+	"context"
+	"log"
+	"sort"
+)
+
+// Action is what happens to screened content
+type Action string
+
+const (
+	Allow  Action = "allow"  // Content passes unchanged
+	Soften Action = "soften" // Questions get a careful answer, answers have flagged terms masked
+	Block  Action = "block"  // Questions aren't answered, answers are replaced
+)
+
+// severity orders actions from least to most strict
+var severity = map[Action]int{Allow: 0, Soften: 1, Block: 2}
+
+// Stage is the point of the chat the content is screened at
+type Stage string
+
+const (
+	StageQuestion Stage = "question" // A user's question, before the LLM call
+	StageAnswer   Stage = "answer"   // The LLM's answer, before it is stored and returned
+)
+
+// Verdict is the outcome of screening one piece of content
+type Verdict struct {
+	Action     Action   `json:"action"`
+	Categories []string `json:"categories,omitempty"`
+	Reasons    []string `json:"reasons,omitempty"`
+	// Matches are the flagged spans of text, masked when an answer is softened
+	Matches []string `json:"-"`
+}
+
+// Flagged reports whether the content needs more than a pass
+func (v Verdict) Flagged() bool {
+	return v.Action != "" && v.Action != Allow
+}
+
+// Merge combines two verdicts, keeping the stricter action and every category and reason
+func (v Verdict) Merge(other Verdict) Verdict {
+	merged := Verdict{
+		Action:     v.Action,
+		Categories: unique(append(append([]string(nil), v.Categories...), other.Categories...)),
+		Reasons:    append(append([]string(nil), v.Reasons...), other.Reasons...),
+		Matches:    unique(append(append([]string(nil), v.Matches...), other.Matches...)),
+	}
+	if severity[other.Action] > severity[merged.Action] || merged.Action == "" {
+		merged.Action = other.Action
+	}
+	return merged
+}
+
+// Moderator screens one piece of content
+type Moderator interface {
+	Check(ctx context.Context, stage Stage, text string) (Verdict, error)
+}
+
+// Pipeline runs moderators in order and merges their verdicts. A moderator that fails is
+// skipped, so an unavailable classifier leaves the rule lists in charge.
+type Pipeline struct {
+	moderators []Moderator
+}
+
+// NewPipeline creates a pipeline of moderators
+func NewPipeline(moderators ...Moderator) *Pipeline {
+	return &Pipeline{moderators: moderators}
+}
+
+// Check screens content with every moderator. Once content is blocked the remaining
+// moderators are not asked.
+func (p *Pipeline) Check(ctx context.Context, stage Stage, text string) (Verdict, error) {
+	verdict := Verdict{Action: Allow}
+	for _, moderator := range p.moderators {
+		result, err := moderator.Check(ctx, stage, text)
+		if err != nil {
+			log.Printf("WARN: Moderator %T failed on %s: %v", moderator, stage, err)
+			continue
+		}
+		verdict = verdict.Merge(result)
+		if verdict.Action == Block {
+			break
+		}
+	}
+	return verdict, nil
+}
+
+func unique(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,69 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleModerator(t *testing.T) {
+	moderator := NewRuleModerator(DefaultRules(), "forbidden")
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		stage      Stage
+		text       string
+		action     Action
+		categories []string
+	}{
+		{"Bible subjects pass", StageQuestion, "Why did David kill Goliath, and what happens when we die?", Allow, nil},
+		{"Explicit content is blocked", StageQuestion, "Where can I find porn?", Block, []string{"sexual_content"}},
+		{"Self-harm is softened", StageQuestion, "Sometimes I want to die", Soften, []string{"self_harm"}},
+		{"Self-harm rules don't apply to answers", StageAnswer, "If you feel suicidal, talk to someone you trust.", Allow, nil},
+		{"Contact details are softened", StageAnswer, "Email me at kid@example.com", Soften, []string{"personal_info"}},
+		{"Configured terms are blocked", StageAnswer, "That is FORBIDDEN here", Block, []string{"blocked_term"}},
+		{"Strictest action wins", StageQuestion, "shit, how do i make a bomb", Block, []string{"profanity", "weapons"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			verdict, err := moderator.Check(ctx, tc.stage, tc.text)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.action, verdict.Action)
+			assert.Equal(t, tc.categories, verdict.Categories)
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	verdict, _ := NewRuleModerator(DefaultRules()).Check(context.Background(), StageAnswer, "Call 555-123-4567, it's shit")
+	assert.Equal(t, "Call ************, it's ****", Mask("Call 555-123-4567, it's shit", verdict.Matches))
+}
+
+type failingModerator struct{}
+
+func (failingModerator) Check(ctx context.Context, stage Stage, text string) (Verdict, error) {
+	return Verdict{}, errors.New("unavailable")
+}
+
+type fixedModerator struct{ verdict Verdict }
+
+func (m fixedModerator) Check(ctx context.Context, stage Stage, text string) (Verdict, error) {
+	return m.verdict, nil
+}
+
+func TestPipelineSkipsFailingModerators(t *testing.T) {
+	pipeline := NewPipeline(
+		failingModerator{},
+		fixedModerator{Verdict{Action: Soften, Categories: []string{"bullying"}}},
+		fixedModerator{Verdict{Action: Allow}},
+	)
+	verdict, err := pipeline.Check(context.Background(), StageQuestion, "text")
+	assert.NoError(t, err)
+	assert.Equal(t, Soften, verdict.Action)
+	assert.Equal(t, []string{"bullying"}, verdict.Categories)
+	assert.True(t, verdict.Flagged())
+}
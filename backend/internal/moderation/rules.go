@@ -0,0 +1,142 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rule flags content matching any of its patterns, with a separate action for questions and answers.
+// An Allow action means the rule doesn't apply at that stage.
+type Rule struct {
+	Category       string
+	QuestionAction Action
+	AnswerAction   Action
+	Patterns       []*regexp.Regexp
+}
+
+// NewKeywordRule builds a rule from whole-word, case-insensitive terms
+func NewKeywordRule(category string, questionAction Action, answerAction Action, terms ...string) Rule {
+	rule := Rule{Category: category, QuestionAction: questionAction, AnswerAction: answerAction}
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		rule.Patterns = append(rule.Patterns, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(term)+`\b`))
+	}
+	return rule
+}
+
+// NewPatternRule builds a rule from regular expressions, matched case-insensitively
+func NewPatternRule(category string, questionAction Action, answerAction Action, patterns ...string) Rule {
+	rule := Rule{Category: category, QuestionAction: questionAction, AnswerAction: answerAction}
+	for _, pattern := range patterns {
+		rule.Patterns = append(rule.Patterns, regexp.MustCompile(`(?i)`+pattern))
+	}
+	return rule
+}
+
+func (r Rule) action(stage Stage) Action {
+	if stage == StageAnswer {
+		return r.AnswerAction
+	}
+	return r.QuestionAction
+}
+
+// DefaultRules are tuned for readers as young as eight. Bible subjects such as war, death,
+// sin and marriage are deliberately not flagged; the lists target content no answer needs.
+func DefaultRules() []Rule {
+	return []Rule{
+		NewKeywordRule("sexual_content", Block, Block,
+			"porn", "porno", "pornography", "nudes", "sexting", "hentai", "onlyfans", "blowjob"),
+		NewPatternRule("weapons", Block, Block,
+			`\bhow (?:do i|to|can i) (?:make|build|get) (?:a |an )?(?:bomb|explosive|gun|weapon)s?\b`),
+		NewPatternRule("self_harm", Soften, Allow,
+			`\b(?:kill|hurt|cut) myself\b`, `\bend (?:my|it) (?:life|all)\b`, `\bwant(?:ed)? to die\b`,
+			`\bsuicid(?:e|al)\b`, `\bself[- ]harm\b`),
+		NewPatternRule("violence", Soften, Allow,
+			`\bi (?:want|am going|'m going|plan) to (?:kill|hurt|shoot|stab|beat up)\b`),
+		NewKeywordRule("profanity", Soften, Soften,
+			"fuck", "fucking", "motherfucker", "shit", "bitch", "asshole", "cunt"),
+		NewPatternRule("personal_info", Soften, Soften,
+			`\b[A-Z0-9._%+-]+@[A-Z0-9.-]+\.[A-Z]{2,}\b`,
+			`(?:\+?\d{1,2}[\s.-]?)?\(?\d{3}\)?[\s.-]?\d{3}[\s.-]?\d{4}\b`),
+	}
+}
+
+// RuleModerator flags content that matches keyword and pattern rules
+type RuleModerator struct {
+	rules []Rule
+}
+
+// NewRuleModerator creates a rule moderator. Extra blocked terms, e.g. from configuration,
+// are blocked in both questions and answers.
+func NewRuleModerator(rules []Rule, blockedTerms ...string) *RuleModerator {
+	if len(blockedTerms) > 0 {
+		rules = append(rules, NewKeywordRule("blocked_term", Block, Block, blockedTerms...))
+	}
+	return &RuleModerator{rules: rules}
+}
+
+// Check flags the content with every rule that matches it at this stage
+func (m *RuleModerator) Check(ctx context.Context, stage Stage, text string) (Verdict, error) {
+	verdict := Verdict{Action: Allow}
+	for _, rule := range m.rules {
+		action := rule.action(stage)
+		if action == "" || action == Allow {
+			continue
+		}
+		var matches []string
+		for _, pattern := range rule.Patterns {
+			matches = append(matches, pattern.FindAllString(text, -1)...)
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		verdict = verdict.Merge(Verdict{
+			Action:     action,
+			Categories: []string{rule.Category},
+			Reasons:    []string{fmt.Sprintf("rule %s matched %d time(s)", rule.Category, len(matches))},
+			Matches:    matches,
+		})
+	}
+	return verdict, nil
+}
+
+// Mask replaces every occurrence of the matched spans with asterisks
+func Mask(text string, matches []string) string {
+	for _, match := range matches {
+		if match == "" {
+			continue
+		}
+		text = strings.ReplaceAll(text, match, strings.Repeat("*", len([]rune(match))))
+	}
+	return text
+}
+
+// softeningInstructions tell the LLM how to answer a softened question, per category
+var softeningInstructions = map[string]string{
+	"self_harm": "The user may be thinking about hurting themselves. Respond with warmth and without judgment, " +
+		"remind them that they are loved, encourage them to talk to a parent, pastor or another trusted adult right away, " +
+		"and mention that they can call or text a crisis line such as 988 in the US.",
+	"violence": "The user may be thinking about hurting someone. Respond calmly, don't help with any harmful plan, " +
+		"point to what the Bible teaches about anger and forgiveness, and encourage them to talk to a trusted adult.",
+	"profanity":     "Don't repeat any rude language from the question.",
+	"personal_info": "Don't repeat any contact details from the question, and gently remind the user not to share personal information online.",
+}
+
+// SofteningInstructions returns the prompt instructions for answering a softened question
+func SofteningInstructions(categories []string) string {
+	var instructions []string
+	for _, category := range categories {
+		if instruction, ok := softeningInstructions[category]; ok {
+			instructions = append(instructions, instruction)
+		}
+	}
+	if len(instructions) == 0 {
+		return "Take particular care that the answer is appropriate for a young reader."
+	}
+	return strings.Join(instructions, " ")
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrChatLimitReached is returned when reserving a chat request would go over the day's limit
+var ErrChatLimitReached = errors.New("chat limit reached")
+
+// ChatUsageRepository counts chat requests per user and day. The day is a YYYY-MM-DD date in
+// the user's time zone, chosen by the caller.
+type ChatUsageRepository interface {
+	// Reserve counts one more request for the day and returns the new count, or ErrChatLimitReached
+	// if the count is already at the limit. The check and the increment are one atomic step.
+	// A limit of 0 means unlimited. Counts may be dropped once expiresAt has passed.
+	Reserve(ctx context.Context, userID string, day string, limit int, expiresAt time.Time) (int, error)
+
+	// Release gives back a reserved request that wasn't answered
+	Release(ctx context.Context, userID string, day string) error
+
+	// GetUsage gets the number of requests counted for the day
+	GetUsage(ctx context.Context, userID string, day string) (int, error)
+}
+
+// chatUsageDocument is a user's request count for one day
+type chatUsageDocument struct {
+	ID        string    `bson:"_id"` // "<user ID>/<day>"
+	UserID    string    `bson:"user_id"`
+	Day       string    `bson:"day"`
+	Count     int       `bson:"count"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func chatUsageID(userID string, day string) string {
+	return userID + "/" + day
+}
+
+// MongoChatUsageRepository implements ChatUsageRepository using MongoDB, so counts survive
+// restarts and are shared by every replica
+type MongoChatUsageRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoChatUsageRepository creates a new instance of MongoChatUsageRepository.
+func NewMongoChatUsageRepository(db *mongo.Database) *MongoChatUsageRepository {
+	collection := db.Collection("chat_usage")
+
+	// Mongo removes a day's count once it has expired
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
+	if err != nil {
+		log.Printf("WARN: Could not create TTL index on chat_usage collection: %v", err)
+	}
+
+	return &MongoChatUsageRepository{collection: collection}
+}
+
+// Reserve increments the day's count only if it is below the limit. When the count is at the
+// limit the filter doesn't match, and the upsert's insert collides with the existing document.
+// The first requests of a day can also collide with each other's inserts, so a collision is
+// tried once more before it counts as the limit.
+func (r *MongoChatUsageRepository) Reserve(ctx context.Context, userID string, day string, limit int, expiresAt time.Time) (int, error) {
+	count, err := r.reserve(ctx, userID, day, limit, expiresAt)
+	if mongo.IsDuplicateKeyError(err) {
+		count, err = r.reserve(ctx, userID, day, limit, expiresAt)
+	}
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return limit, ErrChatLimitReached
+		}
+		log.Printf("ERROR: Failed to reserve chat request for user %s on %s: %v", userID, day, err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *MongoChatUsageRepository) reserve(ctx context.Context, userID string, day string, limit int, expiresAt time.Time) (int, error) {
+	filter := bson.M{"_id": chatUsageID(userID, day)}
+	if limit > 0 {
+		filter["count"] = bson.M{"$lt": limit}
+	}
+	update := bson.M{
+		"$inc":         bson.M{"count": 1},
+		"$set":         bson.M{"expires_at": expiresAt},
+		"$setOnInsert": bson.M{"user_id": userID, "day": day},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var usage chatUsageDocument
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&usage); err != nil {
+		return 0, err
+	}
+	return usage.Count, nil
+}
+
+// Release decrements the day's count, never below zero
+func (r *MongoChatUsageRepository) Release(ctx context.Context, userID string, day string) error {
+	filter := bson.M{"_id": chatUsageID(userID, day), "count": bson.M{"$gt": 0}}
+	if _, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"count": -1}}); err != nil {
+		log.Printf("ERROR: Failed to release chat request for user %s on %s: %v", userID, day, err)
+		return err
+	}
+	return nil
+}
+
+// GetUsage gets the number of requests counted for the day
+func (r *MongoChatUsageRepository) GetUsage(ctx context.Context, userID string, day string) (int, error) {
+	var usage chatUsageDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": chatUsageID(userID, day)}).Decode(&usage)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		log.Printf("ERROR: Failed to get chat usage for user %s on %s: %v", userID, day, err)
+		return 0, err
+	}
+	return usage.Count, nil
+}
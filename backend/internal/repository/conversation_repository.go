@@ -0,0 +1,392 @@
+package repository
+
+import (
+	"bibleapp/backend/internal/domain"
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrConversationNotFound is returned when updating a conversation that doesn't exist
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// ConversationFilter narrows a user's conversation list; zero fields match everything
+type ConversationFilter struct {
+	PlanID    string
+	DayNumber int
+}
+
+// ConversationRepository stores chat conversations
+type ConversationRepository interface {
+	Create(ctx context.Context, conversation *domain.Conversation) error
+	// FindByID returns a conversation with its message tree, or nil if it doesn't exist
+	FindByID(ctx context.Context, id string) (*domain.Conversation, error)
+	// ListByUser returns a user's conversations without their messages, most recently updated first
+	ListByUser(ctx context.Context, userID string, filter ConversationFilter) ([]*domain.Conversation, error)
+	// AppendMessages adds messages to a conversation's tree and shows the branch ending at leafID
+	AppendMessages(ctx context.Context, id string, leafID string, messages ...domain.ChatMessage) error
+	// SetLeaf shows the branch ending at leafID
+	SetLeaf(ctx context.Context, id string, leafID string) error
+	// ClearMessages removes every message and the summary of a conversation but keeps the conversation
+	ClearMessages(ctx context.Context, id string) error
+	// SetSummary stores a summary of the branch up to and including the message through. It only
+	// applies if the stored summary is still previousSummary, and reports whether it did.
+	SetSummary(ctx context.Context, id string, summary string, through string, previousSummary string) (bool, error)
+	Rename(ctx context.Context, id string, title string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// MongoConversationRepository implements ConversationRepository using MongoDB.
+type MongoConversationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoConversationRepository creates a new instance of MongoConversationRepository.
+func NewMongoConversationRepository(db *mongo.Database) *MongoConversationRepository {
+	collection := db.Collection("conversations")
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "updated_at", Value: -1}},
+	}
+	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
+	if err != nil {
+		log.Printf("WARN: Could not create 'user_id/updated_at' index on conversations collection: %v", err)
+	}
+
+	return &MongoConversationRepository{collection: collection}
+}
+
+// Create inserts a new conversation
+func (r *MongoConversationRepository) Create(ctx context.Context, conversation *domain.Conversation) error {
+	prepareNewConversation(conversation)
+	if _, err := r.collection.InsertOne(ctx, conversation); err != nil {
+		log.Printf("ERROR: Failed to insert conversation for user %s: %v", conversation.UserID, err)
+		return err
+	}
+	return nil
+}
+
+// FindByID returns a conversation with its messages, or nil if it doesn't exist
+func (r *MongoConversationRepository) FindByID(ctx context.Context, id string) (*domain.Conversation, error) {
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, nil // Malformed IDs can't name a stored conversation
+	}
+
+	var conversation domain.Conversation
+	err = r.collection.FindOne(ctx, bson.M{"_id": parsedUUID}).Decode(&conversation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.Printf("ERROR: Failed to find conversation %s: %v", id, err)
+		return nil, err
+	}
+	conversation.EnsureTree()
+	return &conversation, nil
+}
+
+// ListByUser returns a user's conversations without their messages, most recently updated first
+func (r *MongoConversationRepository) ListByUser(ctx context.Context, userID string, filter ConversationFilter) ([]*domain.Conversation, error) {
+	query := bson.M{"user_id": userID}
+	if filter.PlanID != "" {
+		query["plan_id"] = filter.PlanID
+	}
+	if filter.DayNumber > 0 {
+		query["day"] = filter.DayNumber
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetProjection(bson.M{"messages": 0})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to list conversations for user %s: %v", userID, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var conversations []*domain.Conversation
+	if err = cursor.All(ctx, &conversations); err != nil {
+		log.Printf("ERROR: Failed to decode conversations for user %s: %v", userID, err)
+		return nil, err
+	}
+
+	if conversations == nil {
+		conversations = []*domain.Conversation{}
+	}
+	return conversations, nil
+}
+
+// AppendMessages adds messages to a conversation's tree and moves to their branch in one atomic update
+func (r *MongoConversationRepository) AppendMessages(ctx context.Context, id string, leafID string, messages ...domain.ChatMessage) error {
+	return r.update(ctx, id, bson.M{
+		"$push": bson.M{"messages": bson.M{"$each": messages}},
+		"$inc":  bson.M{"message_count": len(messages)},
+		"$set":  bson.M{"leaf_id": leafID, "updated_at": time.Now()},
+	})
+}
+
+// SetLeaf shows the branch ending at leafID
+func (r *MongoConversationRepository) SetLeaf(ctx context.Context, id string, leafID string) error {
+	return r.update(ctx, id, bson.M{"$set": bson.M{"leaf_id": leafID, "updated_at": time.Now()}})
+}
+
+// ClearMessages removes every message and the summary of a conversation but keeps the conversation
+func (r *MongoConversationRepository) ClearMessages(ctx context.Context, id string) error {
+	return r.update(ctx, id, bson.M{
+		"$set": bson.M{
+			"messages":      []domain.ChatMessage{},
+			"message_count": 0,
+			"updated_at":    time.Now(),
+		},
+		"$unset": bson.M{"leaf_id": "", "summary": "", "summary_through": "", "summarized_count": ""},
+	})
+}
+
+// SetSummary stores a summary of the branch up to and including the message through, unless
+// another summary was stored since previousSummary was read
+func (r *MongoConversationRepository) SetSummary(ctx context.Context, id string, summary string, through string, previousSummary string) (bool, error) {
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return false, ErrConversationNotFound
+	}
+
+	// summary is omitted while it is empty
+	summaryFilter := bson.M{"summary": previousSummary}
+	if previousSummary == "" {
+		summaryFilter = bson.M{"$or": bson.A{
+			bson.M{"summary": bson.M{"$exists": false}},
+			bson.M{"summary": ""},
+		}}
+	}
+	filter := bson.M{"$and": bson.A{bson.M{"_id": parsedUUID}, summaryFilter}}
+	update := bson.M{
+		"$set":   bson.M{"summary": summary, "summary_through": through},
+		"$unset": bson.M{"summarized_count": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to store summary of conversation %s: %v", id, err)
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// Rename sets a conversation's title
+func (r *MongoConversationRepository) Rename(ctx context.Context, id string, title string) error {
+	return r.update(ctx, id, bson.M{"$set": bson.M{"title": title, "updated_at": time.Now()}})
+}
+
+// Delete removes a conversation
+func (r *MongoConversationRepository) Delete(ctx context.Context, id string) error {
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrConversationNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": parsedUUID})
+	if err != nil {
+		log.Printf("ERROR: Failed to delete conversation %s: %v", id, err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
+func (r *MongoConversationRepository) update(ctx context.Context, id string, update bson.M) error {
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrConversationNotFound
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": parsedUUID}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to update conversation %s: %v", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
+// prepareNewConversation sets the ID and timestamps of a conversation about to be stored
+func prepareNewConversation(conversation *domain.Conversation) {
+	if conversation.ID == uuid.Nil {
+		conversation.ID = uuid.New()
+	}
+	now := time.Now()
+	conversation.CreatedAt = now
+	conversation.UpdatedAt = now
+	if conversation.Messages == nil {
+		conversation.Messages = []domain.ChatMessage{}
+	}
+	conversation.MessageCount = len(conversation.Messages)
+}
+
+// MemoryConversationRepository implements ConversationRepository in memory, for tests
+type MemoryConversationRepository struct {
+	mu            sync.RWMutex
+	conversations map[uuid.UUID]*domain.Conversation
+}
+
+// NewMemoryConversationRepository creates a new in-memory conversation repository
+func NewMemoryConversationRepository() *MemoryConversationRepository {
+	return &MemoryConversationRepository{conversations: make(map[uuid.UUID]*domain.Conversation)}
+}
+
+// Create stores a new conversation
+func (r *MemoryConversationRepository) Create(ctx context.Context, conversation *domain.Conversation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prepareNewConversation(conversation)
+	stored := *conversation
+	stored.Messages = append([]domain.ChatMessage(nil), conversation.Messages...)
+	r.conversations[stored.ID] = &stored
+	return nil
+}
+
+// FindByID returns a copy of a conversation with its messages, or nil if it doesn't exist
+func (r *MemoryConversationRepository) FindByID(ctx context.Context, id string) (*domain.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, nil
+	}
+	stored, ok := r.conversations[parsedUUID]
+	if !ok {
+		return nil, nil
+	}
+	conversation := *stored
+	conversation.Messages = append([]domain.ChatMessage(nil), stored.Messages...)
+	conversation.EnsureTree()
+	return &conversation, nil
+}
+
+// ListByUser returns a user's conversations without their messages, most recently updated first
+func (r *MemoryConversationRepository) ListByUser(ctx context.Context, userID string, filter ConversationFilter) ([]*domain.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conversations := []*domain.Conversation{}
+	for _, stored := range r.conversations {
+		if stored.UserID != userID ||
+			(filter.PlanID != "" && stored.PlanID != filter.PlanID) ||
+			(filter.DayNumber > 0 && stored.DayNumber != filter.DayNumber) {
+			continue
+		}
+		conversation := *stored
+		conversation.Messages = nil
+		conversations = append(conversations, &conversation)
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+	return conversations, nil
+}
+
+// AppendMessages adds messages to a conversation's tree and moves to their branch
+func (r *MemoryConversationRepository) AppendMessages(ctx context.Context, id string, leafID string, messages ...domain.ChatMessage) error {
+	return r.update(id, func(conversation *domain.Conversation) {
+		conversation.Messages = append(conversation.Messages, messages...)
+		conversation.MessageCount = len(conversation.Messages)
+		conversation.LeafID = leafID
+	})
+}
+
+// SetLeaf shows the branch ending at leafID
+func (r *MemoryConversationRepository) SetLeaf(ctx context.Context, id string, leafID string) error {
+	return r.update(id, func(conversation *domain.Conversation) {
+		conversation.LeafID = leafID
+	})
+}
+
+// ClearMessages removes every message and the summary of a conversation but keeps the conversation
+func (r *MemoryConversationRepository) ClearMessages(ctx context.Context, id string) error {
+	return r.update(id, func(conversation *domain.Conversation) {
+		conversation.Messages = []domain.ChatMessage{}
+		conversation.MessageCount = 0
+		conversation.LeafID = ""
+		conversation.Summary = ""
+		conversation.SummaryThrough = ""
+		conversation.SummarizedCount = 0
+	})
+}
+
+// SetSummary stores a summary of the branch up to and including the message through, unless
+// another summary was stored since previousSummary was read
+func (r *MemoryConversationRepository) SetSummary(ctx context.Context, id string, summary string, through string, previousSummary string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return false, ErrConversationNotFound
+	}
+	conversation, ok := r.conversations[parsedUUID]
+	if !ok {
+		return false, ErrConversationNotFound
+	}
+	if conversation.Summary != previousSummary {
+		return false, nil
+	}
+	conversation.Summary = summary
+	conversation.SummaryThrough = through
+	conversation.SummarizedCount = 0
+	return true, nil
+}
+
+// Rename sets a conversation's title
+func (r *MemoryConversationRepository) Rename(ctx context.Context, id string, title string) error {
+	return r.update(id, func(conversation *domain.Conversation) {
+		conversation.Title = title
+	})
+}
+
+// Delete removes a conversation
+func (r *MemoryConversationRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrConversationNotFound
+	}
+	if _, ok := r.conversations[parsedUUID]; !ok {
+		return ErrConversationNotFound
+	}
+	delete(r.conversations, parsedUUID)
+	return nil
+}
+
+func (r *MemoryConversationRepository) update(id string, apply func(conversation *domain.Conversation)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrConversationNotFound
+	}
+	conversation, ok := r.conversations[parsedUUID]
+	if !ok {
+		return ErrConversationNotFound
+	}
+	apply(conversation)
+	conversation.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"bibleapp/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConversation(t *testing.T, repo *MemoryConversationRepository) string {
+	t.Helper()
+	conversation := &domain.Conversation{ID: uuid.New(), UserID: "user-1", Title: "Psalm 23"}
+	require.NoError(t, repo.Create(context.Background(), conversation))
+	return conversation.ID.String()
+}
+
+func TestMemoryConversationRepositoryAppendsBranches(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryConversationRepository()
+	id := newTestConversation(t, repo)
+
+	require.NoError(t, repo.AppendMessages(ctx, id, "a1",
+		domain.ChatMessage{ID: "q1", Role: "user", Content: "Who wrote it?"},
+		domain.ChatMessage{ID: "a1", ParentID: "q1", Role: "assistant", Content: "David"}))
+	require.NoError(t, repo.AppendMessages(ctx, id, "a2",
+		domain.ChatMessage{ID: "a2", ParentID: "q1", Role: "assistant", Content: "King David"}))
+
+	conversation, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 3, conversation.MessageCount)
+	assert.Equal(t, "a2", conversation.LeafID)
+
+	require.NoError(t, repo.SetLeaf(ctx, id, "a1"))
+	conversation, err = repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "David", conversation.Branch()[1].Content)
+}
+
+func TestMemoryConversationRepositorySetSummaryComparesAndSwaps(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryConversationRepository()
+	id := newTestConversation(t, repo)
+
+	stored, err := repo.SetSummary(ctx, id, "first", "a1", "")
+	require.NoError(t, err)
+	assert.True(t, stored)
+
+	// A summarizer that read the conversation before the first summary was stored loses
+	stored, err = repo.SetSummary(ctx, id, "stale", "q1", "")
+	require.NoError(t, err)
+	assert.False(t, stored)
+
+	stored, err = repo.SetSummary(ctx, id, "second", "a2", "first")
+	require.NoError(t, err)
+	assert.True(t, stored)
+
+	conversation, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "second", conversation.Summary)
+	assert.Equal(t, "a2", conversation.SummaryThrough)
+}
+
+func TestMemoryConversationRepositoryClearMessagesDropsSummary(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryConversationRepository()
+	id := newTestConversation(t, repo)
+	require.NoError(t, repo.AppendMessages(ctx, id, "q1", domain.ChatMessage{ID: "q1", Role: "user", Content: "Hi"}))
+	_, err := repo.SetSummary(ctx, id, "summary", "q1", "")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.ClearMessages(ctx, id))
+
+	conversation, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Empty(t, conversation.Messages)
+	assert.Empty(t, conversation.LeafID)
+	assert.Empty(t, conversation.Summary)
+	assert.Empty(t, conversation.SummaryThrough)
+}
+
+func TestMemoryConversationRepositoryUnknownConversation(t *testing.T) {
+	repo := NewMemoryConversationRepository()
+
+	conversation, err := repo.FindByID(context.Background(), uuid.NewString())
+	require.NoError(t, err)
+	assert.Nil(t, conversation)
+	assert.ErrorIs(t, repo.SetLeaf(context.Background(), uuid.NewString(), "m0"), ErrConversationNotFound)
+}
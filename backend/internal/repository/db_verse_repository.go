@@ -7,6 +7,8 @@ import (
 	"sort"
 	"strings"
 
+	"bibleapp/backend/internal/domain"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -15,6 +17,8 @@ import (
 type VerseRepository interface {
 	GetVerseByReference(ctx context.Context, reference string) (string, error)
 	GetVersesByReferences(ctx context.Context, references []string) (map[string]string, error)
+	// SearchVerses returns verses whose text contains the words of a query, best matches first
+	SearchVerses(ctx context.Context, query string, limit int) ([]domain.Citation, error)
 }
 
 type MongoVerseRepository struct {
@@ -53,6 +57,9 @@ func NewMongoVerseRepository(db *mongo.Database) VerseRepository {
 		{
 			Keys: bson.D{{Key: "book_index", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "text", Value: "text"}}, // Text index for searching
+		},
 	}
 
 	// Create indexes in the background
@@ -582,6 +589,37 @@ func (r *MongoVerseRepository) getVerseRange(ctx context.Context, reference stri
 	return versesText.String(), nil
 }
 
+// SearchVerses runs a text search over the verses, ranked by MongoDB's text score
+func (r *MongoVerseRepository) SearchVerses(ctx context.Context, query string, limit int) ([]domain.Citation, error) {
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"book_index": 1, "chapter": 1, "verse": 1, "text": 1, "score": score}).
+		SetSort(bson.M{"score": score}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"$text": bson.M{"$search": query}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search verses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var verses []BibleVerse
+	if err := cursor.All(ctx, &verses); err != nil {
+		return nil, fmt.Errorf("failed to decode verses: %w", err)
+	}
+
+	matches := make([]domain.Citation, 0, len(verses))
+	for _, verse := range verses {
+		book := getBookName(verse.BookIndex)
+		number := extractSimpleVerse(verse.BookIndex, verse.Verse)
+		if book == "" || number <= 0 {
+			continue
+		}
+		matches = append(matches, domain.Citation{Reference: fmt.Sprintf("%s %d:%d", book, verse.Chapter, number), Text: verse.Text})
+	}
+	return matches, nil
+}
+
 // convertBookName handles alternative book name formats
 func convertBookName(book string) string {
 	// Map of common abbreviated forms to full names
@@ -619,25 +657,25 @@ func convertBookName(book string) string {
 	return book
 }
 
+// bookIndices numbers the books of the Bible in canonical order
+var bookIndices = map[string]int{
+	"Genesis": 0, "Exodus": 1, "Leviticus": 2, "Numbers": 3, "Deuteronomy": 4,
+	"Joshua": 5, "Judges": 6, "Ruth": 7, "1 Samuel": 8, "2 Samuel": 9,
+	"1 Kings": 10, "2 Kings": 11, "1 Chronicles": 12, "2 Chronicles": 13, "Ezra": 14,
+	"Nehemiah": 15, "Esther": 16, "Job": 17, "Psalm": 18, "Proverbs": 19,
+	"Ecclesiastes": 20, "Song of Solomon": 21, "Isaiah": 22, "Jeremiah": 23, "Lamentations": 24,
+	"Ezekiel": 25, "Daniel": 26, "Hosea": 27, "Joel": 28, "Amos": 29,
+	"Obadiah": 30, "Jonah": 31, "Micah": 32, "Nahum": 33, "Habakkuk": 34,
+	"Zephaniah": 35, "Haggai": 36, "Zechariah": 37, "Malachi": 38, "Matthew": 39,
+	"Mark": 40, "Luke": 41, "John": 42, "Acts": 43, "Romans": 44,
+	"1 Corinthians": 45, "2 Corinthians": 46, "Galatians": 47, "Ephesians": 48, "Philippians": 49,
+	"Colossians": 50, "1 Thessalonians": 51, "2 Thessalonians": 52, "1 Timothy": 53, "2 Timothy": 54,
+	"Titus": 55, "Philemon": 56, "Hebrews": 57, "James": 58, "1 Peter": 59,
+	"2 Peter": 60, "1 John": 61, "2 John": 62, "3 John": 63, "Jude": 64, "Revelation": 65,
+}
+
 // getBookIndex returns the numeric index for a given book name
 func getBookIndex(book string) int {
-	// Map of book names to their index numbers
-	bookIndices := map[string]int{
-		"Genesis": 0, "Exodus": 1, "Leviticus": 2, "Numbers": 3, "Deuteronomy": 4,
-		"Joshua": 5, "Judges": 6, "Ruth": 7, "1 Samuel": 8, "2 Samuel": 9,
-		"1 Kings": 10, "2 Kings": 11, "1 Chronicles": 12, "2 Chronicles": 13, "Ezra": 14,
-		"Nehemiah": 15, "Esther": 16, "Job": 17, "Psalm": 18, "Proverbs": 19,
-		"Ecclesiastes": 20, "Song of Solomon": 21, "Isaiah": 22, "Jeremiah": 23, "Lamentations": 24,
-		"Ezekiel": 25, "Daniel": 26, "Hosea": 27, "Joel": 28, "Amos": 29,
-		"Obadiah": 30, "Jonah": 31, "Micah": 32, "Nahum": 33, "Habakkuk": 34,
-		"Zephaniah": 35, "Haggai": 36, "Zechariah": 37, "Malachi": 38, "Matthew": 39,
-		"Mark": 40, "Luke": 41, "John": 42, "Acts": 43, "Romans": 44,
-		"1 Corinthians": 45, "2 Corinthians": 46, "Galatians": 47, "Ephesians": 48, "Philippians": 49,
-		"Colossians": 50, "1 Thessalonians": 51, "2 Thessalonians": 52, "1 Timothy": 53, "2 Timothy": 54,
-		"Titus": 55, "Philemon": 56, "Hebrews": 57, "James": 58, "1 Peter": 59,
-		"2 Peter": 60, "1 John": 61, "2 John": 62, "3 John": 63, "Jude": 64, "Revelation": 65,
-	}
-
 	// Normalize book name and check for index
 	if index, ok := bookIndices[book]; ok {
 		return index
@@ -651,3 +689,13 @@ func getBookIndex(book string) int {
 
 	return -1 // Not found
 }
+
+// getBookName returns the name of the book with an index, or "" if there is none
+func getBookName(index int) string {
+	for name, i := range bookIndices {
+		if i == index {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"bibleapp/backend/internal/domain"
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FeedbackFilter narrows a feedback export. Empty fields match everything.
+type FeedbackFilter struct {
+	Rating string
+	Since  time.Time // Only feedback given or changed at or after this time
+}
+
+// FeedbackRepository stores users' ratings of chat answers
+type FeedbackRepository interface {
+	// Save stores a rating, replacing the user's earlier rating of the same answer
+	Save(ctx context.Context, feedback *domain.AnswerFeedback) error
+	// List returns matching feedback, oldest first
+	List(ctx context.Context, filter FeedbackFilter) ([]*domain.AnswerFeedback, error)
+}
+
+// MongoFeedbackRepository implements FeedbackRepository using MongoDB.
+type MongoFeedbackRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoFeedbackRepository creates a new instance of MongoFeedbackRepository.
+func NewMongoFeedbackRepository(db *mongo.Database) *MongoFeedbackRepository {
+	collection := db.Collection("answer_feedback")
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "conversation_id", Value: 1}, {Key: "message_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
+	if err != nil {
+		log.Printf("WARN: Could not create unique 'user_id/conversation_id/message_id' index on answer_feedback collection: %v", err)
+	}
+
+	return &MongoFeedbackRepository{collection: collection}
+}
+
+// Save upserts the rating of an answer, keeping the ID and creation time of an earlier rating
+func (r *MongoFeedbackRepository) Save(ctx context.Context, feedback *domain.AnswerFeedback) error {
+	prepareNewFeedback(feedback)
+
+	fields, err := bson.Marshal(feedback)
+	if err != nil {
+		return err
+	}
+	var set bson.M
+	if err := bson.Unmarshal(fields, &set); err != nil {
+		return err
+	}
+	delete(set, "_id")
+	delete(set, "created_at")
+
+	filter := bson.M{"user_id": feedback.UserID, "conversation_id": feedback.ConversationID, "message_id": feedback.MessageID}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"_id": feedback.ID, "created_at": feedback.CreatedAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(feedback); err != nil {
+		log.Printf("ERROR: Failed to save feedback of user %s on conversation %s: %v", feedback.UserID, feedback.ConversationID, err)
+		return err
+	}
+	return nil
+}
+
+// List returns matching feedback, oldest first
+func (r *MongoFeedbackRepository) List(ctx context.Context, filter FeedbackFilter) ([]*domain.AnswerFeedback, error) {
+	query := bson.M{}
+	if filter.Rating != "" {
+		query["rating"] = filter.Rating
+	}
+	if !filter.Since.IsZero() {
+		query["updated_at"] = bson.M{"$gte": filter.Since}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to list answer feedback: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var feedback []*domain.AnswerFeedback
+	if err = cursor.All(ctx, &feedback); err != nil {
+		log.Printf("ERROR: Failed to decode answer feedback: %v", err)
+		return nil, err
+	}
+
+	if feedback == nil {
+		feedback = []*domain.AnswerFeedback{}
+	}
+	return feedback, nil
+}
+
+// prepareNewFeedback sets the ID and timestamps of a rating about to be stored
+func prepareNewFeedback(feedback *domain.AnswerFeedback) {
+	if feedback.ID == uuid.Nil {
+		feedback.ID = uuid.New()
+	}
+	now := time.Now()
+	feedback.CreatedAt = now
+	feedback.UpdatedAt = now
+}
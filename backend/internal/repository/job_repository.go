@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"bibleapp/backend/internal/domain"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrJobNotFound is returned when a job has no persisted state
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrLockLost is returned when renewing a job's lock that another owner has taken or that was released
+var ErrLockLost = errors.New("lock is no longer held")
+
+// JobRun describes one finished run of a scheduled job
+type JobRun struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	Err       error
+	NextRunAt time.Time
+}
+
+// JobRepository persists scheduled job state and the per-job lock that keeps
+// replicas from running the same job concurrently
+type JobRepository interface {
+	// EnsureJob creates the job's state if it doesn't exist yet and returns the stored state
+	EnsureJob(ctx context.Context, name string, schedule string, nextRunAt time.Time) (*domain.JobState, error)
+	// List returns the state of all jobs, sorted by name
+	List(ctx context.Context) ([]*domain.JobState, error)
+	// SetSchedule stores a changed schedule and its next run time
+	SetSchedule(ctx context.Context, name string, schedule string, nextRunAt time.Time) error
+	// SetNextRun moves the job's next run time, e.g. to trigger it immediately
+	SetNextRun(ctx context.Context, name string, nextRunAt time.Time) error
+	// AcquireLock takes the job's lock if the job is due and no live lock is held
+	AcquireLock(ctx context.Context, name string, owner string, now time.Time, ttl time.Duration) (bool, error)
+	// RenewLock extends a lock still held by owner
+	RenewLock(ctx context.Context, name string, owner string, until time.Time) error
+	// CompleteRun records a finished run, schedules the next one and releases owner's lock.
+	// A next run time moved after the run started, e.g. by a trigger, is kept.
+	CompleteRun(ctx context.Context, name string, owner string, run JobRun) error
+}
+
+// MongoJobRepository implements JobRepository using MongoDB.
+// Locks rely on single-document atomic updates, so they hold across replicas.
+type MongoJobRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoJobRepository creates a new instance of MongoJobRepository.
+func NewMongoJobRepository(db *mongo.Database) *MongoJobRepository {
+	return &MongoJobRepository{collection: db.Collection("scheduled_jobs")}
+}
+
+// EnsureJob creates the job's state if it doesn't exist yet and returns the stored state
+func (r *MongoJobRepository) EnsureJob(ctx context.Context, name string, schedule string, nextRunAt time.Time) (*domain.JobState, error) {
+	now := time.Now()
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"schedule":    schedule,
+			"next_run_at": nextRunAt,
+			"run_count":   0,
+			"updated_at":  now,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var state domain.JobState
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": name}, update, opts).Decode(&state)
+	if err != nil {
+		log.Printf("ERROR: Failed to ensure state of job '%s': %v", name, err)
+		return nil, err
+	}
+	return &state, nil
+}
+
+// List returns the state of all jobs, sorted by name
+func (r *MongoJobRepository) List(ctx context.Context) ([]*domain.JobState, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to list jobs: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var states []*domain.JobState
+	if err = cursor.All(ctx, &states); err != nil {
+		log.Printf("ERROR: Failed to decode jobs: %v", err)
+		return nil, err
+	}
+
+	if states == nil {
+		states = []*domain.JobState{}
+	}
+	return states, nil
+}
+
+// SetSchedule stores a changed schedule and its next run time
+func (r *MongoJobRepository) SetSchedule(ctx context.Context, name string, schedule string, nextRunAt time.Time) error {
+	update := bson.M{"$set": bson.M{"schedule": schedule, "next_run_at": nextRunAt, "updated_at": time.Now()}}
+	return r.update(ctx, name, update)
+}
+
+// SetNextRun moves the job's next run time
+func (r *MongoJobRepository) SetNextRun(ctx context.Context, name string, nextRunAt time.Time) error {
+	update := bson.M{"$set": bson.M{"next_run_at": nextRunAt, "updated_at": time.Now()}}
+	return r.update(ctx, name, update)
+}
+
+func (r *MongoJobRepository) update(ctx context.Context, name string, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": name}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to update job '%s': %v", name, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// AcquireLock takes the job's lock if the job is due and no live lock is held.
+// The due check is part of the same atomic update, so once one replica has run
+// the job and moved next_run_at forward, other replicas can't run it again.
+func (r *MongoJobRepository) AcquireLock(ctx context.Context, name string, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	filter := bson.M{
+		"_id":         name,
+		"next_run_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"lock_owner": bson.M{"$exists": false}},
+			bson.M{"lock_owner": ""},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"lock_owner":   owner,
+		"locked_until": now.Add(ttl),
+		"updated_at":   now,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to acquire lock of job '%s': %v", name, err)
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RenewLock extends a lock still held by owner
+func (r *MongoJobRepository) RenewLock(ctx context.Context, name string, owner string, until time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": name, "lock_owner": owner},
+		bson.M{"$set": bson.M{"locked_until": until, "updated_at": time.Now()}})
+	if err != nil {
+		log.Printf("ERROR: Failed to renew lock of job '%s': %v", name, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// CompleteRun records a finished run, schedules the next one and releases owner's lock.
+// The next run is only written while next_run_at is still the due time the run was started for;
+// a later value was set during the run by a trigger or schedule change and is kept.
+func (r *MongoJobRepository) CompleteRun(ctx context.Context, name string, owner string, run JobRun) error {
+	set := bson.M{
+		"last_run_at":   run.StartedAt,
+		"last_duration": run.Duration.Round(time.Millisecond).String(),
+		"next_run_at":   run.NextRunAt,
+		"updated_at":    time.Now(),
+	}
+	unset := bson.M{"lock_owner": "", "locked_until": ""}
+	if run.Err != nil {
+		set["last_error"] = run.Err.Error()
+	} else {
+		set["last_success_at"] = run.StartedAt
+		unset["last_error"] = ""
+	}
+
+	update := bson.M{
+		"$set":   set,
+		"$unset": unset,
+		"$inc":   bson.M{"run_count": 1},
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": name, "lock_owner": owner, "next_run_at": bson.M{"$lte": run.StartedAt}}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to record run of job '%s': %v", name, err)
+		return err
+	}
+	if result.MatchedCount == 1 {
+		return nil
+	}
+
+	delete(set, "next_run_at")
+	result, err = r.collection.UpdateOne(ctx, bson.M{"_id": name, "lock_owner": owner}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to record run of job '%s': %v", name, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("lock was lost before the run completed")
+	}
+	log.Printf("INFO: Job '%s' was triggered or rescheduled during its run, keeping the new run time", name)
+	return nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"bibleapp/backend/internal/domain"
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LLMUsageFilter narrows usage totals. An empty UserID matches every user.
+type LLMUsageFilter struct {
+	UserID string
+	From   time.Time // Inclusive
+	To     time.Time // Exclusive
+}
+
+// LLMUsageRepository stores the token counts and cost of LLM requests
+type LLMUsageRepository interface {
+	Record(ctx context.Context, usage *domain.LLMUsage) error
+	// CostSince sums the cost of the requests made since a time
+	CostSince(ctx context.Context, since time.Time) (float64, error)
+	// Totals sums usage per UTC day and feature, oldest day first
+	Totals(ctx context.Context, filter LLMUsageFilter) ([]domain.LLMUsageTotal, error)
+}
+
+// MongoLLMUsageRepository implements LLMUsageRepository using MongoDB.
+type MongoLLMUsageRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoLLMUsageRepository creates a new instance of MongoLLMUsageRepository.
+func NewMongoLLMUsageRepository(db *mongo.Database) *MongoLLMUsageRepository {
+	collection := db.Collection("llm_usage")
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	}
+	_, err := collection.Indexes().CreateMany(context.Background(), indexes)
+	if err != nil {
+		log.Printf("WARN: Could not create indexes on llm_usage collection: %v", err)
+	}
+
+	return &MongoLLMUsageRepository{collection: collection}
+}
+
+// Record inserts the usage of one request
+func (r *MongoLLMUsageRepository) Record(ctx context.Context, usage *domain.LLMUsage) error {
+	prepareNewLLMUsage(usage)
+	if _, err := r.collection.InsertOne(ctx, usage); err != nil {
+		log.Printf("ERROR: Failed to insert LLM usage of %s: %v", usage.Feature, err)
+		return err
+	}
+	return nil
+}
+
+// CostSince sums the cost of the requests made since a time
+func (r *MongoLLMUsageRepository) CostSince(ctx context.Context, since time.Time) (float64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "cost": bson.M{"$sum": "$cost"}}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Printf("ERROR: Failed to sum LLM cost: %v", err)
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Cost float64 `bson:"cost"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		log.Printf("ERROR: Failed to decode LLM cost: %v", err)
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return results[0].Cost, nil
+}
+
+// Totals sums usage per UTC day and feature, oldest day first
+func (r *MongoLLMUsageRepository) Totals(ctx context.Context, filter LLMUsageFilter) ([]domain.LLMUsageTotal, error) {
+	match := bson.M{"created_at": bson.M{"$gte": filter.From, "$lt": filter.To}}
+	if filter.UserID != "" {
+		match["user_id"] = filter.UserID
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"day":     bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$created_at"}},
+				"feature": "$feature",
+			},
+			"requests":          bson.M{"$sum": 1},
+			"prompt_tokens":     bson.M{"$sum": "$prompt_tokens"},
+			"completion_tokens": bson.M{"$sum": "$completion_tokens"},
+			"cost":              bson.M{"$sum": "$cost"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.day", Value: 1}, {Key: "_id.feature", Value: 1}}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Printf("ERROR: Failed to total LLM usage: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID struct {
+			Day     string `bson:"day"`
+			Feature string `bson:"feature"`
+		} `bson:"_id"`
+		Requests         int     `bson:"requests"`
+		PromptTokens     int     `bson:"prompt_tokens"`
+		CompletionTokens int     `bson:"completion_tokens"`
+		Cost             float64 `bson:"cost"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		log.Printf("ERROR: Failed to decode LLM usage totals: %v", err)
+		return nil, err
+	}
+
+	totals := make([]domain.LLMUsageTotal, len(results))
+	for i, result := range results {
+		totals[i] = domain.LLMUsageTotal{
+			Day:              result.ID.Day,
+			Feature:          result.ID.Feature,
+			Requests:         result.Requests,
+			PromptTokens:     result.PromptTokens,
+			CompletionTokens: result.CompletionTokens,
+			Cost:             result.Cost,
+		}
+	}
+	return totals, nil
+}
+
+// prepareNewLLMUsage sets the ID and timestamp of a usage record about to be stored
+func prepareNewLLMUsage(usage *domain.LLMUsage) {
+	if usage.ID == uuid.Nil {
+		usage.ID = uuid.New()
+	}
+	if usage.CreatedAt.IsZero() {
+		usage.CreatedAt = time.Now()
+	}
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"bibleapp/backend/internal/domain"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrModerationFlagNotFound is returned when reviewing a flag that doesn't exist
+var ErrModerationFlagNotFound = errors.New("moderation flag not found")
+
+// ModerationFlagFilter narrows the review queue. Nil UserIDs match every user; an empty
+// Status matches every status.
+type ModerationFlagFilter struct {
+	UserIDs []string
+	Status  string
+}
+
+// ModerationRepository stores the moderation review queue
+type ModerationRepository interface {
+	Create(ctx context.Context, flag *domain.ModerationFlag) error
+	// FindByID returns a flag, or nil if it doesn't exist
+	FindByID(ctx context.Context, id string) (*domain.ModerationFlag, error)
+	// List returns flags, newest first
+	List(ctx context.Context, filter ModerationFlagFilter) ([]*domain.ModerationFlag, error)
+	// AddReview appends an audit entry and moves the flag to the entry's status
+	AddReview(ctx context.Context, id string, entry domain.ModerationAuditEntry) error
+}
+
+// MongoModerationRepository implements ModerationRepository using MongoDB.
+type MongoModerationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoModerationRepository creates a new instance of MongoModerationRepository.
+func NewMongoModerationRepository(db *mongo.Database) *MongoModerationRepository {
+	collection := db.Collection("moderation_flags")
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+	}
+	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
+	if err != nil {
+		log.Printf("WARN: Could not create 'user_id/status/created_at' index on moderation_flags collection: %v", err)
+	}
+
+	return &MongoModerationRepository{collection: collection}
+}
+
+// Create inserts a new flag
+func (r *MongoModerationRepository) Create(ctx context.Context, flag *domain.ModerationFlag) error {
+	prepareNewModerationFlag(flag)
+	if _, err := r.collection.InsertOne(ctx, flag); err != nil {
+		log.Printf("ERROR: Failed to insert moderation flag for user %s: %v", flag.UserID, err)
+		return err
+	}
+	return nil
+}
+
+// FindByID returns a flag, or nil if it doesn't exist
+func (r *MongoModerationRepository) FindByID(ctx context.Context, id string) (*domain.ModerationFlag, error) {
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var flag domain.ModerationFlag
+	err = r.collection.FindOne(ctx, bson.M{"_id": parsedUUID}).Decode(&flag)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.Printf("ERROR: Failed to find moderation flag %s: %v", id, err)
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// List returns flags, newest first
+func (r *MongoModerationRepository) List(ctx context.Context, filter ModerationFlagFilter) ([]*domain.ModerationFlag, error) {
+	query := bson.M{}
+	if filter.UserIDs != nil {
+		query["user_id"] = bson.M{"$in": filter.UserIDs}
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to list moderation flags: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var flags []*domain.ModerationFlag
+	if err = cursor.All(ctx, &flags); err != nil {
+		log.Printf("ERROR: Failed to decode moderation flags: %v", err)
+		return nil, err
+	}
+
+	if flags == nil {
+		flags = []*domain.ModerationFlag{}
+	}
+	return flags, nil
+}
+
+// AddReview appends an audit entry and moves the flag to the entry's status in one atomic update
+func (r *MongoModerationRepository) AddReview(ctx context.Context, id string, entry domain.ModerationAuditEntry) error {
+	parsedUUID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrModerationFlagNotFound
+	}
+
+	update := bson.M{
+		"$push": bson.M{"audit": entry},
+		"$set":  bson.M{"status": entry.Status, "updated_at": entry.At},
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": parsedUUID}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to review moderation flag %s: %v", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrModerationFlagNotFound
+	}
+	return nil
+}
+
+// prepareNewModerationFlag sets the ID, status and timestamps of a flag about to be stored
+func prepareNewModerationFlag(flag *domain.ModerationFlag) {
+	if flag.ID == uuid.Nil {
+		flag.ID = uuid.New()
+	}
+	now := time.Now()
+	flag.CreatedAt = now
+	flag.UpdatedAt = now
+	if flag.Status == "" {
+		flag.Status = domain.FlagStatusOpen
+	}
+	if flag.Audit == nil {
+		flag.Audit = []domain.ModerationAuditEntry{}
+	}
+}
@@ -24,7 +24,22 @@ type PlanRepository interface {
 	FindByID(ctx context.Context, id string) (*domain.ReadingPlan, error)
 	FindByUser(ctx context.Context, userID string) ([]*domain.ReadingPlan, error)
 	Delete(ctx context.Context, id string) error
-	// Add other methods like FindAll as needed
+	FindAll(ctx context.Context) ([]*domain.ReadingPlan, error)
+	// FindDefaultByTrack retrieves the default plans of a track, newest first.
+	// includeUntracked also returns default plans saved before tracks existed.
+	FindDefaultByTrack(ctx context.Context, trackID string, includeUntracked bool) ([]*domain.ReadingPlan, error)
+	// SetDayExplanation stores the devotional of one day without touching the rest of the plan.
+	// With onlyIfEmpty it leaves an existing devotional alone and reports false.
+	SetDayExplanation(ctx context.Context, planID string, dayNumber int, explanation string, onlyIfEmpty bool) (bool, error)
+	// SetDayStudy stores the reflection questions and quiz of one day, like SetDayExplanation
+	SetDayStudy(ctx context.Context, planID string, dayNumber int, questions []string, quiz []domain.QuizQuestion, onlyIfEmpty bool) (bool, error)
+	// SetDayStatus stores the resolution status and reading length of one day, like SetDayExplanation.
+	// A day whose reference has changed since is left alone.
+	SetDayStatus(ctx context.Context, planID string, day domain.DailyVerse) error
+	// SetActive marks one of a user's plans as their active plan and clears the flag on the others
+	SetActive(ctx context.Context, userID string, planID string) error
+	// SetPriority stores the order of a plan among its user's concurrent plans
+	SetPriority(ctx context.Context, planID string, priority int) error
 }
 
 // MongoPlanRepository implements PlanRepository using MongoDB.
@@ -138,6 +153,7 @@ func (r *MongoPlanRepository) Save(ctx context.Context, plan *domain.ReadingPlan
 				"duration_days":   plan.DurationDays,
 				"target_audience": plan.TargetAudience,
 				"daily_verses":    plan.DailyVerses,
+				"minutes_per_day": plan.MinutesPerDay,
 			},
 			"$setOnInsert": bson.M{
 				"created_at": plan.CreatedAt, // Keep original created_at if upsert happens
@@ -199,3 +215,196 @@ func (r *MongoPlanRepository) Delete(ctx context.Context, id string) error {
 	log.Printf("INFO: Successfully deleted plan with ID: %s", id)
 	return nil
 }
+
+// FindAll retrieves every stored plan, newest first.
+func (r *MongoPlanRepository) FindAll(ctx context.Context) ([]*domain.ReadingPlan, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to execute find query for all plans: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var plans []*domain.ReadingPlan
+	if err = cursor.All(ctx, &plans); err != nil {
+		log.Printf("ERROR: Failed to decode plans: %v", err)
+		return nil, err
+	}
+
+	if plans == nil {
+		plans = []*domain.ReadingPlan{}
+	}
+	return plans, nil
+}
+
+// FindDefaultByTrack retrieves the default plans generated for a track, newest first.
+func (r *MongoPlanRepository) FindDefaultByTrack(ctx context.Context, trackID string, includeUntracked bool) ([]*domain.ReadingPlan, error) {
+	filter := bson.M{"user_id": domain.DefaultPlanUserID, "track_id": trackID}
+	if includeUntracked {
+		filter = bson.M{
+			"user_id": domain.DefaultPlanUserID,
+			"$or": bson.A{
+				bson.M{"track_id": trackID},
+				bson.M{"track_id": bson.M{"$exists": false}},
+				bson.M{"track_id": ""},
+			},
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to find default plans for track %s: %v", trackID, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var plans []*domain.ReadingPlan
+	if err = cursor.All(ctx, &plans); err != nil {
+		log.Printf("ERROR: Failed to decode default plans for track %s: %v", trackID, err)
+		return nil, err
+	}
+
+	if plans == nil {
+		plans = []*domain.ReadingPlan{}
+	}
+	log.Printf("INFO: Found %d default plans for track %s", len(plans), trackID)
+	return plans, nil
+}
+
+// SetDayExplanation stores the devotional of one day without touching the rest of the plan.
+// The emptiness check is part of the update filter, so concurrent first views store one devotional.
+func (r *MongoPlanRepository) SetDayExplanation(ctx context.Context, planID string, dayNumber int, explanation string, onlyIfEmpty bool) (bool, error) {
+	parsedUUID, err := uuid.Parse(planID)
+	if err != nil {
+		return false, errors.New("invalid plan UUID format")
+	}
+
+	dayFilter := bson.M{"day": dayNumber}
+	if onlyIfEmpty {
+		dayFilter["$or"] = bson.A{
+			bson.M{"explanation": bson.M{"$exists": false}},
+			bson.M{"explanation": ""},
+		}
+	}
+	filter := bson.M{
+		"_id":          parsedUUID,
+		"daily_verses": bson.M{"$elemMatch": dayFilter},
+	}
+	update := bson.M{"$set": bson.M{
+		"daily_verses.$.explanation":              explanation,
+		"daily_verses.$.explanation_generated_at": time.Now(),
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to store explanation for day %d of plan %s: %v", dayNumber, planID, err)
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// SetDayStudy stores the reflection questions and quiz of one day without touching the rest of the plan
+func (r *MongoPlanRepository) SetDayStudy(ctx context.Context, planID string, dayNumber int, questions []string, quiz []domain.QuizQuestion, onlyIfEmpty bool) (bool, error) {
+	parsedUUID, err := uuid.Parse(planID)
+	if err != nil {
+		return false, errors.New("invalid plan UUID format")
+	}
+
+	dayFilter := bson.M{"day": dayNumber}
+	if onlyIfEmpty {
+		dayFilter["quiz.0"] = bson.M{"$exists": false}
+	}
+	filter := bson.M{
+		"_id":          parsedUUID,
+		"daily_verses": bson.M{"$elemMatch": dayFilter},
+	}
+	update := bson.M{"$set": bson.M{
+		"daily_verses.$.reflection_questions": questions,
+		"daily_verses.$.quiz":                 quiz,
+		"daily_verses.$.study_generated_at":   time.Now(),
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to store study for day %d of plan %s: %v", dayNumber, planID, err)
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// SetDayStatus stores the resolution status and reading length of one day without touching the rest of the plan.
+// Matching on the reference keeps a concurrent edit of the day from getting the old reference's status.
+func (r *MongoPlanRepository) SetDayStatus(ctx context.Context, planID string, day domain.DailyVerse) error {
+	parsedUUID, err := uuid.Parse(planID)
+	if err != nil {
+		return errors.New("invalid plan UUID format")
+	}
+
+	filter := bson.M{
+		"_id":          parsedUUID,
+		"daily_verses": bson.M{"$elemMatch": bson.M{"day": day.DayNumber, "reference": day.Reference}},
+	}
+	update := bson.M{"$set": bson.M{
+		"daily_verses.$.resolution_status": day.ResolutionStatus,
+		"daily_verses.$.resolution_error":  day.ResolutionError,
+		"daily_verses.$.word_count":        day.WordCount,
+		"daily_verses.$.estimated_minutes": day.EstimatedMinutes,
+	}}
+
+	if _, err := r.collection.UpdateOne(ctx, filter, update); err != nil {
+		log.Printf("ERROR: Failed to store status for day %d of plan %s: %v", day.DayNumber, planID, err)
+		return err
+	}
+	return nil
+}
+
+// SetActive marks one of a user's plans as their active plan and clears the flag on the others.
+// The new plan is flagged first, so a reader never sees the user without an active plan.
+func (r *MongoPlanRepository) SetActive(ctx context.Context, userID string, planID string) error {
+	parsedUUID, err := uuid.Parse(planID)
+	if err != nil {
+		return errors.New("invalid plan UUID format")
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": parsedUUID, "user_id": userID},
+		bson.M{"$set": bson.M{"is_active": true}})
+	if err != nil {
+		log.Printf("ERROR: Failed to activate plan %s: %v", planID, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "_id": bson.M{"$ne": parsedUUID}, "is_active": true},
+		bson.M{"$set": bson.M{"is_active": false}})
+	if err != nil {
+		log.Printf("ERROR: Failed to deactivate other plans of user %s: %v", userID, err)
+		return err
+	}
+	log.Printf("INFO: Plan %s is now the active plan of user %s", planID, userID)
+	return nil
+}
+
+// SetPriority stores the order of a plan among its user's concurrent plans
+func (r *MongoPlanRepository) SetPriority(ctx context.Context, planID string, priority int) error {
+	parsedUUID, err := uuid.Parse(planID)
+	if err != nil {
+		return errors.New("invalid plan UUID format")
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": parsedUUID}, bson.M{"$set": bson.M{"priority": priority}})
+	if err != nil {
+		log.Printf("ERROR: Failed to set priority of plan %s: %v", planID, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"bibleapp/backend/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PlanRevisionRepository stores the append-only revision log of reading plans.
+// Revisions are never updated or deleted once written.
+type PlanRevisionRepository interface {
+	// Append assigns the next revision number for the plan and stores the revision
+	Append(ctx context.Context, revision *domain.PlanRevision) error
+	// ListByPlan returns a plan's revisions, oldest first
+	ListByPlan(ctx context.Context, planID string) ([]*domain.PlanRevision, error)
+	// FindByRevision returns a single revision, or nil if it doesn't exist
+	FindByRevision(ctx context.Context, planID string, revision int) (*domain.PlanRevision, error)
+}
+
+// MongoPlanRevisionRepository implements PlanRevisionRepository using MongoDB.
+type MongoPlanRevisionRepository struct {
+	collection *mongo.Collection
+}
+
+// appendRetries bounds how often Append retries when a concurrent writer takes the same revision number
+const appendRetries = 3
+
+// NewMongoPlanRevisionRepository creates a new instance of MongoPlanRevisionRepository.
+func NewMongoPlanRevisionRepository(db *mongo.Database) *MongoPlanRevisionRepository {
+	collection := db.Collection("plan_revisions")
+
+	// The unique index is what keeps revision numbers consistent under concurrent edits
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "plan_id", Value: 1}, {Key: "revision", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
+	if err != nil {
+		log.Printf("WARN: Could not create 'plan_id/revision' index on plan_revisions collection: %v", err)
+	}
+
+	return &MongoPlanRevisionRepository{collection: collection}
+}
+
+// Append stores a new revision with the next revision number for its plan
+func (r *MongoPlanRevisionRepository) Append(ctx context.Context, revision *domain.PlanRevision) error {
+	if revision.PlanID == uuid.Nil {
+		return errors.New("cannot append revision without a plan ID")
+	}
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = time.Now()
+	}
+
+	for attempt := 0; attempt < appendRetries; attempt++ {
+		latest, err := r.latestRevisionNumber(ctx, revision.PlanID)
+		if err != nil {
+			return err
+		}
+
+		revision.ID = uuid.New()
+		revision.Revision = latest + 1
+
+		_, err = r.collection.InsertOne(ctx, revision)
+		if err == nil {
+			log.Printf("INFO: Recorded revision %d (%s) for plan %s", revision.Revision, revision.Action, revision.PlanID)
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			log.Printf("ERROR: Failed to insert revision for plan %s: %v", revision.PlanID, err)
+			return err
+		}
+		log.Printf("WARN: Revision %d for plan %s was taken concurrently, retrying", revision.Revision, revision.PlanID)
+	}
+
+	return fmt.Errorf("failed to append revision for plan %s after %d attempts", revision.PlanID, appendRetries)
+}
+
+// latestRevisionNumber returns the highest revision number for a plan, or 0 if there are none
+func (r *MongoPlanRevisionRepository) latestRevisionNumber(ctx context.Context, planID uuid.UUID) (int, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "revision", Value: -1}}).
+		SetProjection(bson.M{"revision": 1})
+
+	var latest struct {
+		Revision int `bson:"revision"`
+	}
+	err := r.collection.FindOne(ctx, bson.M{"plan_id": planID}, opts).Decode(&latest)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		log.Printf("ERROR: Failed to find latest revision for plan %s: %v", planID, err)
+		return 0, err
+	}
+	return latest.Revision, nil
+}
+
+// ListByPlan returns all revisions of a plan, oldest first
+func (r *MongoPlanRevisionRepository) ListByPlan(ctx context.Context, planID string) ([]*domain.PlanRevision, error) {
+	parsedUUID, err := uuid.Parse(planID)
+	if err != nil {
+		return nil, errors.New("invalid plan UUID format")
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "revision", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"plan_id": parsedUUID}, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to list revisions for plan %s: %v", planID, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var revisions []*domain.PlanRevision
+	if err = cursor.All(ctx, &revisions); err != nil {
+		log.Printf("ERROR: Failed to decode revisions for plan %s: %v", planID, err)
+		return nil, err
+	}
+
+	if revisions == nil {
+		revisions = []*domain.PlanRevision{}
+	}
+	return revisions, nil
+}
+
+// FindByRevision returns a single revision of a plan, or nil if it doesn't exist
+func (r *MongoPlanRevisionRepository) FindByRevision(ctx context.Context, planID string, revision int) (*domain.PlanRevision, error) {
+	parsedUUID, err := uuid.Parse(planID)
+	if err != nil {
+		return nil, errors.New("invalid plan UUID format")
+	}
+
+	var result domain.PlanRevision
+	err = r.collection.FindOne(ctx, bson.M{"plan_id": parsedUUID, "revision": revision}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.Printf("ERROR: Failed to find revision %d of plan %s: %v", revision, planID, err)
+		return nil, err
+	}
+	return &result, nil
+}
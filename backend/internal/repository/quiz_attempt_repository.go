@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"bibleapp/backend/internal/domain"
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// QuizAttemptRepository stores submitted quiz answers and their scores
+type QuizAttemptRepository interface {
+	Save(ctx context.Context, attempt *domain.QuizAttempt) error
+	// ListByUser returns a user's attempts submitted since the given time, newest first
+	ListByUser(ctx context.Context, userID string, since time.Time) ([]*domain.QuizAttempt, error)
+}
+
+// MongoQuizAttemptRepository implements QuizAttemptRepository using MongoDB.
+type MongoQuizAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMongoQuizAttemptRepository creates a new instance of MongoQuizAttemptRepository.
+func NewMongoQuizAttemptRepository(db *mongo.Database) *MongoQuizAttemptRepository {
+	collection := db.Collection("quiz_attempts")
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "submitted_at", Value: -1}},
+	}
+	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
+	if err != nil {
+		log.Printf("WARN: Could not create 'user_id/submitted_at' index on quiz_attempts collection: %v", err)
+	}
+
+	return &MongoQuizAttemptRepository{collection: collection}
+}
+
+// Save inserts a new quiz attempt
+func (r *MongoQuizAttemptRepository) Save(ctx context.Context, attempt *domain.QuizAttempt) error {
+	if attempt.ID == uuid.Nil {
+		attempt.ID = uuid.New()
+	}
+	if attempt.SubmittedAt.IsZero() {
+		attempt.SubmittedAt = time.Now()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, attempt); err != nil {
+		log.Printf("ERROR: Failed to insert quiz attempt for user %s: %v", attempt.UserID, err)
+		return err
+	}
+	return nil
+}
+
+// ListByUser returns a user's attempts submitted since the given time, newest first
+func (r *MongoQuizAttemptRepository) ListByUser(ctx context.Context, userID string, since time.Time) ([]*domain.QuizAttempt, error) {
+	filter := bson.M{"user_id": userID, "submitted_at": bson.M{"$gte": since}}
+	opts := options.Find().SetSort(bson.D{{Key: "submitted_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to list quiz attempts for user %s: %v", userID, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var attempts []*domain.QuizAttempt
+	if err = cursor.All(ctx, &attempts); err != nil {
+		log.Printf("ERROR: Failed to decode quiz attempts for user %s: %v", userID, err)
+		return nil, err
+	}
+
+	if attempts == nil {
+		attempts = []*domain.QuizAttempt{}
+	}
+	return attempts, nil
+}
@@ -17,11 +17,26 @@ import (
 // We might need more methods later (e.g., FindByID, Update).
 type UserRepository interface {
 	FindByGoogleID(ctx context.Context, googleID string) (*domain.User, error)
+	FindByID(ctx context.Context, id string) (*domain.User, error)
+	List(ctx context.Context) ([]*domain.User, error)
 	Create(ctx context.Context, user *domain.User) (*domain.User, error)
+	UpdateRoles(ctx context.Context, id string, roles []string) error
+	UpdateTrack(ctx context.Context, id string, trackID string) error
+	// UpdateAudience sets a user's audience profile and whether it is locked; nil goes back to the default
+	UpdateAudience(ctx context.Context, id string, audience *domain.AudienceProfile, locked bool) error
+	UpdateTimezone(ctx context.Context, id string, timezone string, previous string, from time.Time) error
+	// UpdateChatLimit sets a user's daily chat limit; nil goes back to the role limits
+	UpdateChatLimit(ctx context.Context, id string, limit *int) error
+	UpdateGuardians(ctx context.Context, id string, guardianIDs []string) error
+	// FindByGuardian returns the users linked to a guardian
+	FindByGuardian(ctx context.Context, guardianID string) ([]*domain.User, error)
 	// Update potentially needed if user info from Google changes
 	// Update(ctx context.Context, user *domain.User) error
 }
 
+// ErrUserNotFound is returned by updates that target a missing user
+var ErrUserNotFound = errors.New("user not found")
+
 // MongoUserRepository implements UserRepository using MongoDB.
 type MongoUserRepository struct {
 	collection *mongo.Collection
@@ -93,6 +108,106 @@ func (r *InMemoryUserRepository) Create(ctx context.Context, user *domain.User)
 	return user, nil
 }
 
+// FindByID retrieves a user by their internal ID
+func (r *InMemoryUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+// List returns all users in the in-memory repository
+func (r *InMemoryUserRepository) List(ctx context.Context) ([]*domain.User, error) {
+	users := make([]*domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// UpdateRoles replaces a user's roles
+func (r *InMemoryUserRepository) UpdateRoles(ctx context.Context, id string, roles []string) error {
+	user, _ := r.FindByID(ctx, id)
+	if user == nil {
+		return ErrUserNotFound
+	}
+	user.Roles = roles
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateTrack sets a user's chosen default plan track
+func (r *InMemoryUserRepository) UpdateTrack(ctx context.Context, id string, trackID string) error {
+	user, _ := r.FindByID(ctx, id)
+	if user == nil {
+		return ErrUserNotFound
+	}
+	user.TrackID = trackID
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateAudience sets a user's audience profile; nil goes back to the default
+func (r *InMemoryUserRepository) UpdateAudience(ctx context.Context, id string, audience *domain.AudienceProfile, locked bool) error {
+	user, _ := r.FindByID(ctx, id)
+	if user == nil {
+		return ErrUserNotFound
+	}
+	user.Audience = audience
+	user.AudienceLocked = locked
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateTimezone sets the time zone a user's daily chat limit resets in, and the zone it replaces
+// until from; a zero from drops the previous zone
+func (r *InMemoryUserRepository) UpdateTimezone(ctx context.Context, id string, timezone string, previous string, from time.Time) error {
+	user, _ := r.FindByID(ctx, id)
+	if user == nil {
+		return ErrUserNotFound
+	}
+	user.Timezone = timezone
+	user.PreviousTimezone = previous
+	user.TimezoneFrom = from
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateChatLimit sets a user's daily chat limit; nil goes back to the role limits
+func (r *InMemoryUserRepository) UpdateChatLimit(ctx context.Context, id string, limit *int) error {
+	user, _ := r.FindByID(ctx, id)
+	if user == nil {
+		return ErrUserNotFound
+	}
+	user.ChatLimit = limit
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateGuardians replaces the guardians linked to a user
+func (r *InMemoryUserRepository) UpdateGuardians(ctx context.Context, id string, guardianIDs []string) error {
+	user, _ := r.FindByID(ctx, id)
+	if user == nil {
+		return ErrUserNotFound
+	}
+	user.GuardianIDs = guardianIDs
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// FindByGuardian returns the users linked to a guardian
+func (r *InMemoryUserRepository) FindByGuardian(ctx context.Context, guardianID string) ([]*domain.User, error) {
+	var users []*domain.User
+	for _, user := range r.users {
+		if user.HasGuardian(guardianID) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 // FindByGoogleID finds a user by their Google ID.
 func (r *MongoUserRepository) FindByGoogleID(ctx context.Context, googleID string) (*domain.User, error) {
 	filter := bson.M{"google_id": googleID}
@@ -144,3 +259,207 @@ func (r *MongoUserRepository) Create(ctx context.Context, user *domain.User) (*d
 		return nil, errors.New("failed to retrieve user ID after creation")
 	}
 }
+
+// FindByID finds a user by their internal ID (the hex form of the MongoDB ObjectID).
+func (r *MongoUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid user ID format")
+	}
+
+	var user domain.User
+	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.Printf("ERROR: Failed to find user by ID %s: %v", id, err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// List returns all users, oldest first.
+func (r *MongoUserRepository) List(ctx context.Context) ([]*domain.User, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to list users: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*domain.User
+	if err = cursor.All(ctx, &users); err != nil {
+		log.Printf("ERROR: Failed to decode users: %v", err)
+		return nil, err
+	}
+
+	if users == nil {
+		users = []*domain.User{}
+	}
+	return users, nil
+}
+
+// UpdateRoles replaces a user's roles.
+func (r *MongoUserRepository) UpdateRoles(ctx context.Context, id string, roles []string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	update := bson.M{"$set": bson.M{"roles": roles, "updated_at": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to update roles for user %s: %v", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	log.Printf("INFO: Updated roles for user %s: %v", id, roles)
+	return nil
+}
+
+// UpdateTrack sets a user's chosen default plan track.
+func (r *MongoUserRepository) UpdateTrack(ctx context.Context, id string, trackID string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	update := bson.M{"$set": bson.M{"track_id": trackID, "updated_at": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to update track for user %s: %v", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateAudience sets a user's audience profile; nil goes back to the default.
+func (r *MongoUserRepository) UpdateAudience(ctx context.Context, id string, audience *domain.AudienceProfile, locked bool) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	unset := bson.M{}
+	if audience != nil {
+		set["audience"] = audience
+	} else {
+		unset["audience"] = ""
+	}
+	if locked {
+		set["audience_locked"] = true
+	} else {
+		unset["audience_locked"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to update audience for user %s: %v", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateTimezone sets the time zone a user's daily chat limit resets in, and the zone it replaces
+// until from. A zero from drops the previous zone.
+func (r *MongoUserRepository) UpdateTimezone(ctx context.Context, id string, timezone string, previous string, from time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	update := bson.M{"$set": bson.M{"timezone": timezone, "previous_timezone": previous, "timezone_from": from, "updated_at": time.Now()}}
+	if from.IsZero() {
+		update = bson.M{
+			"$set":   bson.M{"timezone": timezone, "updated_at": time.Now()},
+			"$unset": bson.M{"previous_timezone": "", "timezone_from": ""},
+		}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to update timezone for user %s: %v", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateChatLimit sets a user's daily chat limit; nil goes back to the role limits.
+func (r *MongoUserRepository) UpdateChatLimit(ctx context.Context, id string, limit *int) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	update := bson.M{"$set": bson.M{"chat_limit": limit, "updated_at": time.Now()}}
+	if limit == nil {
+		update = bson.M{"$unset": bson.M{"chat_limit": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to update chat limit for user %s: %v", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateGuardians replaces the guardians linked to a user.
+func (r *MongoUserRepository) UpdateGuardians(ctx context.Context, id string, guardianIDs []string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	update := bson.M{"$set": bson.M{"guardian_ids": guardianIDs, "updated_at": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		log.Printf("ERROR: Failed to update guardians for user %s: %v", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	log.Printf("INFO: Updated guardians for user %s: %v", id, guardianIDs)
+	return nil
+}
+
+// FindByGuardian returns the users linked to a guardian, oldest first.
+func (r *MongoUserRepository) FindByGuardian(ctx context.Context, guardianID string) ([]*domain.User, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"guardian_ids": guardianID}, opts)
+	if err != nil {
+		log.Printf("ERROR: Failed to find users of guardian %s: %v", guardianID, err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*domain.User
+	if err = cursor.All(ctx, &users); err != nil {
+		log.Printf("ERROR: Failed to decode users of guardian %s: %v", guardianID, err)
+		return nil, err
+	}
+
+	if users == nil {
+		users = []*domain.User{}
+	}
+	return users, nil
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression with the standard five fields:
+// minute, hour, day of month, month and day of week.
+// Fields support "*", single values, ranges ("1-5"), lists ("1,15") and steps ("*/15", "0-30/10").
+// The descriptors @hourly, @daily, @weekly and @monthly are also accepted.
+type Schedule struct {
+	expr    string
+	minutes [60]bool
+	hours   [24]bool
+	days    [32]bool // Index 0 is unused
+	months  [13]bool // Index 0 is unused
+	weekday [7]bool  // Sunday is 0; 7 is accepted as Sunday when parsing
+	anyDay  bool     // Day of month starts with "*"
+	anyWeek bool     // Day of week starts with "*"
+}
+
+var descriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// maxSearchMinutes bounds the search for the next run, enough for any valid expression
+const maxSearchMinutes = 5 * 366 * 24 * 60
+
+// ParseSchedule parses a five-field cron expression
+func ParseSchedule(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if expanded, ok := descriptors[strings.ToLower(expr)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{expr: expr}
+	if err := parseField(fields[0], 0, 59, s.minutes[:]); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	if err := parseField(fields[1], 0, 23, s.hours[:]); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	if err := parseField(fields[2], 1, 31, s.days[:]); err != nil {
+		return nil, fmt.Errorf("invalid day of month field: %w", err)
+	}
+	if err := parseField(fields[3], 1, 12, s.months[:]); err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+
+	var weekdays [8]bool
+	if err := parseField(fields[4], 0, 7, weekdays[:]); err != nil {
+		return nil, fmt.Errorf("invalid day of week field: %w", err)
+	}
+	copy(s.weekday[:], weekdays[:7])
+	if weekdays[7] {
+		s.weekday[0] = true
+	}
+
+	s.anyDay = strings.HasPrefix(fields[2], "*")
+	s.anyWeek = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first run time strictly after the given time, in the time's location
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < maxSearchMinutes; i++ {
+		if s.matches(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+// matches follows cron semantics: when both day fields are restricted, either may match
+func (s *Schedule) matches(t time.Time) bool {
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[t.Month()] {
+		return false
+	}
+
+	dayMatch := s.days[t.Day()]
+	weekMatch := s.weekday[t.Weekday()]
+	switch {
+	case s.anyDay && s.anyWeek:
+		return true
+	case s.anyDay:
+		return weekMatch
+	case s.anyWeek:
+		return dayMatch
+	default:
+		return dayMatch || weekMatch
+	}
+}
+
+func parseField(field string, min int, max int, set []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		if part == "" {
+			return fmt.Errorf("empty list item in '%s'", field)
+		}
+
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rangePart = part[:idx]
+			parsedStep, err := strconv.Atoi(part[idx+1:])
+			if err != nil || parsedStep <= 0 {
+				return fmt.Errorf("invalid step in '%s'", part)
+			}
+			step = parsedStep
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = parseValue(bounds[0], min, max); err != nil {
+				return err
+			}
+			if end, err = parseValue(bounds[1], min, max); err != nil {
+				return err
+			}
+			if start > end {
+				return fmt.Errorf("range '%s' is reversed", rangePart)
+			}
+		default:
+			value, err := parseValue(rangePart, min, max)
+			if err != nil {
+				return err
+			}
+			start = value
+			// "5/10" means every 10 starting at 5
+			if !strings.Contains(part, "/") {
+				end = value
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+func parseValue(value string, min int, max int) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a number", value)
+	}
+	if parsed < min || parsed > max {
+		return 0, fmt.Errorf("%d is outside %d-%d", parsed, min, max)
+	}
+	return parsed, nil
+}
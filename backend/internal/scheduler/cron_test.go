@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	}
+	for _, expr := range invalid {
+		_, err := ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday
+	base := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"Every minute", "* * * * *", base, time.Date(2025, time.January, 15, 10, 31, 0, 0, time.UTC)},
+		{"Strictly after", "30 10 * * *", base, time.Date(2025, time.January, 16, 10, 30, 0, 0, time.UTC)},
+		{"Seconds are truncated", "31 10 * * *", base.Add(45 * time.Second), time.Date(2025, time.January, 15, 10, 31, 0, 0, time.UTC)},
+		{"Step", "*/20 * * * *", base, time.Date(2025, time.January, 15, 10, 40, 0, 0, time.UTC)},
+		{"Step from a start value", "5/20 * * * *", base, time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"Sunday 2 AM", "0 2 * * 0", base, time.Date(2025, time.January, 19, 2, 0, 0, 0, time.UTC)},
+		{"Seven is Sunday", "0 2 * * 7", base, time.Date(2025, time.January, 19, 2, 0, 0, 0, time.UTC)},
+		{"Weekday range", "0 9 * * 1-5", time.Date(2025, time.January, 17, 10, 0, 0, 0, time.UTC), time.Date(2025, time.January, 20, 9, 0, 0, 0, time.UTC)},
+		{"List", "0 8,20 * * *", base, time.Date(2025, time.January, 15, 20, 0, 0, 0, time.UTC)},
+		{"Day of month", "0 0 1 * *", base, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"Day of month or weekday", "0 0 1 * 5", base, time.Date(2025, time.January, 17, 0, 0, 0, 0, time.UTC)},
+		{"Leap day", "0 0 29 2 *", base, time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"Weekly descriptor", "@weekly", base, time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"Daily descriptor", "@daily", base, time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(tt.after))
+		})
+	}
+}
+
+func TestScheduleNextUsesLocation(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	schedule, err := ParseSchedule("0 2 * * *")
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2025, time.January, 15, 1, 0, 0, 0, location))
+	assert.Equal(t, time.Date(2025, time.January, 15, 2, 0, 0, 0, location), next)
+}
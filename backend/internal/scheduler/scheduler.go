@@ -0,0 +1,337 @@
+package scheduler
+
+import (
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// pollInterval is how often the scheduler checks for due jobs
+	pollInterval = 30 * time.Second
+	// lockTTL is how long a lock lives without renewal
+	lockTTL = 5 * time.Minute
+)
+
+// lockRenewInterval is how often running jobs renew their lock; a var so tests can shorten it
+var lockRenewInterval = lockTTL / 3
+
+// Job is a named unit of background work run on a cron schedule
+type Job struct {
+	Name     string
+	Schedule string
+	Timeout  time.Duration // Optional limit on a single run
+	// DueOnCreate runs the job as soon as its state is first stored, e.g. on a fresh deploy,
+	// instead of waiting for its first scheduled time
+	DueOnCreate bool
+	Run         func(ctx context.Context) error
+}
+
+// JobStatus is a job's persisted state as shown to admins
+type JobStatus struct {
+	domain.JobState
+	Running bool `json:"running"`
+}
+
+type registeredJob struct {
+	Job
+	schedule *Schedule
+}
+
+// Scheduler runs registered jobs on their schedules. Job state lives in the
+// job repository, so runs missed while every replica was down are caught up
+// once on start, and the repository lock lets only one replica run a job.
+type Scheduler struct {
+	repo     repository.JobRepository
+	location *time.Location
+	owner    string // Identifies this replica in job locks
+
+	mu      sync.Mutex
+	jobs    map[string]*registeredJob
+	order   []string
+	running map[string]bool
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+}
+
+// New creates a scheduler that evaluates schedules in the given location
+func New(repo repository.JobRepository, location *time.Location) *Scheduler {
+	if location == nil {
+		location = time.Local
+	}
+	return &Scheduler{
+		repo:     repo,
+		location: location,
+		owner:    newOwnerID(),
+		jobs:     make(map[string]*registeredJob),
+		running:  make(map[string]bool),
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job needs a name and a run function")
+	}
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job '%s': %w", job.Name, err)
+	}
+	if schedule.Next(time.Now().In(s.location)).IsZero() {
+		return fmt.Errorf("job '%s': schedule '%s' never runs", job.Name, job.Schedule)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job '%s' is already registered", job.Name)
+	}
+	s.jobs[job.Name] = &registeredJob{Job: job, schedule: schedule}
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Start persists the state of every job and begins polling for due jobs until ctx is cancelled or Stop is called
+func (s *Scheduler) Start(ctx context.Context) error {
+	now := time.Now().In(s.location)
+	for _, name := range s.order {
+		job := s.jobs[name]
+		firstRun := job.schedule.Next(now)
+		if job.DueOnCreate {
+			firstRun = now
+		}
+		state, err := s.repo.EnsureJob(ctx, job.Name, job.Schedule, firstRun)
+		if err != nil {
+			return fmt.Errorf("failed to initialize job '%s': %w", job.Name, err)
+		}
+
+		if state.Schedule != job.Schedule {
+			// The schedule changed since the last deploy; reschedule from now
+			next := job.schedule.Next(now)
+			if err := s.repo.SetSchedule(ctx, job.Name, job.Schedule, next); err != nil {
+				return fmt.Errorf("failed to update schedule of job '%s': %w", job.Name, err)
+			}
+			log.Printf("INFO: Job '%s' schedule changed from '%s' to '%s', next run at %s",
+				job.Name, state.Schedule, job.Schedule, next.Format(time.RFC3339))
+			continue
+		}
+
+		if state.NextRunAt.Before(now) {
+			log.Printf("INFO: Job '%s' missed its run at %s, catching up", job.Name, state.NextRunAt.Format(time.RFC3339))
+		} else {
+			log.Printf("INFO: Job '%s' (%s) next run at %s", job.Name, job.Schedule, state.NextRunAt.Format(time.RFC3339))
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		s.runDueJobs(runCtx)
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				s.runDueJobs(runCtx)
+			}
+		}
+	}()
+
+	log.Printf("INFO: Job scheduler started with %d jobs (replica %s)", len(s.order), s.owner)
+	return nil
+}
+
+// Stop cancels running jobs and waits for them to return or for ctx to expire
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Printf("INFO: Job scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for jobs to stop: %w", ctx.Err())
+	}
+}
+
+// Jobs returns the persisted state of every registered job
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobStatus, error) {
+	states, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statuses := []JobStatus{}
+	for _, state := range states {
+		if _, registered := s.jobs[state.Name]; !registered {
+			continue
+		}
+		statuses = append(statuses, JobStatus{JobState: *state, Running: state.IsRunning(now)})
+	}
+	return statuses, nil
+}
+
+// Trigger makes a job due now; whichever replica polls next runs it
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	if _, registered := s.jobs[name]; !registered {
+		return repository.ErrJobNotFound
+	}
+	if err := s.repo.SetNextRun(ctx, name, time.Now()); err != nil {
+		return err
+	}
+
+	log.Printf("INFO: Job '%s' triggered manually", name)
+	return nil
+}
+
+// runDueJobs starts every job whose lock this replica can take
+func (s *Scheduler) runDueJobs(ctx context.Context) {
+	for _, name := range s.order {
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.mu.Lock()
+		busy := s.running[name]
+		s.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		lockedAt := time.Now()
+		acquired, err := s.repo.AcquireLock(ctx, name, s.owner, lockedAt, lockTTL)
+		if err != nil || !acquired {
+			continue
+		}
+
+		s.mu.Lock()
+		s.running[name] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func(job *registeredJob) {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.running, job.Name)
+				s.mu.Unlock()
+			}()
+			s.runJob(ctx, job, lockedAt)
+		}(s.jobs[name])
+	}
+}
+
+// runJob runs a job while renewing its lock, then records the outcome and the next run.
+// The run is cancelled if the lock is lost or may expire before it can be renewed, since
+// another replica can then take the lock and start the job too.
+// The run counts as started when its lock was taken, so a trigger arriving after that is kept.
+func (s *Scheduler) runJob(ctx context.Context, job *registeredJob, startedAt time.Time) {
+	log.Printf("INFO: Running job '%s'", job.Name)
+
+	var jobCtx context.Context
+	var cancel context.CancelFunc
+	if job.Timeout > 0 {
+		jobCtx, cancel = context.WithTimeout(ctx, job.Timeout)
+	} else {
+		jobCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	// Keep the lock alive for long runs such as LLM plan generation
+	heartbeatDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lockRenewInterval)
+		defer ticker.Stop()
+		lockedUntil := startedAt.Add(lockTTL)
+		for {
+			select {
+			case <-heartbeatDone:
+				return
+			case <-ticker.C:
+				until := time.Now().Add(lockTTL)
+				err := s.repo.RenewLock(context.Background(), job.Name, s.owner, until)
+				if err == nil {
+					lockedUntil = until
+					continue
+				}
+				log.Printf("WARN: Could not renew lock of job '%s': %v", job.Name, err)
+				if errors.Is(err, repository.ErrLockLost) || time.Now().Add(lockRenewInterval).After(lockedUntil) {
+					log.Printf("WARN: Lost lock of job '%s', cancelling the run", job.Name)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	runErr := runSafely(jobCtx, job.Run)
+	close(heartbeatDone)
+
+	duration := time.Since(startedAt)
+	if runErr != nil {
+		log.Printf("ERROR: Job '%s' failed after %s: %v", job.Name, duration.Round(time.Millisecond), runErr)
+	} else {
+		log.Printf("INFO: Job '%s' finished in %s", job.Name, duration.Round(time.Millisecond))
+	}
+
+	// Schedule from now so a long catch-up doesn't queue a burst of runs.
+	// A run cut short by shutdown stays due so the next replica to start retries it.
+	// Use a fresh context: the outcome must be recorded even during shutdown.
+	next := job.schedule.Next(time.Now().In(s.location))
+	if runErr != nil && ctx.Err() != nil {
+		next = time.Now()
+		runErr = fmt.Errorf("interrupted by shutdown: %w", runErr)
+	}
+	run := repository.JobRun{StartedAt: startedAt, Duration: duration, Err: runErr, NextRunAt: next}
+	if err := s.repo.CompleteRun(context.Background(), job.Name, s.owner, run); err != nil {
+		log.Printf("ERROR: Failed to record run of job '%s': %v", job.Name, err)
+		return
+	}
+	log.Printf("INFO: Job '%s' next run at %s", job.Name, next.Format(time.RFC3339))
+}
+
+// runSafely turns a panicking job into an error so the scheduler keeps running
+func runSafely(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// newOwnerID identifies this process in job locks
+func newOwnerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
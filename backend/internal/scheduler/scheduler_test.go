@@ -0,0 +1,316 @@
+package scheduler
+
+import (
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJobRepository mirrors the atomic behaviour of the Mongo repository in memory
+type fakeJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]*domain.JobState
+}
+
+func newFakeJobRepository() *fakeJobRepository {
+	return &fakeJobRepository{jobs: make(map[string]*domain.JobState)}
+}
+
+func (r *fakeJobRepository) EnsureJob(ctx context.Context, name string, schedule string, nextRunAt time.Time) (*domain.JobState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jobs[name]; !ok {
+		r.jobs[name] = &domain.JobState{Name: name, Schedule: schedule, NextRunAt: nextRunAt}
+	}
+	state := *r.jobs[name]
+	return &state, nil
+}
+
+func (r *fakeJobRepository) List(ctx context.Context) ([]*domain.JobState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var states []*domain.JobState
+	for _, state := range r.jobs {
+		copied := *state
+		states = append(states, &copied)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states, nil
+}
+
+func (r *fakeJobRepository) SetSchedule(ctx context.Context, name string, schedule string, nextRunAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.jobs[name]
+	if !ok {
+		return repository.ErrJobNotFound
+	}
+	state.Schedule = schedule
+	state.NextRunAt = nextRunAt
+	return nil
+}
+
+func (r *fakeJobRepository) SetNextRun(ctx context.Context, name string, nextRunAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.jobs[name]
+	if !ok {
+		return repository.ErrJobNotFound
+	}
+	state.NextRunAt = nextRunAt
+	return nil
+}
+
+func (r *fakeJobRepository) AcquireLock(ctx context.Context, name string, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.jobs[name]
+	if !ok || state.NextRunAt.After(now) || state.IsRunning(now) {
+		return false, nil
+	}
+	state.LockOwner = owner
+	state.LockedUntil = now.Add(ttl)
+	return true, nil
+}
+
+func (r *fakeJobRepository) RenewLock(ctx context.Context, name string, owner string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.jobs[name]
+	if !ok || state.LockOwner != owner {
+		return repository.ErrLockLost
+	}
+	state.LockedUntil = until
+	return nil
+}
+
+func (r *fakeJobRepository) CompleteRun(ctx context.Context, name string, owner string, run repository.JobRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.jobs[name]
+	if !ok || state.LockOwner != owner {
+		return errors.New("lock was lost before the run completed")
+	}
+	state.LastRunAt = run.StartedAt
+	if !state.NextRunAt.After(run.StartedAt) {
+		state.NextRunAt = run.NextRunAt
+	}
+	state.RunCount++
+	state.LastError = ""
+	if run.Err != nil {
+		state.LastError = run.Err.Error()
+	} else {
+		state.LastSuccessAt = run.StartedAt
+	}
+	state.LockOwner = ""
+	state.LockedUntil = time.Time{}
+	return nil
+}
+
+func (r *fakeJobRepository) get(name string) domain.JobState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.jobs[name]
+}
+
+func TestMissedRunIsCaughtUpOnce(t *testing.T) {
+	repo := newFakeJobRepository()
+	_, err := repo.EnsureJob(context.Background(), "plans", "0 2 * * *", time.Now().Add(-72*time.Hour))
+	require.NoError(t, err)
+
+	var runs int32
+	s := New(repo, time.UTC)
+	require.NoError(t, s.Register(Job{Name: "plans", Schedule: "0 2 * * *", Run: func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}}))
+
+	require.NoError(t, s.Start(context.Background()))
+	require.Eventually(t, func() bool { return repo.get("plans").RunCount == 1 }, time.Second, 10*time.Millisecond)
+
+	s.runDueJobs(context.Background())
+	require.NoError(t, s.Stop(context.Background()))
+
+	state := repo.get("plans")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+	assert.True(t, state.NextRunAt.After(time.Now()))
+	assert.False(t, state.LastSuccessAt.IsZero())
+	assert.Empty(t, state.LockOwner)
+}
+
+func TestOnlyOneReplicaRunsADueJob(t *testing.T) {
+	repo := newFakeJobRepository()
+	_, err := repo.EnsureJob(context.Background(), "plans", "@daily", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	var runs int32
+	job := Job{Name: "plans", Schedule: "@daily", Run: func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return nil
+	}}
+
+	first, second := New(repo, time.UTC), New(repo, time.UTC)
+	require.NoError(t, first.Register(job))
+	require.NoError(t, second.Register(job))
+
+	first.runDueJobs(context.Background())
+	second.runDueJobs(context.Background())
+	close(release)
+	first.wg.Wait()
+	second.wg.Wait()
+
+	// Once the run is recorded the job is no longer due for anyone
+	second.runDueJobs(context.Background())
+	second.wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+}
+
+func TestTriggerDuringRunIsKept(t *testing.T) {
+	repo := newFakeJobRepository()
+	_, err := repo.EnsureJob(context.Background(), "plans", "@daily", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	var runs int32
+	s := New(repo, time.UTC)
+	require.NoError(t, s.Register(Job{Name: "plans", Schedule: "@daily", Run: func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		started <- struct{}{}
+		<-release
+		return nil
+	}}))
+
+	s.runDueJobs(context.Background())
+	<-started
+	require.NoError(t, s.Trigger(context.Background(), "plans"))
+	release <- struct{}{}
+	s.wg.Wait()
+
+	// The trigger still stands after the run it arrived during
+	assert.False(t, repo.get("plans").NextRunAt.After(time.Now()))
+
+	s.runDueJobs(context.Background())
+	<-started
+	close(release)
+	s.wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+	assert.True(t, repo.get("plans").NextRunAt.After(time.Now()))
+}
+
+func TestFailedRunIsRecorded(t *testing.T) {
+	repo := newFakeJobRepository()
+	_, err := repo.EnsureJob(context.Background(), "plans", "@daily", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	s := New(repo, time.UTC)
+	require.NoError(t, s.Register(Job{Name: "plans", Schedule: "@daily", Run: func(ctx context.Context) error {
+		panic("boom")
+	}}))
+
+	s.runDueJobs(context.Background())
+	s.wg.Wait()
+
+	state := repo.get("plans")
+	assert.Contains(t, state.LastError, "boom")
+	assert.True(t, state.LastSuccessAt.IsZero())
+	assert.True(t, state.NextRunAt.After(time.Now()))
+}
+
+func TestShutdownCancelsRunAndKeepsItDue(t *testing.T) {
+	repo := newFakeJobRepository()
+	_, err := repo.EnsureJob(context.Background(), "plans", "@daily", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	s := New(repo, time.UTC)
+	require.NoError(t, s.Register(Job{Name: "plans", Schedule: "@daily", Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+
+	require.NoError(t, s.Start(context.Background()))
+	<-started
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Stop(stopCtx))
+
+	state := repo.get("plans")
+	assert.Contains(t, state.LastError, "interrupted by shutdown")
+	assert.False(t, state.NextRunAt.After(time.Now()))
+}
+
+func TestRegisterRejectsInvalidJobs(t *testing.T) {
+	s := New(newFakeJobRepository(), time.UTC)
+	run := func(ctx context.Context) error { return nil }
+
+	assert.Error(t, s.Register(Job{Name: "", Schedule: "@daily", Run: run}))
+	assert.Error(t, s.Register(Job{Name: "bad", Schedule: "not cron", Run: run}))
+	assert.Error(t, s.Register(Job{Name: "never", Schedule: "0 0 30 2 *", Run: run}))
+	require.NoError(t, s.Register(Job{Name: "ok", Schedule: "@daily", Run: run}))
+	assert.Error(t, s.Register(Job{Name: "ok", Schedule: "@daily", Run: run}))
+}
+
+func TestDueOnCreateRunsOnFirstStart(t *testing.T) {
+	repo := newFakeJobRepository()
+	s := New(repo, time.UTC)
+	require.NoError(t, s.Register(Job{Name: "plans", Schedule: "@daily", DueOnCreate: true, Run: func(ctx context.Context) error {
+		return nil
+	}}))
+
+	require.NoError(t, s.Start(context.Background()))
+	require.Eventually(t, func() bool { return repo.get("plans").RunCount == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, s.Stop(context.Background()))
+
+	assert.True(t, repo.get("plans").NextRunAt.After(time.Now()))
+}
+
+func TestLostLockCancelsRun(t *testing.T) {
+	defer func(interval time.Duration) { lockRenewInterval = interval }(lockRenewInterval)
+	lockRenewInterval = 10 * time.Millisecond
+
+	repo := newFakeJobRepository()
+	_, err := repo.EnsureJob(context.Background(), "plans", "@daily", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	s := New(repo, time.UTC)
+	require.NoError(t, s.Register(Job{Name: "plans", Schedule: "@daily", Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}}))
+
+	s.runDueJobs(context.Background())
+	<-started
+
+	// Another replica takes over the lock
+	repo.mu.Lock()
+	repo.jobs["plans"].LockOwner = "other"
+	repo.mu.Unlock()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("run was not cancelled after its lock was lost")
+	}
+	s.wg.Wait()
+	assert.Equal(t, "other", repo.get("plans").LockOwner)
+}
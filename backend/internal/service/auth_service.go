@@ -13,6 +13,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -30,20 +31,22 @@ type GoogleUserInfo struct {
 
 // AuthService handles authentication logic (OAuth, JWT)
 type AuthService struct {
-	googleOAuthConfig *oauth2.Config
-	userRepo          repository.UserRepository
-	jwtSecret         []byte
+	googleOAuthConfig    *oauth2.Config
+	userRepo             repository.UserRepository
+	jwtSecret            []byte
+	bootstrapAdminEmails []string // Users signing in with these emails are granted the admin role
 }
 
 // NewAuthService creates a new AuthService
-func NewAuthService(googleOAuthConfig *oauth2.Config, userRepo repository.UserRepository, jwtSecret string) *AuthService {
+func NewAuthService(googleOAuthConfig *oauth2.Config, userRepo repository.UserRepository, jwtSecret string, bootstrapAdminEmails []string) *AuthService {
 	if len(jwtSecret) == 0 {
 		log.Fatal("FATAL: JWT Secret cannot be empty in AuthService")
 	}
 	return &AuthService{
-		googleOAuthConfig: googleOAuthConfig,
-		userRepo:          userRepo,
-		jwtSecret:         []byte(jwtSecret),
+		googleOAuthConfig:    googleOAuthConfig,
+		userRepo:             userRepo,
+		jwtSecret:            []byte(jwtSecret),
+		bootstrapAdminEmails: bootstrapAdminEmails,
 	}
 }
 
@@ -109,6 +112,7 @@ func (s *AuthService) HandleGoogleCallback(ctx context.Context, code string) (*d
 			Email:    userInfo.Email,
 			Name:     userInfo.Name,
 			Picture:  userInfo.Picture,
+			Roles:    []string{domain.RoleUser},
 			// CreatedAt/UpdatedAt set by repository
 		}
 		user, err = s.userRepo.Create(ctx, newUser)
@@ -124,6 +128,19 @@ func (s *AuthService) HandleGoogleCallback(ctx context.Context, code string) (*d
 		// if user.Name != userInfo.Name || user.Picture != userInfo.Picture { ... s.userRepo.Update(ctx, user) ... }
 	}
 
+	// Grant admin to configured bootstrap emails, so a fresh deployment has someone
+	// who can assign roles. The role is persisted, so removing the email later
+	// doesn't revoke it.
+	if s.isBootstrapAdmin(user.Email) && !user.HasRole(domain.RoleAdmin) {
+		roles := append(append([]string{}, user.EffectiveRoles()...), domain.RoleAdmin)
+		if err := s.userRepo.UpdateRoles(ctx, user.ID, roles); err != nil {
+			log.Printf("ERROR: Failed to grant bootstrap admin role to %s: %v", user.Email, err)
+		} else {
+			user.Roles = roles
+			log.Printf("INFO: Granted bootstrap admin role to %s (ID=%s)", user.Email, user.ID)
+		}
+	}
+
 	// 4. Generate JWT for our application
 	appToken, err := s.generateJWT(user)
 	if err != nil {
@@ -134,6 +151,16 @@ func (s *AuthService) HandleGoogleCallback(ctx context.Context, code string) (*d
 	return user, appToken, nil
 }
 
+// isBootstrapAdmin reports whether an email is configured to receive the admin role
+func (s *AuthService) isBootstrapAdmin(email string) bool {
+	for _, adminEmail := range s.bootstrapAdminEmails {
+		if email != "" && strings.EqualFold(adminEmail, email) {
+			return true
+		}
+	}
+	return false
+}
+
 // fetchGoogleUserInfo uses the Google token to get user details
 func (s *AuthService) fetchGoogleUserInfo(ctx context.Context, token *oauth2.Token) (*GoogleUserInfo, error) {
 	client := s.googleOAuthConfig.Client(ctx, token)
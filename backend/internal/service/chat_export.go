@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+)
+
+// exportTimeLayout formats timestamps in Markdown exports
+const exportTimeLayout = "2006-01-02 15:04 MST"
+
+// ConversationExport is a conversation prepared for keeping outside the app, with the branch last shown
+type ConversationExport struct {
+	ID         string               `json:"id"`
+	Title      string               `json:"title"`
+	Reference  string               `json:"reference,omitempty"`
+	VerseText  string               `json:"verse_text,omitempty"`
+	PlanID     string               `json:"plan_id,omitempty"`
+	DayNumber  int                  `json:"day,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+	ExportedAt time.Time            `json:"exported_at"`
+	Messages   []domain.ChatMessage `json:"messages"`
+}
+
+// ExportConversation prepares one of the user's conversations for export, with the text of its passage
+func (s *chatService) ExportConversation(ctx context.Context, userID string, conversationID string) (ConversationExport, error) {
+	conversation, err := s.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return ConversationExport{}, err
+	}
+	return s.toExport(ctx, conversation), nil
+}
+
+// ExportAllConversations prepares every one of the user's conversations for export, most recently updated first
+func (s *chatService) ExportAllConversations(ctx context.Context, userID string) ([]ConversationExport, error) {
+	summaries, err := s.conversationRepo.ListByUser(ctx, userID, repository.ConversationFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	exports := make([]ConversationExport, 0, len(summaries))
+	for _, summary := range summaries {
+		conversation, err := s.conversationRepo.FindByID(ctx, summary.ID.String())
+		if err != nil {
+			return nil, fmt.Errorf("error finding conversation: %w", err)
+		}
+		if conversation == nil {
+			continue // Deleted since it was listed
+		}
+		exports = append(exports, s.toExport(ctx, conversation))
+	}
+	return exports, nil
+}
+
+// toExport copies a conversation into an export. A passage whose text can't be found is
+// exported by reference only.
+func (s *chatService) toExport(ctx context.Context, conversation *domain.Conversation) ConversationExport {
+	export := ConversationExport{
+		ID:         conversation.ID.String(),
+		Title:      conversation.Title,
+		Reference:  conversation.Reference,
+		PlanID:     conversation.PlanID,
+		DayNumber:  conversation.DayNumber,
+		CreatedAt:  conversation.CreatedAt,
+		UpdatedAt:  conversation.UpdatedAt,
+		ExportedAt: time.Now(),
+		Messages:   conversation.Branch(),
+	}
+	if export.Messages == nil {
+		export.Messages = []domain.ChatMessage{}
+	}
+	if conversation.Reference != "" {
+		text, err := s.verseService.GetVerseContent(ctx, conversation.Reference)
+		if err != nil {
+			log.Printf("WARN: Exporting conversation %s without the text of %s: %v", conversation.ID, conversation.Reference, err)
+		} else {
+			export.VerseText = text
+		}
+	}
+	return export
+}
+
+// Markdown renders the export as a Markdown document for a journal
+func (e ConversationExport) Markdown() string {
+	var md strings.Builder
+	fmt.Fprintf(&md, "# %s\n\n", e.Title)
+
+	if e.Reference != "" {
+		fmt.Fprintf(&md, "**Passage:** %s\n\n", e.Reference)
+		if e.VerseText != "" {
+			fmt.Fprintf(&md, "> %s\n\n", strings.ReplaceAll(e.VerseText, "\n", "\n> "))
+		}
+	}
+	fmt.Fprintf(&md, "_Started %s, last updated %s_\n",
+		e.CreatedAt.UTC().Format(exportTimeLayout), e.UpdatedAt.UTC().Format(exportTimeLayout))
+
+	for _, message := range e.Messages {
+		speaker := "You"
+		if message.Role == "assistant" {
+			speaker = "Bible helper"
+		}
+		fmt.Fprintf(&md, "\n---\n\n**%s** (%s):\n\n%s\n", speaker, message.CreatedAt.UTC().Format(exportTimeLayout), message.Content)
+		if len(message.Citations) > 0 {
+			references := make([]string, len(message.Citations))
+			for i, citation := range message.Citations {
+				references[i] = citation.Reference
+			}
+			fmt.Fprintf(&md, "\n_Sources: %s_\n", strings.Join(references, "; "))
+		}
+	}
+	return md.String()
+}
+
+var fileNameUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// FileName names the export's file, e.g. "2026-10-18-john-3-16-why-did-jesus-say-this.md"
+func (e ConversationExport) FileName(extension string) string {
+	slug := strings.Trim(fileNameUnsafe.ReplaceAllString(strings.ToLower(e.Title), "-"), "-")
+	if len(slug) > 60 {
+		slug = strings.TrimRight(slug[:60], "-")
+	}
+	if slug == "" {
+		slug = "conversation"
+	}
+	return fmt.Sprintf("%s-%s.%s", e.CreatedAt.UTC().Format("2006-01-02"), slug, extension)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares output with testdata/name, rewriting the file with -update
+func assertGolden(t *testing.T, name string, output []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(path, output, 0o644))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(output))
+}
+
+func TestConversationExportRendering(t *testing.T) {
+	started := time.Date(2026, 10, 18, 7, 30, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return started.Add(time.Duration(minutes) * time.Minute) }
+
+	// The first answer was regenerated, and the follow-up asked on the regenerated answer is shown
+	conversation := &domain.Conversation{
+		ID:        uuid.MustParse("7d4f8a52-3c1e-4b8a-9f3e-2a6b1c0d9e47"),
+		Title:     "John 3:16: Why did Jesus say this?",
+		Reference: "John 3:16",
+		PlanID:    "plan-1",
+		DayNumber: 3,
+		CreatedAt: started,
+		UpdatedAt: at(6),
+		Messages: []domain.ChatMessage{
+			{ID: "q1", Role: "user", Content: "Why did Jesus say this?", CreatedAt: at(0)},
+			{ID: "a1", ParentID: "q1", Role: "assistant", Content: "To explain salvation.", CreatedAt: at(1)},
+			{ID: "a2", ParentID: "q1", Role: "assistant", Content: "He was answering Nicodemus, who came to him at night.",
+				Citations: []domain.Citation{{Reference: "John 3:1-2"}, {Reference: "John 3:16"}}, CreatedAt: at(2)},
+			{ID: "q2", ParentID: "a2", Role: "user", Content: "Who was Nicodemus?", CreatedAt: at(5)},
+			{ID: "a3", ParentID: "q2", Role: "assistant", Content: "A Pharisee and a ruler of the Jews.\n\nHe later helped bury Jesus.", CreatedAt: at(6)},
+		},
+		LeafID: "a3",
+	}
+	chat := &chatService{verseService: fakeVerseService{}}
+
+	export := chat.toExport(context.Background(), conversation)
+	export.ExportedAt = at(60)
+	shown := []string{}
+	for _, message := range export.Messages {
+		shown = append(shown, message.ID)
+	}
+	assert.Equal(t, []string{"q1", "a2", "q2", "a3"}, shown, "only the branch last shown is exported")
+	assert.Equal(t, "2026-10-18-john-3-16-why-did-jesus-say-this.md", export.FileName("md"))
+
+	t.Run("Markdown", func(t *testing.T) {
+		assertGolden(t, "conversation_export.md", []byte(export.Markdown()))
+	})
+	t.Run("JSON", func(t *testing.T) {
+		body, err := json.MarshalIndent(export, "", "  ")
+		require.NoError(t, err)
+		assertGolden(t, "conversation_export.json", body)
+	})
+}
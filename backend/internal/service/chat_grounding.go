@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/tokenbudget"
+	"bibleapp/backend/internal/util"
+)
+
+const (
+	// groundingContextVerses is how many verses either side of the conversation's passage are sent with it
+	groundingContextVerses = 3
+	// maxQuestionReferences caps how many references mentioned in a question are looked up
+	maxQuestionReferences = 3
+	// groundingMaxTokens caps the passage text sent with a question
+	groundingMaxTokens = 1500
+	// maxCitations caps the citations returned with an answer
+	maxCitations = 5
+)
+
+// groundingInstructions asks the model to answer from the retrieved passages and cite them
+const groundingInstructions = " Base your answer on the Bible passages provided rather than on memory, and quote them exactly." +
+	" Cite every verse you quote or refer to in square brackets, for example [John 3:16]." +
+	" If the passages don't cover the question, say so briefly instead of quoting from memory."
+
+// retrieveSources looks up the passages an answer is grounded in: the conversation's passage
+// with a few verses of context either side, then the references the question mentions.
+// Passages that can't be found are left out; the text is capped at groundingMaxTokens.
+func (s *chatService) retrieveSources(ctx context.Context, reference string, question string) []domain.Citation {
+	var candidates []string
+	if reference != "" {
+		if expanded := util.ExpandReference(reference, groundingContextVerses, groundingContextVerses); expanded != "" {
+			candidates = append(candidates, expanded)
+		} else {
+			candidates = append(candidates, reference)
+		}
+	}
+	mentioned := util.ExtractReferences(question)
+	if len(mentioned) > maxQuestionReferences {
+		mentioned = mentioned[:maxQuestionReferences]
+	}
+	candidates = append(candidates, mentioned...)
+
+	var sources []domain.Citation
+	budget := groundingMaxTokens
+	for _, candidate := range candidates {
+		if budget <= 0 {
+			break
+		}
+		if containsReference(sources, candidate) {
+			continue
+		}
+		text, err := s.verseService.GetVerseContent(ctx, candidate)
+		if err != nil || strings.TrimSpace(text) == "" {
+			log.Printf("WARN: No verse text to ground chat answer in for %s: %v", candidate, err)
+			continue
+		}
+		text = tokenbudget.Truncate(text, budget)
+		budget -= tokenbudget.EstimateTokens(text)
+		sources = append(sources, domain.Citation{Reference: candidate, Text: text})
+	}
+	return sources
+}
+
+// groundingMessage formats the retrieved passages for the prompt
+func groundingMessage(sources []domain.Citation) string {
+	var message strings.Builder
+	message.WriteString("Bible passages for this question:")
+	for _, source := range sources {
+		fmt.Fprintf(&message, "\n\n%s\n%s", source.Reference, source.Text)
+	}
+	return message.String()
+}
+
+// citationsFor returns the references an answer cites, each with its verse text. References
+// that don't resolve to any verse are dropped, since the answer made them up. An answer that
+// cites nothing is credited to the passages it was grounded in.
+func (s *chatService) citationsFor(ctx context.Context, answer string, sources []domain.Citation) []domain.Citation {
+	cited := util.ExtractReferences(answer)
+	if len(cited) == 0 {
+		return sources
+	}
+
+	var citations []domain.Citation
+	for _, reference := range cited {
+		if len(citations) == maxCitations {
+			break
+		}
+		if text := sourceText(sources, reference); text != "" {
+			citations = append(citations, domain.Citation{Reference: reference, Text: text})
+			continue
+		}
+		text, err := s.verseService.GetVerseContent(ctx, reference)
+		if err != nil || strings.TrimSpace(text) == "" {
+			log.Printf("WARN: Dropping citation %s that doesn't resolve to a verse: %v", reference, err)
+			continue
+		}
+		if !containsReference(sources, reference) {
+			log.Printf("DEBUG: Answer cites %s from outside the passages it was given", reference)
+		}
+		citations = append(citations, domain.Citation{Reference: reference, Text: text})
+	}
+	return citations
+}
+
+// containsReference reports whether one of the sources covers the reference
+func containsReference(sources []domain.Citation, reference string) bool {
+	for _, source := range sources {
+		if util.ReferenceContains(source.Reference, reference) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceText returns the text of a source with exactly this reference
+func sourceText(sources []domain.Citation, reference string) string {
+	for _, source := range sources {
+		if strings.EqualFold(source.Reference, reference) {
+			return source.Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+)
+
+// chatUsageRetention keeps a day's count for two days after it ends. Time zones are up to 26 hours
+// apart, so once a changed zone takes over, a date counted in the old zone still has its count.
+const chatUsageRetention = 48 * time.Hour
+
+// chatQuota is a user's chat allowance for their current day
+type chatQuota struct {
+	day       string    // YYYY-MM-DD in the user's time zone
+	limit     int       // Requests allowed; 0 means unlimited
+	expiresAt time.Time // When the day's count may be dropped
+}
+
+// quotaFor works out the user's day and limit. A user who can't be looked up gets the
+// default limit on the UTC calendar.
+func (s *chatService) quotaFor(ctx context.Context, userID string, now time.Time) chatQuota {
+	location := time.UTC
+	var user *domain.User
+	if s.userRepo != nil {
+		found, err := s.userRepo.FindByID(ctx, userID)
+		if err != nil {
+			log.Printf("WARN: Failed to look up chat limit of user %s, using the default: %v", userID, err)
+		}
+		user = found
+	}
+	if user != nil {
+		if timezone := user.TimezoneAt(now); timezone != "" {
+			if loaded, err := time.LoadLocation(timezone); err == nil {
+				location = loaded
+			} else {
+				log.Printf("WARN: User %s has unknown time zone '%s', using UTC", userID, timezone)
+			}
+		}
+	}
+
+	return chatQuota{
+		day:       now.In(location).Format("2006-01-02"),
+		limit:     chatLimitFor(user, s.cfg.ChatRateLimitPerDay, s.cfg.ChatRateLimitPerRole),
+		expiresAt: nextMidnight(now, location).Add(chatUsageRetention),
+	}
+}
+
+// loadTimezone loads an IANA time zone, falling back to UTC for an empty or unknown name
+func loadTimezone(name string) *time.Location {
+	if location, err := time.LoadLocation(name); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// nextMidnight returns the first midnight after t in a time zone
+func nextMidnight(t time.Time, location *time.Location) time.Time {
+	local := t.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location).AddDate(0, 0, 1)
+}
+
+// chatLimitFor picks a user's daily limit: their own override, else the most generous limit
+// configured for one of their roles, else the default. 0 means unlimited.
+func chatLimitFor(user *domain.User, defaultLimit int, roleLimits map[string]int) int {
+	if user == nil {
+		return defaultLimit
+	}
+	if user.ChatLimit != nil {
+		return *user.ChatLimit
+	}
+
+	limit, found := 0, false
+	for _, role := range user.EffectiveRoles() {
+		roleLimit, ok := roleLimits[role]
+		if !ok {
+			continue
+		}
+		if !found || roleLimit == 0 || (limit != 0 && roleLimit > limit) {
+			limit = roleLimit
+		}
+		found = true
+	}
+	if !found {
+		return defaultLimit
+	}
+	return limit
+}
+
+// reserveChat counts a request against the user's limit in one atomic step, returning the day
+// it was counted on. An empty day means nothing was counted: rate limiting is off, or the
+// count couldn't be stored, which lets the request through rather than failing the chat.
+func (s *chatService) reserveChat(ctx context.Context, userID string) (string, error) {
+	if !s.cfg.ChatRateLimitEnabled || userID == "" {
+		return "", nil
+	}
+
+	quota := s.quotaFor(ctx, userID, time.Now())
+	count, err := s.chatUsageRepo.Reserve(ctx, userID, quota.day, quota.limit, quota.expiresAt)
+	if err != nil {
+		if errors.Is(err, repository.ErrChatLimitReached) {
+			return "", ErrRateLimitExceeded{}
+		}
+		log.Printf("WARN: Failed to check chat rate limit: %v", err)
+		return "", nil
+	}
+
+	if quota.limit > 0 {
+		log.Printf("INFO: User %s has used %d/%d chat requests on %s", userID, count, quota.limit, quota.day)
+	}
+	return quota.day, nil
+}
+
+// releaseChat gives back a reserved request that wasn't answered
+func (s *chatService) releaseChat(ctx context.Context, userID string, day string) {
+	if day == "" {
+		return
+	}
+	if err := s.chatUsageRepo.Release(ctx, userID, day); err != nil {
+		log.Printf("WARN: Failed to release chat request of user %s: %v", userID, err)
+	}
+}
+
+// releaseUnanswered releases a turn's reservation when answering it failed before the LLM produced
+// any output. Output that was generated counts, even when the client went away mid-stream.
+func (s *chatService) releaseUnanswered(ctx context.Context, userID string, turn *chatTurn, err *error) {
+	if *err != nil && !turn.generated {
+		s.releaseChat(ctx, userID, turn.usageDay)
+	}
+}
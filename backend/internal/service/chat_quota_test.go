@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextMidnight(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		location *time.Location
+		expected time.Time
+	}{
+		{
+			name:     "Ordinary day",
+			t:        time.Date(2024, 6, 1, 15, 30, 0, 0, time.UTC),
+			location: time.UTC,
+			expected: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "At midnight the next one is a day away",
+			t:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			location: time.UTC,
+			expected: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Date in the user's zone, not in UTC",
+			t:        time.Date(2024, 6, 2, 2, 0, 0, 0, time.UTC), // 22:00 on June 1 in New York
+			location: newYork,
+			expected: time.Date(2024, 6, 2, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Day that loses an hour to daylight saving",
+			t:        time.Date(2024, 3, 10, 1, 30, 0, 0, newYork),
+			location: newYork,
+			expected: time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC), // Midnight EDT
+		},
+		{
+			name:     "Day that gains an hour when daylight saving ends",
+			t:        time.Date(2024, 11, 3, 1, 30, 0, 0, newYork),
+			location: newYork,
+			expected: time.Date(2024, 11, 4, 5, 0, 0, 0, time.UTC), // Midnight EST
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.True(t, tc.expected.Equal(nextMidnight(tc.t, tc.location)), "got %s", nextMidnight(tc.t, tc.location).UTC())
+		})
+	}
+}
+
+func TestChatLimitFor(t *testing.T) {
+	limit := func(n int) *int { return &n }
+	roleLimits := map[string]int{domain.RoleUser: 20, domain.RoleGuardian: 50, domain.RoleEditor: 0}
+
+	tests := []struct {
+		name     string
+		user     *domain.User
+		expected int
+	}{
+		{name: "Unknown user gets the default", user: nil, expected: 10},
+		{name: "Role limit replaces the default", user: &domain.User{}, expected: 20},
+		{name: "Role without a configured limit gets the default", user: &domain.User{Roles: []string{domain.RoleAdmin}}, expected: 10},
+		{name: "Most generous role wins", user: &domain.User{Roles: []string{domain.RoleUser, domain.RoleGuardian}}, expected: 50},
+		{name: "Unlimited role is the most generous", user: &domain.User{Roles: []string{domain.RoleGuardian, domain.RoleEditor, domain.RoleUser}}, expected: 0},
+		{name: "User override wins over the roles", user: &domain.User{Roles: []string{domain.RoleGuardian}, ChatLimit: limit(5)}, expected: 5},
+		{name: "User override of 0 is unlimited", user: &domain.User{ChatLimit: limit(0)}, expected: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, chatLimitFor(tc.user, 10, roleLimits))
+		})
+	}
+}
@@ -5,12 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"sync" // Import sync for mutex
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"bibleapp/backend/internal/config"
 	"bibleapp/backend/internal/domain"
 	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/metering"
+	"bibleapp/backend/internal/moderation"
 	"bibleapp/backend/internal/repository"
+	"bibleapp/backend/internal/tokenbudget"
+
+	"github.com/google/uuid"
 )
 
 // ErrRateLimitExceeded is returned when a user has exceeded their daily chat limit
@@ -20,172 +27,670 @@ func (e ErrRateLimitExceeded) Error() string {
 	return "rate limit exceeded"
 }
 
-// --- Conversation Store (In-Memory) ---
-// For simplicity, we'll use a single hardcoded key for the niece's conversation.
-// In a multi-user app, this key would be dynamic (e.g., user ID, session ID).
-const nieceConversationKey = "niece_conversation"
+// maxConversationTitleLength caps conversation titles, both generated and user-chosen
+const maxConversationTitleLength = 80
 
-var (
-	// Protect concurrent access to the history map
-	conversationHistory      = make(map[string][]llm.Message)
-	conversationHistoryMutex sync.RWMutex
-)
+// chatAnswerMaxTokens caps the length of an answer
+const chatAnswerMaxTokens = 300
+
+// summaryMaxTokens caps the length of a conversation summary
+const summaryMaxTokens = 300
+
+// summaryTimeout bounds summarizing a conversation after an answer, which runs on its own
+const summaryTimeout = time.Minute
+
+// streamScreenBytes is about how much of a streamed answer is held back before it is screened
+// and passed on. Text is passed on up to the end of a sentence.
+const streamScreenBytes = 200
+
+// streamScreenOverlap is how much of the text already passed on is screened again with the next
+// piece, so terms spanning the two are caught without screening the whole answer each time
+const streamScreenOverlap = 80
+
+// ChatQuestion is a question to answer, or an earlier one to answer again
+type ChatQuestion struct {
+	ConversationID string // Empty starts a new conversation
+	Text           string
+	EditOf         string // ID of an earlier question the text replaces, starting a new branch
+	Regenerate     bool   // Answer the last question of the branch being shown again, ignoring Text
+}
+
+// ChatReply is the assistant's answer and the conversation it belongs to
+type ChatReply struct {
+	ConversationID string
+	QuestionID     string // Message the question is stored as
+	MessageID      string // Message the answer is stored as, for rating it
+	Answer         string
+	Citations      []domain.Citation // Passages the answer cites
+}
 
 // --- Chat Service Interface Update ---
 type ChatService interface {
-	// GetResponse now implicitly uses stored history
-	GetResponse(ctx context.Context, verse domain.DailyVerse, question string, userID string) (string, error)
-	// New method to reset history
-	ResetChatHistory(ctx context.Context) error
+	// GetResponse answers a question within a user's conversation, starting a new one when it has no conversation ID
+	GetResponse(ctx context.Context, userID string, question ChatQuestion, verse domain.DailyVerse, audience domain.AudienceProfile) (ChatReply, error)
+	// StreamResponse is GetResponse with the answer passed to onDelta piece by piece as it is generated.
+	// Each piece is screened before it is passed on. onReset drops the text passed on so far when it
+	// turns out not to be the answer, such as text written before a tool call; the pieces passed on
+	// after the last reset make up the reply's answer. The conversation and usage are only updated
+	// once the whole answer has arrived.
+	StreamResponse(ctx context.Context, userID string, question ChatQuestion, verse domain.DailyVerse, audience domain.AudienceProfile, onDelta func(delta string) error, onReset func() error) (ChatReply, error)
+	// SwitchBranch shows the branch of one of the user's conversations that contains a message
+	SwitchBranch(ctx context.Context, userID string, conversationID string, messageID string) (*domain.Conversation, error)
+	// ResetChatHistory clears the messages of one of the user's conversations
+	ResetChatHistory(ctx context.Context, userID string, conversationID string) error
 	// Get current chat usage for a user
-	GetChatUsage(ctx context.Context, userID string) (int, int, error) // returns (current usage, limit, error)
+	GetChatUsage(ctx context.Context, userID string) (int, int, error) // returns (current usage, limit or 0 for unlimited, error)
+	// ListConversations lists the user's conversations without their messages
+	ListConversations(ctx context.Context, userID string, filter repository.ConversationFilter) ([]*domain.Conversation, error)
+	// GetConversation returns one of the user's conversations with its messages
+	GetConversation(ctx context.Context, userID string, conversationID string) (*domain.Conversation, error)
+	// RenameConversation changes the title of one of the user's conversations
+	RenameConversation(ctx context.Context, userID string, conversationID string, title string) (*domain.Conversation, error)
+	// DeleteConversation removes one of the user's conversations
+	DeleteConversation(ctx context.Context, userID string, conversationID string) error
+	// ExportConversation prepares one of the user's conversations for download
+	ExportConversation(ctx context.Context, userID string, conversationID string) (ConversationExport, error)
+	// ExportAllConversations prepares every one of the user's conversations for download
+	ExportAllConversations(ctx context.Context, userID string) ([]ConversationExport, error)
 }
 
 // --- Chat Service Implementation Update ---
 type chatService struct {
-	llmClient       llm.LLMClient
-	modelName       string
-	verseService    VerseService // Added verse service for Bible verse lookups
-	chatUsageRepo   repository.ChatUsageRepository
-	rateLimitConfig *config.Config // Configuration for rate limiting
+	llmClient        llm.LLMClient
+	modelName        string
+	verseService     VerseService // Added verse service for Bible verse lookups
+	planService      PlanService  // Today's reading, for the chat tools
+	chatUsageRepo    repository.ChatUsageRepository
+	conversationRepo repository.ConversationRepository
+	userRepo         repository.UserRepository // Time zones and chat limits
+	moderation       ModerationService         // Screens questions and answers
+	cfg              *config.Config            // Rate limits and history budget
 }
 
 // NewChatService now includes all dependencies
-func NewChatService(client llm.LLMClient, modelName string, verseService VerseService,
-	chatUsageRepo repository.ChatUsageRepository, cfg *config.Config) ChatService {
+func NewChatService(client llm.LLMClient, modelName string, verseService VerseService, planService PlanService,
+	chatUsageRepo repository.ChatUsageRepository, conversationRepo repository.ConversationRepository, userRepo repository.UserRepository, moderationService ModerationService, cfg *config.Config) ChatService {
 	return &chatService{
-		llmClient:       client,
-		modelName:       modelName,
-		verseService:    verseService,
-		chatUsageRepo:   chatUsageRepo,
-		rateLimitConfig: cfg,
+		llmClient:        client,
+		modelName:        modelName,
+		verseService:     verseService,
+		planService:      planService,
+		chatUsageRepo:    chatUsageRepo,
+		conversationRepo: conversationRepo,
+		userRepo:         userRepo,
+		moderation:       moderationService,
+		cfg:              cfg,
 	}
 }
 
-// GetResponse now manages history and implements rate limiting
-func (s *chatService) GetResponse(ctx context.Context, verse domain.DailyVerse, question string, userID string) (string, error) {
-	if question == "" {
-		return "", errors.New("question cannot be empty")
+// chatTurn is a question ready to be sent to the LLM
+type chatTurn struct {
+	conversation *domain.Conversation
+	question     domain.ChatMessage // Asked now, or asked earlier when regenerating
+	newQuestion  bool               // Whether the question still has to be stored
+	request      llm.ChatCompletionRequest
+	prompt       *domain.PromptRecord // Kept with the answer for feedback
+	sources      []domain.Citation    // Passages the prompt grounds the answer in, and those its tools found
+	verdict      moderation.Verdict   // Moderation verdict on the question
+	usageDay     string               // Day the request was counted against; empty if it wasn't
+	generated    bool                 // Whether the LLM produced any output
+}
+
+// GetResponse answers a question within a user's conversation and stores both turns.
+// A new conversation is tied to the plan day and passage of the verse it starts from.
+// The answer is written for the audience profile.
+func (s *chatService) GetResponse(ctx context.Context, userID string, question ChatQuestion, verse domain.DailyVerse, audience domain.AudienceProfile) (reply ChatReply, err error) {
+	ctx = metering.WithFeature(ctx, metering.FeatureChat)
+	turn, err := s.prepareChat(ctx, userID, question, verse, audience)
+	if err != nil {
+		return ChatReply{}, err
 	}
+	defer s.releaseUnanswered(ctx, userID, turn, &err)
 
-	// Check rate limits if enabled
-	if s.rateLimitConfig.ChatRateLimitEnabled && userID != "" {
-		currentUsage, err := s.chatUsageRepo.GetTodayUsage(ctx, userID)
-		if err != nil {
-			log.Printf("WARN: Failed to check chat rate limit: %v", err)
-			// Continue despite error to maintain service availability
-		} else if currentUsage >= s.rateLimitConfig.ChatRateLimitPerDay {
-			// User has exceeded their daily limit
-			return "", ErrRateLimitExceeded{}
-		}
+	response, err := s.complete(ctx, userID, turn, nil)
+	if err != nil {
+		// Don't save history if LLM fails
+		return ChatReply{}, fmt.Errorf("LLM completion failed: %w", err)
 	}
 
-	// Lock for reading and potentially writing history
-	conversationHistoryMutex.Lock()
-	defer conversationHistoryMutex.Unlock()
+	return s.finishChat(ctx, userID, turn, response)
+}
+
+// StreamResponse answers like GetResponse, passing the answer to onDelta as it is generated.
+// The answer is passed on a few sentences at a time once the text so far passes screening. Once
+// screening flags it, or when the question was flagged, the rest arrives as moderated in one piece.
+func (s *chatService) StreamResponse(ctx context.Context, userID string, question ChatQuestion, verse domain.DailyVerse, audience domain.AudienceProfile, onDelta func(delta string) error, onReset func() error) (reply ChatReply, err error) {
+	ctx = metering.WithFeature(ctx, metering.FeatureChat)
+	turn, err := s.prepareChat(ctx, userID, question, verse, audience)
+	if err != nil {
+		return ChatReply{}, err
+	}
+	defer s.releaseUnanswered(ctx, userID, turn, &err)
 
-	// Retrieve current history for the niece
-	history, ok := conversationHistory[nieceConversationKey]
+	hold := turn.verdict.Flagged()
+	stream := &screenedStream{ctx: ctx, moderation: s.moderation, onDelta: onDelta, onReset: onReset, hold: hold, held: hold}
+	response, err := s.complete(ctx, userID, turn, stream)
+	if err != nil {
+		// A partial answer is never stored, so the question can simply be asked again
+		return ChatReply{}, fmt.Errorf("LLM completion failed: %w", err)
+	}
+
+	reply, err = s.finishChat(ctx, userID, turn, response)
+	if err != nil {
+		return ChatReply{}, err
+	}
+	if err := stream.finish(reply.Answer); err != nil {
+		log.Printf("WARN: Failed to pass the rest of the answer on for conversation %s: %v", reply.ConversationID, err)
+	}
+	return reply, nil
+}
+
+// screenedStream holds back streamed text until the text so far has passed answer screening
+type screenedStream struct {
+	ctx        context.Context
+	moderation ModerationService
+	onDelta    func(delta string) error
+	onReset    func() error
+	text       strings.Builder // Everything streamed in this round
+	passed     int             // Bytes of text passed on
+	hold       bool            // Whether the question was flagged, so the answer is only passed on once screened whole
+	held       bool            // Whether nothing more is passed on, because of hold or screening flagged the text
+}
+
+// write adds a delta, passing the text on up to its last sentence end once enough has built up.
+// Only the new text and a little before it is screened; finishChat screens the whole answer.
+func (w *screenedStream) write(delta string) error {
+	w.text.WriteString(delta)
+	if w.held {
+		return nil
+	}
+	text := w.text.String()
+	end := strings.LastIndexAny(text, ".!?\n") + 1
+	if end-w.passed < streamScreenBytes {
+		return nil
+	}
+	start := max(w.passed-streamScreenOverlap, 0)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	if w.moderation.Screen(w.ctx, moderation.StageAnswer, text[start:end]).Flagged() {
+		w.held = true
+		return nil
+	}
+	chunk := text[w.passed:end]
+	w.passed = end
+	return w.onDelta(chunk)
+}
+
+// discard drops the text of a round that ended in tool calls, which isn't part of the answer,
+// resetting what was passed on of it
+func (w *screenedStream) discard() error {
+	w.text.Reset()
+	w.held = w.hold
+	if w.passed == 0 {
+		return nil
+	}
+	w.passed = 0
+	return w.onReset()
+}
+
+// finish passes on the rest of the answer as delivered. When the delivered answer doesn't carry
+// on from the text already passed on, e.g. because it was blocked, that text is reset and the
+// whole answer passed on.
+func (w *screenedStream) finish(answer string) error {
+	rest, ok := strings.CutPrefix(answer, w.text.String()[:w.passed])
 	if !ok {
-		history = make([]llm.Message, 0)
-		// Initialize history with verse reference for a new conversation (without full text to save tokens)
-		if verse.Reference != "" {
-			// Add only the verse reference as context, not the full text
-			initialContext := fmt.Sprintf("Let's talk about Bible verse %s. The user can see the full text. My first question is: %s",
-				verse.Reference, question)
-			history = append(history, llm.Message{Role: "user", Content: initialContext})
-			log.Printf("INFO: Initializing chat history for key '%s' with verse reference only (token optimized).", nieceConversationKey)
-		} else {
-			// If verse reference is empty, just add the question
-			history = append(history, llm.Message{Role: "user", Content: question})
+		if err := w.onReset(); err != nil {
+			return err
 		}
+		rest = answer
+	}
+	if rest == "" {
+		return nil
+	}
+	return w.onDelta(rest)
+}
+
+// prepareChat reserves a request within the rate limit, loads the conversation (or describes the new one
+// the question starts), places the question in the conversation's tree, retrieves the passages to ground
+// the answer in and builds the completion request from the branch leading to the question.
+// The reservation is released again if the question can't be asked.
+func (s *chatService) prepareChat(ctx context.Context, userID string, q ChatQuestion, verse domain.DailyVerse, audience domain.AudienceProfile) (_ *chatTurn, err error) {
+	if q.Regenerate && q.EditOf != "" {
+		return nil, errors.New("invalid edit: an answer can't be regenerated while its question is edited")
+	}
+	if q.Regenerate && q.ConversationID == "" {
+		return nil, errors.New("nothing to regenerate")
+	}
+	if q.EditOf != "" && q.ConversationID == "" {
+		return nil, errors.New("message not found")
+	}
+	if !q.Regenerate && q.Text == "" {
+		return nil, errors.New("question cannot be empty")
+	}
+
+	usageDay, err := s.reserveChat(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			s.releaseChat(ctx, userID, usageDay)
+		}
+	}()
+
+	// Load the conversation, or describe the one this question starts
+	var conversation *domain.Conversation
+	if q.ConversationID != "" {
+		existing, err := s.GetConversation(ctx, userID, q.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		conversation = existing
 	} else {
-		// Append only the new question if history exists
-		history = append(history, llm.Message{Role: "user", Content: question})
+		conversation = &domain.Conversation{
+			UserID:    userID,
+			Title:     conversationTitle(verse, q.Text),
+			PlanID:    verse.PlanID,
+			DayNumber: verse.DayNumber,
+			Reference: verse.Reference,
+		}
+	}
+
+	questionMessage, newQuestion, err := placeQuestion(conversation, q)
+	if err != nil {
+		return nil, err
+	}
+	question := questionMessage.Content
+
+	// Screen the question before it reaches the LLM
+	verdict := s.moderation.Screen(ctx, moderation.StageQuestion, question)
+	if verdict.Action == moderation.Block {
+		s.recordFlag(ctx, conversation, moderation.StageQuestion, verdict, question, "", "")
+		return nil, ErrContentBlocked{}
 	}
 
 	// --- LLM Prompt Construction ---
-	// System prompt provides overall context
-	systemPrompt := "You are a friendly, kind, and knowledgeable Bible helper explaining things to a 14-year-old. Explain the verse clearly and simply. Keep answers concise and encouraging. Relate it to modern life if appropriate, but stay true to the verse's meaning. Respond directly to the user's latest question, considering the conversation history provided."
+	// System prompt provides overall context, written for the user's audience profile
+	systemPrompt := "You are a friendly, kind, and knowledgeable Bible helper. " + audience.PromptInstructions() +
+		" Explain the verse clearly. Keep answers concise. Relate it to modern life if appropriate, but stay true to the verse's meaning. Respond directly to the user's latest question, considering the conversation history provided."
+	if conversation.Reference != "" {
+		systemPrompt += fmt.Sprintf(" The conversation is about Bible verse %s. The user can see the full text.", conversation.Reference)
+	}
+
+	if verdict.Flagged() {
+		systemPrompt += " " + moderation.SofteningInstructions(verdict.Categories)
+	}
+
+	// The passage with its context and any references in the question ground the answer
+	sources := s.retrieveSources(ctx, conversation.Reference, question)
+	if len(sources) > 0 {
+		systemPrompt += groundingInstructions
+	}
+	if s.cfg.ChatToolsEnabled {
+		systemPrompt += toolInstructions
+	}
 
-	// Prepare messages for LLM: System Prompt + History
-	// WARNING: Long histories can exceed LLM token limits. Production apps need pruning.
+	// The system prompt, the passages and the summary of older turns are always sent
 	messagesForLLM := []llm.Message{
 		{Role: "system", Content: systemPrompt},
 	}
-	messagesForLLM = append(messagesForLLM, history...)
+	if len(sources) > 0 {
+		messagesForLLM = append(messagesForLLM, llm.Message{Role: "system", Content: groundingMessage(sources)})
+	}
+	summary, unsummarized := conversation.SummaryFor(conversation.PathTo(questionMessage.ParentID))
+	if summary != "" {
+		messagesForLLM = append(messagesForLLM, llm.Message{Role: "system", Content: "Summary of the earlier conversation: " + summary})
+	}
+
+	// Then as many of the branch's turns since the summary as fit the budget, and the question
+	branch := append(unsummarized, questionMessage)
+	history := toLLMMessages(branch)
+	first := tokenbudget.Window(history, s.historyBudget(messagesForLLM))
+	if first > 0 {
+		log.Printf("DEBUG: Leaving %d older messages of conversation %s out of the prompt", first, conversation.ID)
+	}
 
-	// Optional: Implement history pruning here if needed
-	// e.g., keep only the last N messages, or based on token count
+	// Record how the prompt was built, so a rating of the answer can rebuild it
+	prompt := &domain.PromptRecord{HistoryFrom: branch[first].ID}
+	for _, message := range messagesForLLM {
+		prompt.System = append(prompt.System, message.Content)
+	}
+	messagesForLLM = append(messagesForLLM, history[first:]...)
 
 	request := llm.ChatCompletionRequest{
 		Model:       s.modelName,
 		Messages:    messagesForLLM,
-		MaxTokens:   300, // Might need adjustment based on expected answer length
+		MaxTokens:   chatAnswerMaxTokens,
 		Temperature: 0.6,
 	}
+	if s.cfg.ChatToolsEnabled {
+		request.Tools = chatTools
+		request.ToolChoice = llm.ToolChoiceAuto
+	}
+	return &chatTurn{conversation: conversation, question: questionMessage, newQuestion: newQuestion, request: request, prompt: prompt, sources: sources, verdict: verdict, usageDay: usageDay}, nil
+}
 
-	response, err := s.llmClient.CreateChatCompletion(ctx, request)
-	if err != nil {
-		// Don't save history if LLM fails
-		return "", fmt.Errorf("LLM completion failed: %w", err)
+// placeQuestion finds where a question goes in the conversation's tree. A new question follows
+// the branch being shown and an edited one becomes a sibling of the original, while
+// regenerating reuses the branch's last question. It reports whether the question is new.
+func placeQuestion(conversation *domain.Conversation, q ChatQuestion) (domain.ChatMessage, bool, error) {
+	question := domain.ChatMessage{ID: uuid.NewString(), ParentID: conversation.LeafID, Role: "user", Content: q.Text}
+	switch {
+	case q.Regenerate:
+		branch := conversation.Branch()
+		for i := len(branch) - 1; i >= 0; i-- {
+			if branch[i].Role == "user" {
+				return branch[i], false, nil
+			}
+		}
+		return domain.ChatMessage{}, false, errors.New("nothing to regenerate")
+	case q.EditOf != "":
+		original := conversation.Message(q.EditOf)
+		if original == nil {
+			return domain.ChatMessage{}, false, errors.New("message not found")
+		}
+		if original.Role != "user" {
+			return domain.ChatMessage{}, false, errors.New("invalid edit: only questions can be edited")
+		}
+		question.ParentID = original.ParentID
 	}
+	return question, true, nil
+}
 
+// finishChat screens the complete answer, stores it with the question and its citations, queues
+// flagged exchanges for review and counts the request towards the user's limit
+func (s *chatService) finishChat(ctx context.Context, userID string, turn *chatTurn, response llm.ChatCompletionResponse) (ChatReply, error) {
 	if len(response.Choices) == 0 || response.Choices[0].Message.Content == "" {
 		// Don't save history if LLM gives empty response
-		return "", errors.New("LLM returned an empty response")
+		return ChatReply{}, errors.New("LLM returned an empty response")
 	}
 
-	assistantResponse := response.Choices[0].Message.Content
-
-	// Append assistant's response to the history
-	history = append(history, llm.Message{Role: "assistant", Content: assistantResponse})
-
-	// Save updated history back to the map
-	conversationHistory[nieceConversationKey] = history
-	log.Printf("INFO: Updated chat history for key '%s'. History length: %d messages.", nieceConversationKey, len(history))
+	generated := response.Choices[0].Message.Content
+	answerVerdict := s.moderation.Screen(ctx, moderation.StageAnswer, generated)
+	assistantResponse := generated
+	var citations []domain.Citation
+	switch answerVerdict.Action {
+	case moderation.Block:
+		assistantResponse = BlockedAnswer
+	case moderation.Soften:
+		assistantResponse = moderation.Mask(generated, answerVerdict.Matches)
+		citations = s.citationsFor(ctx, assistantResponse, turn.sources)
+	default:
+		citations = s.citationsFor(ctx, assistantResponse, turn.sources)
+	}
 
-	// Increment usage counter for rate limiting if enabled and we have a user ID
-	if s.rateLimitConfig.ChatRateLimitEnabled && userID != "" {
-		newCount, err := s.chatUsageRepo.IncrementUsage(ctx, userID)
-		if err != nil {
-			log.Printf("WARN: Failed to increment chat usage counter: %v", err)
-		} else {
-			log.Printf("INFO: User %s has used %d/%d chat requests today",
-				userID, newCount, s.rateLimitConfig.ChatRateLimitPerDay)
+	// Store the question and answer; a new conversation is only created once it has an answer
+	conversation := turn.conversation
+	model := response.Model
+	if model == "" {
+		model = turn.request.Model
+	}
+	now := time.Now()
+	answer := domain.ChatMessage{ID: uuid.NewString(), ParentID: turn.question.ID, Role: "assistant", Content: assistantResponse,
+		Citations: citations, Model: model, Prompt: turn.prompt, CreatedAt: now}
+	messages := []domain.ChatMessage{answer}
+	if turn.newQuestion {
+		question := turn.question
+		question.CreatedAt = now
+		messages = []domain.ChatMessage{question, answer}
+	}
+	if conversation.ID == uuid.Nil {
+		conversation.Messages = messages
+		conversation.LeafID = answer.ID
+		if err := s.conversationRepo.Create(ctx, conversation); err != nil {
+			return ChatReply{}, fmt.Errorf("failed to store conversation: %w", err)
 		}
+		log.Printf("INFO: Started conversation %s for user %s", conversation.ID, userID)
+	} else if err := s.conversationRepo.AppendMessages(ctx, conversation.ID.String(), answer.ID, messages...); err != nil {
+		return ChatReply{}, fmt.Errorf("failed to store chat messages: %w", err)
+	} else {
+		log.Printf("INFO: Updated conversation %s. History length: %d messages.", conversation.ID, len(conversation.Messages)+len(messages))
+		updated := *conversation
+		updated.Messages = append(append([]domain.ChatMessage(nil), conversation.Messages...), messages...)
+		updated.LeafID = answer.ID
+		// Summarizing doesn't hold up the answer; the next question uses the summary once it's stored.
+		// It outlives the request but keeps its tags, within its own deadline.
+		summaryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), summaryTimeout)
+		go func() {
+			defer cancel()
+			s.summarizeIfNeeded(summaryCtx, updated)
+		}()
+	}
+
+	// Queue the exchange for review once it has a conversation to point to
+	if answerVerdict.Flagged() {
+		s.recordFlag(ctx, conversation, moderation.StageAnswer, turn.verdict.Merge(answerVerdict), turn.question.Content, generated, assistantResponse)
+	} else if turn.verdict.Flagged() {
+		s.recordFlag(ctx, conversation, moderation.StageQuestion, turn.verdict, turn.question.Content, generated, assistantResponse)
 	}
 
 	// Return only the latest assistant response
-	return assistantResponse, nil
+	return ChatReply{ConversationID: conversation.ID.String(), QuestionID: turn.question.ID, MessageID: answer.ID, Answer: assistantResponse, Citations: citations}, nil
 }
 
-// ResetChatHistory clears the conversation history for the niece
-func (s *chatService) ResetChatHistory(ctx context.Context) error {
-	conversationHistoryMutex.Lock()
-	defer conversationHistoryMutex.Unlock()
+// recordFlag queues a flagged exchange for review. Failing to queue it doesn't fail the chat.
+func (s *chatService) recordFlag(ctx context.Context, conversation *domain.Conversation, stage moderation.Stage, verdict moderation.Verdict, question string, generated string, delivered string) {
+	flag := &domain.ModerationFlag{
+		UserID:          conversation.UserID,
+		Stage:           string(stage),
+		Action:          string(verdict.Action),
+		Categories:      verdict.Categories,
+		Reasons:         verdict.Reasons,
+		Question:        question,
+		Answer:          generated,
+		DeliveredAnswer: delivered,
+	}
+	if conversation.ID != uuid.Nil {
+		flag.ConversationID = conversation.ID.String()
+	}
+	if err := s.moderation.RecordFlag(ctx, flag); err != nil {
+		log.Printf("ERROR: Failed to queue flagged chat of user %s for review: %v", conversation.UserID, err)
+	}
+}
 
-	_, exists := conversationHistory[nieceConversationKey]
-	if exists {
-		delete(conversationHistory, nieceConversationKey)
-		log.Printf("INFO: Chat history reset for key '%s'.", nieceConversationKey)
-	} else {
-		log.Printf("INFO: Chat history reset requested, but no history found for key '%s'.", nieceConversationKey)
+// historyBudget returns the tokens left for conversation turns after the fixed prompt messages
+// and the answer, capped by the configured history limit
+func (s *chatService) historyBudget(fixed []llm.Message) int {
+	available := tokenbudget.ContextSize(s.modelName, s.cfg.ChatModelContextSizes) - tokenbudget.EstimateMessages(fixed) - chatAnswerMaxTokens
+	if limit := s.cfg.ChatHistoryMaxTokens; limit > 0 && limit < available {
+		available = limit
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// summarizeIfNeeded folds older turns of the branch being shown into the conversation's rolling
+// summary once the turns since the last summary take up most of the history budget. The most
+// recent turns, about half the budget, stay verbatim. A summary of another branch is replaced.
+func (s *chatService) summarizeIfNeeded(ctx context.Context, conversation domain.Conversation) {
+	budget := s.historyBudget(nil)
+	previous, rest := conversation.SummaryFor(conversation.Branch())
+	unsummarized := toLLMMessages(rest)
+	if tokenbudget.EstimateMessages(unsummarized) <= budget*3/4 {
+		return
+	}
+	keep := tokenbudget.Window(unsummarized, budget/2)
+	if keep == 0 {
+		return
+	}
+
+	summary, err := s.generateSummary(ctx, previous, unsummarized[:keep])
+	if err != nil {
+		// The older turns just drop out of the prompt until a later summary succeeds
+		log.Printf("WARN: Failed to summarize conversation %s: %v", conversation.ID, err)
+		return
+	}
+
+	through := rest[keep-1].ID
+	stored, err := s.conversationRepo.SetSummary(ctx, conversation.ID.String(), summary, through, conversation.Summary)
+	if err != nil {
+		log.Printf("WARN: Failed to store summary of conversation %s: %v", conversation.ID, err)
+	} else if stored {
+		log.Printf("INFO: Summarized conversation %s through message %s", conversation.ID, through)
+	}
+}
+
+// generateSummary asks the LLM to fold older turns into the previous summary
+func (s *chatService) generateSummary(ctx context.Context, previousSummary string, turns []llm.Message) (string, error) {
+	var transcript strings.Builder
+	if previousSummary != "" {
+		fmt.Fprintf(&transcript, "Summary so far: %s\n\n", previousSummary)
+	}
+	for _, turn := range turns {
+		fmt.Fprintf(&transcript, "%s: %s\n", turn.Role, turn.Content)
+	}
+
+	request := llm.ChatCompletionRequest{
+		Model: s.modelName,
+		Messages: []llm.Message{
+			{Role: "system", Content: "You summarize conversations between a user and a Bible study helper. Write one paragraph of at most 150 words that keeps the questions asked, the key points of the answers, the passages discussed and anything the user shared about themselves that later answers should remember. Respond with the summary only."},
+			{Role: "user", Content: transcript.String()},
+		},
+		MaxTokens:   summaryMaxTokens,
+		Temperature: 0.3,
+	}
+
+	response, err := s.llmClient.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("LLM completion failed during summarization: %w", err)
+	}
+	if len(response.Choices) == 0 || strings.TrimSpace(response.Choices[0].Message.Content) == "" {
+		return "", errors.New("LLM returned an empty summary")
+	}
+	return strings.TrimSpace(response.Choices[0].Message.Content), nil
+}
+
+// toLLMMessages converts stored chat messages to prompt messages
+func toLLMMessages(messages []domain.ChatMessage) []llm.Message {
+	converted := make([]llm.Message, len(messages))
+	for i, message := range messages {
+		converted[i] = llm.Message{Role: message.Role, Content: message.Content}
+	}
+	return converted
+}
+
+// SwitchBranch shows the branch containing a message, following the newest replies below it
+func (s *chatService) SwitchBranch(ctx context.Context, userID string, conversationID string, messageID string) (*domain.Conversation, error) {
+	conversation, err := s.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Message(messageID) == nil {
+		return nil, errors.New("message not found")
+	}
+	leafID := conversation.LatestLeaf(messageID)
+	if err := s.conversationRepo.SetLeaf(ctx, conversationID, leafID); err != nil {
+		return nil, conversationError(err)
+	}
+	conversation.LeafID = leafID
+	return conversation, nil
+}
+
+// ResetChatHistory clears the messages of one of the user's conversations
+func (s *chatService) ResetChatHistory(ctx context.Context, userID string, conversationID string) error {
+	if _, err := s.GetConversation(ctx, userID, conversationID); err != nil {
+		return err
+	}
+	if err := s.conversationRepo.ClearMessages(ctx, conversationID); err != nil {
+		return conversationError(err)
+	}
+	log.Printf("INFO: Chat history reset for conversation %s of user %s.", conversationID, userID)
+	return nil
+}
+
+// ListConversations lists the user's conversations without their messages, most recently updated first
+func (s *chatService) ListConversations(ctx context.Context, userID string, filter repository.ConversationFilter) ([]*domain.Conversation, error) {
+	conversations, err := s.conversationRepo.ListByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	return conversations, nil
+}
+
+// GetConversation returns one of the user's conversations with its messages.
+// Other users' conversations are reported as not found.
+func (s *chatService) GetConversation(ctx context.Context, userID string, conversationID string) (*domain.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding conversation: %w", err)
+	}
+	if conversation == nil || conversation.UserID != userID {
+		return nil, errors.New("conversation not found")
+	}
+	return conversation, nil
+}
+
+// RenameConversation changes the title of one of the user's conversations
+func (s *chatService) RenameConversation(ctx context.Context, userID string, conversationID string, title string) (*domain.Conversation, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("invalid title: title cannot be empty")
+	}
+	if len([]rune(title)) > maxConversationTitleLength {
+		return nil, fmt.Errorf("invalid title: title cannot be longer than %d characters", maxConversationTitleLength)
+	}
+
+	conversation, err := s.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.conversationRepo.Rename(ctx, conversationID, title); err != nil {
+		return nil, conversationError(err)
+	}
+	conversation.Title = title
+	return conversation, nil
+}
+
+// DeleteConversation removes one of the user's conversations
+func (s *chatService) DeleteConversation(ctx context.Context, userID string, conversationID string) error {
+	if _, err := s.GetConversation(ctx, userID, conversationID); err != nil {
+		return err
+	}
+	if err := s.conversationRepo.Delete(ctx, conversationID); err != nil {
+		return conversationError(err)
+	}
+	log.Printf("INFO: User %s deleted conversation %s", userID, conversationID)
+	return nil
+}
+
+// conversationError maps repository errors to the service's error messages
+func conversationError(err error) error {
+	if errors.Is(err, repository.ErrConversationNotFound) {
+		return errors.New("conversation not found")
+	}
+	return err
+}
+
+// conversationTitle names a new conversation after its passage, or its first question
+func conversationTitle(verse domain.DailyVerse, question string) string {
+	title := strings.TrimSpace(question)
+	if verse.Reference != "" {
+		title = verse.Reference
+		if verse.Title != "" {
+			title += ": " + verse.Title
+		}
+	}
+	if runes := []rune(title); len(runes) > maxConversationTitleLength {
+		title = strings.TrimSpace(string(runes[:maxConversationTitleLength-3])) + "..."
 	}
-	return nil // Indicate success even if no history existed
+	return title
 }
 
-// GetChatUsage returns the current usage and limit for a user
+// GetChatUsage returns the user's usage and limit for their current day. A limit of 0 means unlimited.
 func (s *chatService) GetChatUsage(ctx context.Context, userID string) (int, int, error) {
-	if !s.rateLimitConfig.ChatRateLimitEnabled || userID == "" {
+	if !s.cfg.ChatRateLimitEnabled || userID == "" {
 		// Rate limiting is disabled or no user ID provided
-		return 0, s.rateLimitConfig.ChatRateLimitPerDay, nil
+		return 0, 0, nil
 	}
 
-	currentUsage, err := s.chatUsageRepo.GetTodayUsage(ctx, userID)
+	quota := s.quotaFor(ctx, userID, time.Now())
+	currentUsage, err := s.chatUsageRepo.GetUsage(ctx, userID, quota.day)
 	if err != nil {
-		return 0, s.rateLimitConfig.ChatRateLimitPerDay, err
+		return 0, quota.limit, err
 	}
 
-	return currentUsage, s.rateLimitConfig.ChatRateLimitPerDay, nil
+	return currentUsage, quota.limit, nil
 }
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bibleapp/backend/internal/config"
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/moderation"
+	"bibleapp/backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceQuestion(t *testing.T) {
+	conversation := &domain.Conversation{
+		Messages: []domain.ChatMessage{
+			{ID: "q1", Role: "user", Content: "Who wrote Psalm 23?"},
+			{ID: "a1", ParentID: "q1", Role: "assistant", Content: "David"},
+			{ID: "q2", ParentID: "a1", Role: "user", Content: "When?"},
+			{ID: "a2", ParentID: "q2", Role: "assistant", Content: "During his reign"},
+		},
+		LeafID: "a2",
+	}
+
+	tests := []struct {
+		name           string
+		question       ChatQuestion
+		expectedID     string // Empty for a new question
+		expectedParent string
+		expectedNew    bool
+		expectedErr    string
+	}{
+		{
+			name:           "New question follows the branch being shown",
+			question:       ChatQuestion{Text: "Where?"},
+			expectedParent: "a2",
+			expectedNew:    true,
+		},
+		{
+			name:           "Edited question becomes a sibling of the original",
+			question:       ChatQuestion{Text: "Why?", EditOf: "q2"},
+			expectedParent: "a1",
+			expectedNew:    true,
+		},
+		{
+			name:           "Editing the first question starts a new root",
+			question:       ChatQuestion{Text: "Who wrote Psalm 90?", EditOf: "q1"},
+			expectedParent: "",
+			expectedNew:    true,
+		},
+		{
+			name:           "Regenerating answers the branch's last question again",
+			question:       ChatQuestion{Regenerate: true},
+			expectedID:     "q2",
+			expectedParent: "a1",
+		},
+		{
+			name:        "Editing an answer is refused",
+			question:    ChatQuestion{Text: "Why?", EditOf: "a1"},
+			expectedErr: "invalid edit: only questions can be edited",
+		},
+		{
+			name:        "Editing an unknown message is refused",
+			question:    ChatQuestion{Text: "Why?", EditOf: "missing"},
+			expectedErr: "message not found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			question, isNew, err := placeQuestion(conversation, tc.question)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.expectedID != "" {
+				assert.Equal(t, tc.expectedID, question.ID)
+			} else {
+				assert.NotEmpty(t, question.ID)
+				assert.Equal(t, tc.question.Text, question.Content)
+			}
+			assert.Equal(t, tc.expectedParent, question.ParentID)
+			assert.Equal(t, tc.expectedNew, isNew)
+		})
+	}
+}
+
+func TestPlaceQuestionRegenerateNeedsAQuestion(t *testing.T) {
+	_, _, err := placeQuestion(&domain.Conversation{}, ChatQuestion{Regenerate: true})
+	assert.EqualError(t, err, "nothing to regenerate")
+}
+
+// scriptedLLM answers each request with the next response, streaming its content word by word
+type scriptedLLM struct {
+	responses []llm.ChatCompletionResponse
+}
+
+func (c *scriptedLLM) CreateChatCompletion(ctx context.Context, req llm.ChatCompletionRequest) (llm.ChatCompletionResponse, error) {
+	response := c.responses[0]
+	c.responses = c.responses[1:]
+	return response, nil
+}
+
+func (c *scriptedLLM) CreateChatCompletionStream(ctx context.Context, req llm.ChatCompletionRequest, onDelta func(delta string) error) (llm.ChatCompletionResponse, error) {
+	response := c.responses[0]
+	c.responses = c.responses[1:]
+	for _, word := range strings.SplitAfter(response.Choices[0].Message.Content, " ") {
+		if err := onDelta(word); err != nil {
+			return llm.ChatCompletionResponse{}, err
+		}
+	}
+	return response, nil
+}
+
+// fakeVerseService knows the text of every passage
+type fakeVerseService struct {
+	VerseService
+}
+
+func (f fakeVerseService) GetVerseContent(ctx context.Context, reference string) (string, error) {
+	return "For God so loved the world", nil
+}
+
+func TestStreamResponseDropsTextBeforeToolCalls(t *testing.T) {
+	preamble := strings.Repeat("Let me look that passage up before I answer, so the quote is exact. ", 4)
+	answer := "John 3:16 says that God so loved the world. It is about God's love for everyone."
+	client := &scriptedLLM{responses: []llm.ChatCompletionResponse{
+		{Choices: []llm.ChatChoice{{Message: llm.Message{Role: "assistant", Content: preamble, ToolCalls: []llm.ToolCall{
+			{ID: "call_1", Type: "function", Function: llm.ToolCallFunction{Name: "lookup_passage", Arguments: `{"reference":"John 3:16"}`}},
+		}}}}},
+		{Choices: []llm.ChatChoice{{Message: llm.Message{Role: "assistant", Content: answer}}}},
+	}}
+	chat := NewChatService(client, "some/model", fakeVerseService{}, nil, nil, repository.NewMemoryConversationRepository(), nil,
+		NewModerationService(nil, nil, nil), &config.Config{ChatToolsEnabled: true})
+
+	var streamed []string
+	resets := 0
+	reply, err := chat.StreamResponse(context.Background(), "user-1", ChatQuestion{Text: "What does John 3:16 say?"}, domain.DailyVerse{}, domain.DefaultAudienceProfile,
+		func(delta string) error {
+			streamed = append(streamed, delta)
+			return nil
+		},
+		func() error {
+			streamed = nil
+			resets++
+			return nil
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, answer, reply.Answer)
+	assert.Equal(t, 1, resets, "the preamble was long enough to be passed on before the tool call")
+	assert.Equal(t, reply.Answer, strings.Join(streamed, ""))
+}
+
+// recordingModerator allows everything, keeping the texts it screened
+type recordingModerator struct {
+	texts []string
+}
+
+func (m *recordingModerator) Check(ctx context.Context, stage moderation.Stage, text string) (moderation.Verdict, error) {
+	m.texts = append(m.texts, text)
+	return moderation.Verdict{Action: moderation.Allow}, nil
+}
+
+func TestScreenedStreamScreensOnlyNewText(t *testing.T) {
+	moderator := &recordingModerator{}
+	var passed []string
+	stream := &screenedStream{ctx: context.Background(), moderation: NewModerationService(moderator, nil, nil), onDelta: func(delta string) error {
+		passed = append(passed, delta)
+		return nil
+	}}
+
+	sentence := "In the beginning God created the heaven and the earth, and the earth was without form. "
+	answer := strings.Repeat(sentence, 12)
+	for _, word := range strings.SplitAfter(answer, " ") {
+		require.NoError(t, stream.write(word))
+	}
+	require.NoError(t, stream.finish(answer))
+
+	assert.Equal(t, answer, strings.Join(passed, ""))
+	require.Greater(t, len(moderator.texts), 2)
+	for _, text := range moderator.texts {
+		assert.LessOrEqual(t, len(text), streamScreenBytes+len(sentence)+streamScreenOverlap)
+	}
+}
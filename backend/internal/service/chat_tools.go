@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/tokenbudget"
+)
+
+const (
+	// maxToolRounds caps the tool calling rounds of one answer; the round after it must answer
+	maxToolRounds = 3
+	// maxToolCallsPerRound caps the tools run for one response; further calls are refused
+	maxToolCallsPerRound = 4
+	// toolResultMaxTokens caps the text of one tool result, such as a long passage
+	toolResultMaxTokens = 800
+	// defaultToolSearchResults is how many verses search_verses returns without a limit
+	defaultToolSearchResults = 5
+)
+
+// toolInstructions tells the assistant when to use its tools
+const toolInstructions = " You can look up the exact text of any Bible passage, search verses by keyword and check the user's reading for today with the tools provided." +
+	" Use them rather than memory whenever you quote a verse the passages above don't include."
+
+// chatTools are the functions the chat assistant may call
+var chatTools = []llm.Tool{
+	llm.NewFunctionTool("lookup_passage",
+		"Look up the exact text of a Bible passage.",
+		`{"type":"object","properties":{"reference":{"type":"string","description":"Bible reference, e.g. \"John 3:16\" or \"Psalm 23:1-6\""}},"required":["reference"]}`),
+	llm.NewFunctionTool("search_verses",
+		"Search Bible verses containing the given words, best matches first.",
+		`{"type":"object","properties":{"query":{"type":"string","description":"Words to search for, e.g. \"love your enemies\""},"limit":{"type":"integer","minimum":1,"maximum":10,"description":"Most verses to return, 5 by default"}},"required":["query"]}`),
+	llm.NewFunctionTool("get_todays_reading",
+		"Get the passage of the user's reading plan for today, with its text.",
+		`{"type":"object","properties":{}}`),
+}
+
+// complete sends a turn's request and runs the tools the model calls, sending their results
+// back until it answers. After maxToolRounds rounds the model has to answer without tools.
+// With a stream the responses are streamed to it, and the text of each round that ends in tool
+// calls is discarded, as only the last round's text is the answer. Passages the tools return
+// are added to the turn's sources for citing.
+func (s *chatService) complete(ctx context.Context, userID string, turn *chatTurn, stream *screenedStream) (llm.ChatCompletionResponse, error) {
+	request := turn.request
+	request.Messages = append([]llm.Message(nil), request.Messages...)
+	for round := 0; ; round++ {
+		if len(request.Tools) > 0 && round == maxToolRounds {
+			request.ToolChoice = llm.ToolChoiceNone
+		}
+
+		var response llm.ChatCompletionResponse
+		var err error
+		if stream != nil {
+			response, err = s.llmClient.CreateChatCompletionStream(ctx, request, func(delta string) error {
+				turn.generated = true
+				return stream.write(delta)
+			})
+		} else {
+			response, err = s.llmClient.CreateChatCompletion(ctx, request)
+		}
+		if len(response.Choices) > 0 && (response.Choices[0].Message.Content != "" || len(response.Choices[0].Message.ToolCalls) > 0) {
+			turn.generated = true
+		}
+		if err != nil || len(response.Choices) == 0 || len(response.Choices[0].Message.ToolCalls) == 0 || round == maxToolRounds {
+			return response, err
+		}
+
+		calls := response.Choices[0].Message.ToolCalls
+		if stream != nil {
+			if err := stream.discard(); err != nil {
+				return response, err
+			}
+		}
+		request.Messages = append(request.Messages, llm.Message{Role: "assistant", Content: response.Choices[0].Message.Content, ToolCalls: calls})
+		for i, call := range calls {
+			// Every call needs a result, even the ones that aren't run
+			result := "Error: too many tool calls at once; call fewer tools."
+			if i < maxToolCallsPerRound {
+				var passages []domain.Citation
+				result, passages = s.runTool(ctx, userID, call)
+				turn.sources = addSources(turn.sources, passages)
+			}
+			request.Messages = append(request.Messages, llm.Message{Role: "tool", ToolCallID: call.ID, Content: result})
+		}
+	}
+}
+
+// runTool runs one tool call and returns its result for the model with the passages it found.
+// Failures are reported to the model as the result so it can answer without them.
+func (s *chatService) runTool(ctx context.Context, userID string, call llm.ToolCall) (string, []domain.Citation) {
+	var args struct {
+		Reference string `json:"reference"`
+		Query     string `json:"query"`
+		Limit     int    `json:"limit"`
+	}
+	if strings.TrimSpace(call.Function.Arguments) != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			return "Error: arguments must be a JSON object.", nil
+		}
+	}
+	log.Printf("DEBUG: Running chat tool %s for user %s with %s", call.Function.Name, userID, call.Function.Arguments)
+
+	switch call.Function.Name {
+	case "lookup_passage":
+		reference := strings.TrimSpace(args.Reference)
+		if reference == "" {
+			return "Error: a reference is required.", nil
+		}
+		text, err := s.verseService.GetVerseContent(ctx, reference)
+		if err != nil || strings.TrimSpace(text) == "" {
+			return fmt.Sprintf("No passage found for %s.", reference), nil
+		}
+		text = tokenbudget.Truncate(text, toolResultMaxTokens)
+		return reference + "\n" + text, []domain.Citation{{Reference: reference, Text: text}}
+
+	case "search_verses":
+		limit := args.Limit
+		if limit <= 0 {
+			limit = defaultToolSearchResults
+		}
+		matches, err := s.verseService.SearchVerses(ctx, args.Query, limit)
+		if err != nil {
+			log.Printf("WARN: Chat tool search_verses failed for user %s: %v", userID, err)
+			return "Error: " + err.Error(), nil
+		}
+		if len(matches) == 0 {
+			return fmt.Sprintf("No verses found for %q.", args.Query), nil
+		}
+		var result strings.Builder
+		for _, match := range matches {
+			fmt.Fprintf(&result, "%s: %s\n", match.Reference, match.Text)
+		}
+		return strings.TrimSpace(result.String()), matches
+
+	case "get_todays_reading":
+		verse, err := s.planService.GetEnrichedVerseForToday(ctx, userID, s.verseService)
+		if err != nil {
+			return "The user has no reading for today.", nil
+		}
+		text := tokenbudget.Truncate(verse.Text, toolResultMaxTokens)
+		result := fmt.Sprintf("Day %d: %s", verse.DayNumber, verse.Reference)
+		if verse.Title != "" {
+			result += " (" + verse.Title + ")"
+		}
+		if verse.PlanTopic != "" {
+			result += ", from the plan on " + verse.PlanTopic
+		}
+		if text == "" {
+			return result, nil
+		}
+		return result + "\n" + text, []domain.Citation{{Reference: verse.Reference, Text: text}}
+	}
+	return fmt.Sprintf("Error: unknown tool %q.", call.Function.Name), nil
+}
+
+// addSources adds passages to the sources an answer may cite, skipping ones already covered
+func addSources(sources []domain.Citation, passages []domain.Citation) []domain.Citation {
+	for _, passage := range passages {
+		if !containsReference(sources, passage.Reference) {
+			sources = append(sources, passage)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/metering"
+	"bibleapp/backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// DevotionalService writes the short devotional shown with each day's reading.
+// Devotionals are generated the first time a day is viewed and cached in the plan.
+type DevotionalService interface {
+	// EnsureDevotional fills in the day's explanation, generating and storing it if the plan has none yet
+	EnsureDevotional(ctx context.Context, verse domain.DailyVerse) (domain.DailyVerse, error)
+	// RegenerateDevotional replaces the stored devotional of a plan day
+	RegenerateDevotional(ctx context.Context, planID string, dayNumber int, actor domain.Actor) (domain.DailyVerse, error)
+}
+
+type devotionalService struct {
+	planRepo     repository.PlanRepository
+	llmClient    llm.LLMClient
+	verseService VerseService
+	modelName    string
+}
+
+// NewDevotionalService creates a new DevotionalService
+func NewDevotionalService(planRepo repository.PlanRepository, llmClient llm.LLMClient, verseService VerseService, modelName string) DevotionalService {
+	return &devotionalService{
+		planRepo:     planRepo,
+		llmClient:    llmClient,
+		verseService: verseService,
+		modelName:    modelName,
+	}
+}
+
+// EnsureDevotional fills in the day's explanation, generating and storing it if the plan has none yet
+func (s *devotionalService) EnsureDevotional(ctx context.Context, verse domain.DailyVerse) (domain.DailyVerse, error) {
+	if verse.Explanation != "" {
+		return verse, nil
+	}
+	if verse.PlanID == "" {
+		return verse, errors.New("verse is not linked to a plan")
+	}
+
+	plan, err := s.planRepo.FindByID(ctx, verse.PlanID)
+	if err != nil {
+		return verse, fmt.Errorf("error finding plan for devotional: %w", err)
+	}
+	if plan == nil {
+		return verse, errors.New("plan not found")
+	}
+
+	explanation, err := s.generateDevotional(ctx, plan, verse)
+	if err != nil {
+		return verse, err
+	}
+
+	stored, err := s.planRepo.SetDayExplanation(ctx, verse.PlanID, verse.DayNumber, explanation, true)
+	if err != nil {
+		// The reader still gets the devotional; it is generated again next time
+		log.Printf("WARN: Failed to cache devotional for day %d of plan %s: %v", verse.DayNumber, verse.PlanID, err)
+	} else if !stored {
+		// Another request stored a devotional first; serve that one so every reader sees the same text
+		if existing := s.storedExplanation(ctx, verse.PlanID, verse.DayNumber); existing != "" {
+			explanation = existing
+		}
+	} else {
+		log.Printf("INFO: Cached devotional for day %d of plan %s", verse.DayNumber, verse.PlanID)
+	}
+
+	verse.Explanation = explanation
+	return verse, nil
+}
+
+// RegenerateDevotional replaces the stored devotional of a plan day
+func (s *devotionalService) RegenerateDevotional(ctx context.Context, planID string, dayNumber int, actor domain.Actor) (domain.DailyVerse, error) {
+	if !actor.Can(domain.PermEditAnyPlan) {
+		return domain.DailyVerse{}, errors.New("unauthorized: cannot regenerate devotionals")
+	}
+
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil {
+		return domain.DailyVerse{}, fmt.Errorf("error finding plan for devotional: %w", err)
+	}
+	if plan == nil {
+		return domain.DailyVerse{}, errors.New("plan not found")
+	}
+
+	verse, found := plan.GetVerseForDay(dayNumber)
+	if !found {
+		return domain.DailyVerse{}, repository.ErrDayOutOfRange
+	}
+	verse.PlanID = planID
+
+	verse, err = s.verseService.EnrichDailyVerse(ctx, verse)
+	if err != nil {
+		log.Printf("WARN: Generating devotional for day %d of plan %s without passage text: %v", dayNumber, planID, err)
+	}
+
+	explanation, err := s.generateDevotional(ctx, plan, verse)
+	if err != nil {
+		return domain.DailyVerse{}, err
+	}
+
+	if _, err := s.planRepo.SetDayExplanation(ctx, planID, dayNumber, explanation, false); err != nil {
+		return domain.DailyVerse{}, fmt.Errorf("failed to store devotional: %w", err)
+	}
+
+	log.Printf("INFO: User %s regenerated devotional for day %d of plan %s", actor.UserID, dayNumber, planID)
+	verse.Explanation = explanation
+	return verse, nil
+}
+
+// generateDevotional asks the LLM for a short devotional on the day's passage, written for the plan's audience
+func (s *devotionalService) generateDevotional(ctx context.Context, plan *domain.ReadingPlan, verse domain.DailyVerse) (string, error) {
+	audience := plan.TargetAudience
+	if audience == "" {
+		audience = "reader"
+	}
+
+	systemPrompt := fmt.Sprintf(`You write short daily devotionals for a Bible reading plan on "%s" for a %s.
+
+Write a devotional of 100 to 150 words on the day's passage:
+- Explain what the passage says in language a %s understands
+- Connect it to the plan's topic
+- End with one practical thought or question for the day
+
+Respond with plain text paragraphs only: no title, no headings, no markdown, and do not repeat the passage.`,
+		plan.Topic, audience, audience)
+
+	userPrompt := fmt.Sprintf("Day %d: %s\nPassage: %s", verse.DayNumber, verse.Title, verse.Reference)
+	if verse.Text != "" {
+		userPrompt += "\n\n" + verse.Text
+	}
+
+	request := llm.ChatCompletionRequest{
+		Model: s.modelName,
+		Messages: []llm.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		MaxTokens:   400,
+		Temperature: 0.7,
+	}
+
+	llmResponse, err := s.llmClient.CreateChatCompletion(metering.WithFeature(ctx, metering.FeatureDevotional), request)
+	if err != nil {
+		return "", fmt.Errorf("LLM completion failed during devotional generation: %w", err)
+	}
+
+	if len(llmResponse.Choices) == 0 {
+		return "", errors.New("LLM returned an empty response for devotional generation")
+	}
+	explanation := strings.TrimSpace(llmResponse.Choices[0].Message.Content)
+	if explanation == "" {
+		return "", errors.New("LLM returned an empty response for devotional generation")
+	}
+
+	log.Printf("INFO: Generated devotional for day %d of plan %s (%d characters)", verse.DayNumber, plan.ID, len(explanation))
+	return explanation, nil
+}
+
+// storedExplanation re-reads the devotional currently cached for a plan day
+func (s *devotionalService) storedExplanation(ctx context.Context, planID string, dayNumber int) string {
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil || plan == nil {
+		return ""
+	}
+	verse, found := plan.GetVerseForDay(dayNumber)
+	if !found {
+		return ""
+	}
+	return verse.Explanation
+}
+
+// keepCachedDevotionals carries generated devotionals over to an edited plan for
+// days whose passage didn't change and whose devotional the edit didn't set
+func keepCachedDevotionals(plan *domain.ReadingPlan, existing *domain.ReadingPlan) {
+	for i := range plan.DailyVerses {
+		day := &plan.DailyVerses[i]
+		if day.Explanation != "" {
+			continue
+		}
+		previous, found := existing.GetVerseForDay(day.DayNumber)
+		if found && previous.Reference == day.Reference {
+			day.Explanation = previous.Explanation
+			day.ExplanationGeneratedAt = previous.ExplanationGeneratedAt
+		}
+	}
+}
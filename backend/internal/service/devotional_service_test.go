@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/llm"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureDevotional(t *testing.T) {
+	answer := func(content string) llm.ChatCompletionResponse {
+		return llm.ChatCompletionResponse{Choices: []llm.ChatChoice{{Message: llm.Message{Role: "assistant", Content: content}}}}
+	}
+
+	tests := []struct {
+		name           string
+		stored         string // Devotional already in the plan
+		viewed         string // Devotional on the day as it was read, before the call
+		responses      []llm.ChatCompletionResponse
+		expected       string
+		expectedStored string
+		expectedCalls  int
+		expectedErr    string
+	}{
+		{
+			name:           "First view generates and caches the devotional",
+			responses:      []llm.ChatCompletionResponse{answer("  The Lord provides.  ")},
+			expected:       "The Lord provides.",
+			expectedStored: "The Lord provides.",
+			expectedCalls:  1,
+		},
+		{
+			name:           "Cached devotional is served without generating",
+			stored:         "The Lord provides.",
+			viewed:         "The Lord provides.",
+			expected:       "The Lord provides.",
+			expectedStored: "The Lord provides.",
+		},
+		{
+			name:           "Devotional stored by a concurrent view wins",
+			stored:         "Stored first.",
+			responses:      []llm.ChatCompletionResponse{answer("Generated second.")},
+			expected:       "Stored first.",
+			expectedStored: "Stored first.",
+			expectedCalls:  1,
+		},
+		{
+			name:          "Empty answer is an error and nothing is cached",
+			responses:     []llm.ChatCompletionResponse{answer(" ")},
+			expectedCalls: 1,
+			expectedErr:   "LLM returned an empty response for devotional generation",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := &domain.ReadingPlan{ID: uuid.New(), UserID: "user-1", Topic: "Trust", DailyVerses: []domain.DailyVerse{
+				{DayNumber: 1, Reference: "Psalms 23:1-6", Title: "The Shepherd", Explanation: tc.stored},
+			}}
+			plans := newFakePlanRepository(plan)
+			client := &scriptedLLM{responses: tc.responses}
+			devotionals := NewDevotionalService(plans, client, fakeVerseService{}, "some/model")
+
+			verse := plan.DailyVerses[0]
+			verse.PlanID, verse.Explanation = plan.ID.String(), tc.viewed
+			verse, err := devotionals.EnsureDevotional(context.Background(), verse)
+
+			assert.Equal(t, tc.expectedCalls, len(tc.responses)-len(client.responses))
+			assert.Equal(t, tc.expectedStored, plan.DailyVerses[0].Explanation)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, verse.Explanation)
+		})
+	}
+}
+
+func TestRegenerateDevotionalNeedsEditor(t *testing.T) {
+	plan := &domain.ReadingPlan{ID: uuid.New(), UserID: "user-1", DailyVerses: []domain.DailyVerse{{DayNumber: 1, Reference: "Psalms 23:1-6"}}}
+	devotionals := NewDevotionalService(newFakePlanRepository(plan), &scriptedLLM{}, fakeVerseService{}, "some/model")
+
+	_, err := devotionals.RegenerateDevotional(context.Background(), plan.ID.String(), 1, domain.Actor{UserID: "user-1", Roles: []string{domain.RoleUser}})
+	assert.EqualError(t, err, "unauthorized: cannot regenerate devotionals")
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+)
+
+// maxFeedbackReasonLength caps the reason a user can give with a rating
+const maxFeedbackReasonLength = 500
+
+// FeedbackService captures ratings of chat answers and exports them for evaluating prompt and model changes
+type FeedbackService interface {
+	// RateAnswer records the user's rating of an answer in one of their conversations, replacing an earlier rating
+	RateAnswer(ctx context.Context, userID string, conversationID string, messageID string, rating string, reason string) (*domain.AnswerFeedback, error)
+	// ListFeedback returns rated exchanges, oldest first
+	ListFeedback(ctx context.Context, filter repository.FeedbackFilter) ([]*domain.AnswerFeedback, error)
+}
+
+type feedbackService struct {
+	chatService  ChatService
+	verseService VerseService
+	feedbackRepo repository.FeedbackRepository
+}
+
+// NewFeedbackService creates a new FeedbackService
+func NewFeedbackService(chatService ChatService, verseService VerseService, feedbackRepo repository.FeedbackRepository) FeedbackService {
+	return &feedbackService{
+		chatService:  chatService,
+		verseService: verseService,
+		feedbackRepo: feedbackRepo,
+	}
+}
+
+// RateAnswer snapshots the exchange an answer belongs to and stores it with the rating
+func (s *feedbackService) RateAnswer(ctx context.Context, userID string, conversationID string, messageID string, rating string, reason string) (*domain.AnswerFeedback, error) {
+	if !domain.IsValidRating(rating) {
+		return nil, fmt.Errorf("invalid rating: must be '%s' or '%s'", domain.RatingUp, domain.RatingDown)
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxFeedbackReasonLength {
+		return nil, fmt.Errorf("invalid reason: must be at most %d characters", maxFeedbackReasonLength)
+	}
+
+	conversation, err := s.chatService.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	// A reset conversation has lost its messages
+	path := conversation.PathTo(messageID)
+	if len(path) < 2 || path[len(path)-1].Role != "assistant" {
+		return nil, errors.New("answer not found")
+	}
+	answer := path[len(path)-1]
+
+	feedback := &domain.AnswerFeedback{
+		UserID:         userID,
+		ConversationID: conversationID,
+		MessageID:      messageID,
+		Rating:         rating,
+		Reason:         reason,
+		Model:          answer.Model,
+		Prompt:         rebuildPrompt(path),
+		Question:       path[len(path)-2].Content,
+		Answer:         answer.Content,
+		Citations:      answer.Citations,
+		Conversation:   make([]domain.ChatMessage, len(path)),
+		Reference:      conversation.Reference,
+	}
+	for i, message := range path {
+		message.Prompt = nil // Only the rated answer's prompt is kept, in Prompt
+		feedback.Conversation[i] = message
+	}
+	if conversation.Reference != "" {
+		text, err := s.verseService.GetVerseContent(ctx, conversation.Reference)
+		if err != nil {
+			log.Printf("WARN: Storing feedback on conversation %s without the text of %s: %v", conversationID, conversation.Reference, err)
+		} else {
+			feedback.VerseText = text
+		}
+	}
+
+	if err := s.feedbackRepo.Save(ctx, feedback); err != nil {
+		return nil, fmt.Errorf("failed to store feedback: %w", err)
+	}
+	log.Printf("INFO: User %s rated answer %s of conversation %s %s", userID, messageID, conversationID, rating)
+	return feedback, nil
+}
+
+// ListFeedback returns rated exchanges, oldest first
+func (s *feedbackService) ListFeedback(ctx context.Context, filter repository.FeedbackFilter) ([]*domain.AnswerFeedback, error) {
+	if filter.Rating != "" && !domain.IsValidRating(filter.Rating) {
+		return nil, fmt.Errorf("invalid rating: must be '%s' or '%s'", domain.RatingUp, domain.RatingDown)
+	}
+	feedback, err := s.feedbackRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+// rebuildPrompt restores the messages an answer was generated from, given the branch ending
+// with it: the recorded system messages, then the branch from where the prompt's history
+// started up to the question. Answers stored before prompts were recorded have none.
+func rebuildPrompt(path []domain.ChatMessage) []domain.PromptMessage {
+	answerIndex := len(path) - 1
+	record := path[answerIndex].Prompt
+	if record == nil {
+		return nil
+	}
+	prompt := make([]domain.PromptMessage, 0, len(record.System)+1)
+	for _, content := range record.System {
+		prompt = append(prompt, domain.PromptMessage{Role: "system", Content: content})
+	}
+	start := answerIndex
+	for i, message := range path[:answerIndex] {
+		if message.ID == record.HistoryFrom {
+			start = i
+			break
+		}
+	}
+	for _, message := range path[start:answerIndex] {
+		prompt = append(prompt, domain.PromptMessage{Role: message.Role, Content: message.Content})
+	}
+	return prompt
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conversationChat serves one conversation to the feedback service
+type conversationChat struct {
+	ChatService
+	conversation *domain.Conversation
+}
+
+func (c conversationChat) GetConversation(ctx context.Context, userID string, conversationID string) (*domain.Conversation, error) {
+	return c.conversation, nil
+}
+
+// fakeFeedbackRepository records saved feedback
+type fakeFeedbackRepository struct {
+	repository.FeedbackRepository
+	saved []*domain.AnswerFeedback
+}
+
+func (r *fakeFeedbackRepository) Save(ctx context.Context, feedback *domain.AnswerFeedback) error {
+	r.saved = append(r.saved, feedback)
+	return nil
+}
+
+func TestRateAnswer(t *testing.T) {
+	// The second answer was regenerated after the first summary, so its prompt history starts at q2
+	prompt := &domain.PromptRecord{System: []string{"You are a Bible helper.", "Summary: David wrote it."}, HistoryFrom: "q2"}
+	conversation := &domain.Conversation{
+		Reference: "Psalms 23:1",
+		Messages: []domain.ChatMessage{
+			{ID: "q1", Role: "user", Content: "Who wrote Psalm 23?"},
+			{ID: "a1", ParentID: "q1", Role: "assistant", Content: "David"},
+			{ID: "q2", ParentID: "a1", Role: "user", Content: "When?"},
+			{ID: "a2", ParentID: "q2", Role: "assistant", Content: "During his reign", Model: "model-a", Prompt: prompt},
+			{ID: "a3", ParentID: "q2", Role: "assistant", Content: "As a shepherd", Model: "model-b"},
+		},
+		LeafID: "a3",
+	}
+
+	tests := []struct {
+		name        string
+		messageID   string
+		rating      string
+		reason      string
+		expectedErr string
+	}{
+		{name: "Rating an answer on a branch not shown", messageID: "a2", rating: domain.RatingDown, reason: "  Too vague  "},
+		{name: "Unknown rating", messageID: "a2", rating: "meh", expectedErr: "invalid rating: must be 'up' or 'down'"},
+		{name: "Reason too long", messageID: "a2", rating: domain.RatingUp, reason: strings.Repeat("a", 501), expectedErr: "invalid reason: must be at most 500 characters"},
+		{name: "Questions can't be rated", messageID: "q2", rating: domain.RatingUp, expectedErr: "answer not found"},
+		{name: "Unknown message", messageID: "missing", rating: domain.RatingUp, expectedErr: "answer not found"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeFeedbackRepository{}
+			feedback := NewFeedbackService(conversationChat{conversation: conversation}, fakeVerseService{}, repo)
+
+			rated, err := feedback.RateAnswer(context.Background(), "user-1", "conversation-1", tc.messageID, tc.rating, tc.reason)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				assert.Empty(t, repo.saved)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, repo.saved, 1)
+			assert.Same(t, rated, repo.saved[0])
+
+			assert.Equal(t, "Too vague", rated.Reason)
+			assert.Equal(t, "When?", rated.Question)
+			assert.Equal(t, "During his reign", rated.Answer)
+			assert.Equal(t, "model-a", rated.Model)
+			assert.Equal(t, "For God so loved the world", rated.VerseText)
+			assert.Equal(t, []domain.PromptMessage{
+				{Role: "system", Content: "You are a Bible helper."},
+				{Role: "system", Content: "Summary: David wrote it."},
+				{Role: "user", Content: "When?"},
+			}, rated.Prompt)
+			ids := []string{}
+			for _, message := range rated.Conversation {
+				ids = append(ids, message.ID)
+				assert.Nil(t, message.Prompt, "prompt records stay out of the snapshot")
+			}
+			assert.Equal(t, []string{"q1", "a1", "q2", "a2"}, ids)
+		})
+	}
+}
+
+func TestListFeedbackRejectsUnknownRating(t *testing.T) {
+	feedback := NewFeedbackService(nil, nil, &fakeFeedbackRepository{})
+	_, err := feedback.ListFeedback(context.Background(), repository.FeedbackFilter{Rating: "sideways"})
+	assert.EqualError(t, err, "invalid rating: must be 'up' or 'down'")
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/moderation"
+	"bibleapp/backend/internal/repository"
+)
+
+// ErrContentBlocked is returned when moderation blocks a chat question
+type ErrContentBlocked struct{}
+
+func (e ErrContentBlocked) Error() string {
+	return "content blocked"
+}
+
+// BlockedAnswer replaces an answer the moderation pipeline blocked
+const BlockedAnswer = "I'm sorry, I can't help with that. Let's look at what today's passage has to say instead, " +
+	"or talk to a parent, pastor or another trusted adult about it."
+
+// maxReviewNoteLength caps the note a reviewer can leave on a flag
+const maxReviewNoteLength = 500
+
+// ModerationService screens chat content and keeps the review queue of flagged exchanges
+type ModerationService interface {
+	// Screen runs the moderation pipeline over a question or an answer
+	Screen(ctx context.Context, stage moderation.Stage, text string) moderation.Verdict
+	// RecordFlag queues a flagged exchange for review by the user's guardians and admins
+	RecordFlag(ctx context.Context, flag *domain.ModerationFlag) error
+	// ListFlags returns the flags the actor may review: their wards', or everyone's for user managers
+	ListFlags(ctx context.Context, status string, actor domain.Actor) ([]*domain.ModerationFlag, error)
+	// ReviewFlag moves a flag to a new status, recording the reviewer and note in its audit trail
+	ReviewFlag(ctx context.Context, flagID string, status string, note string, actor domain.Actor) (*domain.ModerationFlag, error)
+}
+
+type moderationService struct {
+	moderator      moderation.Moderator
+	moderationRepo repository.ModerationRepository
+	userRepo       repository.UserRepository
+}
+
+// NewModerationService creates a new ModerationService. A nil moderator lets everything through.
+func NewModerationService(moderator moderation.Moderator, moderationRepo repository.ModerationRepository, userRepo repository.UserRepository) ModerationService {
+	return &moderationService{
+		moderator:      moderator,
+		moderationRepo: moderationRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// Screen runs the moderation pipeline. Failures let the content through, since the pipeline
+// already falls back to the rule lists when the classifier is unavailable.
+func (s *moderationService) Screen(ctx context.Context, stage moderation.Stage, text string) moderation.Verdict {
+	if s.moderator == nil {
+		return moderation.Verdict{Action: moderation.Allow}
+	}
+	verdict, err := s.moderator.Check(ctx, stage, text)
+	if err != nil {
+		log.Printf("WARN: Moderation of %s failed: %v", stage, err)
+		return moderation.Verdict{Action: moderation.Allow}
+	}
+	if verdict.Flagged() {
+		log.Printf("INFO: Moderation flagged %s (%s): %v", stage, verdict.Action, verdict.Categories)
+	}
+	return verdict
+}
+
+// RecordFlag stores a flag with the first entry of its audit trail
+func (s *moderationService) RecordFlag(ctx context.Context, flag *domain.ModerationFlag) error {
+	flag.Status = domain.FlagStatusOpen
+	flag.Audit = []domain.ModerationAuditEntry{{
+		ActorID: "system",
+		Status:  domain.FlagStatusOpen,
+		Note:    fmt.Sprintf("%s %s: %s", flag.Action, flag.Stage, strings.Join(flag.Categories, ", ")),
+		At:      time.Now(),
+	}}
+	if err := s.moderationRepo.Create(ctx, flag); err != nil {
+		return fmt.Errorf("failed to store moderation flag: %w", err)
+	}
+	log.Printf("INFO: Queued moderation flag %s for user %s", flag.ID, flag.UserID)
+	return nil
+}
+
+// ListFlags returns the flags of the actor's wards, or every flag for user managers
+func (s *moderationService) ListFlags(ctx context.Context, status string, actor domain.Actor) ([]*domain.ModerationFlag, error) {
+	if status != "" && !domain.IsValidFlagStatus(status) {
+		return nil, fmt.Errorf("invalid status: unknown status '%s'", status)
+	}
+
+	filter := repository.ModerationFlagFilter{Status: status}
+	if !actor.Can(domain.PermManageUsers) {
+		if !actor.Can(domain.PermViewWards) {
+			return nil, errors.New("unauthorized: not a guardian")
+		}
+		wards, err := s.userRepo.FindByGuardian(ctx, actor.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve wards: %w", err)
+		}
+		filter.UserIDs = []string{}
+		for _, ward := range wards {
+			filter.UserIDs = append(filter.UserIDs, ward.ID)
+		}
+	}
+
+	flags, err := s.moderationRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve moderation flags: %w", err)
+	}
+	return flags, nil
+}
+
+// ReviewFlag records a review of a flag. Only the flagged user's guardians and user managers may review it.
+func (s *moderationService) ReviewFlag(ctx context.Context, flagID string, status string, note string, actor domain.Actor) (*domain.ModerationFlag, error) {
+	if !domain.IsValidFlagStatus(status) {
+		return nil, fmt.Errorf("invalid status: unknown status '%s'", status)
+	}
+	note = strings.TrimSpace(note)
+	if len(note) > maxReviewNoteLength {
+		return nil, fmt.Errorf("invalid note: must be at most %d characters", maxReviewNoteLength)
+	}
+
+	flag, err := s.moderationRepo.FindByID(ctx, flagID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding moderation flag: %w", err)
+	}
+	if flag == nil {
+		return nil, errors.New("moderation flag not found")
+	}
+	if err := s.checkReviewer(ctx, flag.UserID, actor); err != nil {
+		return nil, err
+	}
+
+	entry := domain.ModerationAuditEntry{ActorID: actor.UserID, Status: status, Note: note, At: time.Now()}
+	if err := s.moderationRepo.AddReview(ctx, flagID, entry); err != nil {
+		if errors.Is(err, repository.ErrModerationFlagNotFound) {
+			return nil, errors.New("moderation flag not found")
+		}
+		return nil, fmt.Errorf("failed to review moderation flag: %w", err)
+	}
+
+	log.Printf("INFO: User %s marked moderation flag %s as %s", actor.UserID, flagID, status)
+	flag.Audit = append(flag.Audit, entry)
+	flag.Status = status
+	flag.UpdatedAt = entry.At
+	return flag, nil
+}
+
+// checkReviewer allows user managers and the flagged user's linked guardians
+func (s *moderationService) checkReviewer(ctx context.Context, userID string, actor domain.Actor) error {
+	if actor.Can(domain.PermManageUsers) {
+		return nil
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+	if user == nil || !actor.Can(domain.PermViewWards) || !user.HasGuardian(actor.UserID) {
+		return errors.New("unauthorized: not a guardian of this user")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bibleapp/backend/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// recordRevision appends a snapshot of the plan to its revision log. Changes to existing plans
+// record their revision before saving and fail if it can't be written, so the history may hold
+// an edit whose save then failed but never misses one that was saved.
+func (s *planService) recordRevision(ctx context.Context, plan domain.ReadingPlan, author string, action string, note string) error {
+	revision := &domain.PlanRevision{
+		PlanID:   plan.ID,
+		Author:   author,
+		Action:   action,
+		Note:     note,
+		Snapshot: plan,
+	}
+	if err := s.revisionRepo.Append(ctx, revision); err != nil {
+		log.Printf("ERROR: Failed to record %s revision for plan %s: %v", action, plan.ID, err)
+		return fmt.Errorf("failed to record plan revision: %w", err)
+	}
+	return nil
+}
+
+// ensureBaselineRevision records the current state of a plan that has no revisions yet
+func (s *planService) ensureBaselineRevision(ctx context.Context, plan *domain.ReadingPlan) error {
+	revisions, err := s.revisionRepo.ListByPlan(ctx, plan.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to check plan revisions: %w", err)
+	}
+	if len(revisions) == 0 {
+		return s.recordRevision(ctx, *plan, domain.RevisionAuthorSystem, domain.RevisionActionBaseline, "State before revision history was recorded")
+	}
+	return nil
+}
+
+// findPlanForRevisions loads a plan and checks that the actor may see and change its history
+func (s *planService) findPlanForRevisions(ctx context.Context, planID string, actor domain.Actor) (*domain.ReadingPlan, error) {
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding plan: %w", err)
+	}
+
+	if plan == nil {
+		return nil, errors.New("plan not found")
+	}
+
+	if !canModifyPlan(plan, actor) {
+		return nil, errors.New("unauthorized: cannot access another user's plan history")
+	}
+	return plan, nil
+}
+
+// ListPlanRevisions returns the revision history of a plan, oldest first
+func (s *planService) ListPlanRevisions(ctx context.Context, planID string, actor domain.Actor) ([]domain.PlanRevision, error) {
+	if _, err := s.findPlanForRevisions(ctx, planID, actor); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve plan revisions: %w", err)
+	}
+
+	result := make([]domain.PlanRevision, len(revisions))
+	for i, revision := range revisions {
+		result[i] = *revision
+	}
+	return result, nil
+}
+
+// DiffPlanRevisions compares two revisions of a plan
+func (s *planService) DiffPlanRevisions(ctx context.Context, planID string, fromRevision int, toRevision int, actor domain.Actor) (domain.PlanDiff, error) {
+	if _, err := s.findPlanForRevisions(ctx, planID, actor); err != nil {
+		return domain.PlanDiff{}, err
+	}
+
+	from, err := s.findRevision(ctx, planID, fromRevision)
+	if err != nil {
+		return domain.PlanDiff{}, err
+	}
+	to, err := s.findRevision(ctx, planID, toRevision)
+	if err != nil {
+		return domain.PlanDiff{}, err
+	}
+
+	diff := domain.DiffPlans(from.Snapshot, to.Snapshot)
+	diff.PlanID = planID
+	diff.FromRevision = from.Revision
+	diff.ToRevision = to.Revision
+	return diff, nil
+}
+
+// RollbackPlan restores the content of an earlier revision. The rollback itself
+// is recorded as a new revision, so it can be undone like any other change.
+func (s *planService) RollbackPlan(ctx context.Context, planID string, revision int, actor domain.Actor) (domain.ReadingPlan, error) {
+	plan, err := s.findPlanForRevisions(ctx, planID, actor)
+	if err != nil {
+		return domain.ReadingPlan{}, err
+	}
+
+	target, err := s.findRevision(ctx, planID, revision)
+	if err != nil {
+		return domain.ReadingPlan{}, err
+	}
+
+	if err := s.ensureBaselineRevision(ctx, plan); err != nil {
+		return domain.ReadingPlan{}, err
+	}
+
+	// Restore the editable content; ownership and calendar dates stay as they are now
+	restored := *plan
+	restored.Topic = target.Snapshot.Topic
+	restored.DurationDays = target.Snapshot.DurationDays
+	restored.TargetAudience = target.Snapshot.TargetAudience
+	restored.MinutesPerDay = target.Snapshot.MinutesPerDay
+	restored.DailyVerses = target.Snapshot.DailyVerses
+	if err := s.checkEditedDays(ctx, &restored, plan); err != nil {
+		return domain.ReadingPlan{}, err
+	}
+
+	if err := s.recordRevision(ctx, restored, actor.UserID, domain.RevisionActionRollback, fmt.Sprintf("Rolled back to revision %d", target.Revision)); err != nil {
+		return domain.ReadingPlan{}, err
+	}
+	if err := s.planRepo.Save(ctx, &restored); err != nil {
+		return domain.ReadingPlan{}, fmt.Errorf("failed to save rolled back plan: %w", err)
+	}
+
+	log.Printf("INFO: Plan %s rolled back to revision %d by %s", planID, target.Revision, actor.UserID)
+	return restored, nil
+}
+
+func (s *planService) findRevision(ctx context.Context, planID string, revision int) (*domain.PlanRevision, error) {
+	result, err := s.revisionRepo.FindByRevision(ctx, planID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("error finding revision %d: %w", revision, err)
+	}
+	if result == nil {
+		return nil, errors.New("revision not found")
+	}
+	return result, nil
+}
@@ -1,55 +1,115 @@
 package service
 
 import (
-	"bibleapp/backend/internal/config"
+	"bibleapp/backend/internal/calendar"
 	"bibleapp/backend/internal/domain"
 	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/metering"
 	"bibleapp/backend/internal/repository"
+	"bibleapp/backend/internal/scheduler"
 	"bibleapp/backend/internal/util" // Added for IsValidReference
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 )
 
 // PlanService defines the interface for managing reading plans.
 type PlanService interface {
-	CreatePlan(ctx context.Context, userID string, topic string, durationDays int, targetAudience string) (domain.ReadingPlan, error)
+	CreatePlan(ctx context.Context, userID string, topic string, durationDays int, targetAudience string, minutesPerDay int) (domain.ReadingPlan, error)
 	GetActiveVerseForToday(ctx context.Context, userID string) (domain.DailyVerse, error)
 	ListPlans(ctx context.Context, userID string) ([]domain.ReadingPlan, error)
+	// Get a plan the actor may read
+	GetPlan(ctx context.Context, planID string, actor domain.Actor) (domain.ReadingPlan, error)
+	// Get one day of a plan the actor may read
+	GetPlanDay(ctx context.Context, planID string, dayNumber int, actor domain.Actor) (domain.DailyVerse, error)
 	// New method to get a verse with its full content fetched on-demand
 	GetEnrichedVerseForToday(ctx context.Context, userID string, verseService VerseService) (domain.DailyVerse, error)
-	// Auto-generate default weekly plan based on the yearly theme
-	EnsureDefaultWeeklyPlan(ctx context.Context, yearlyTheme string, targetAudience string) error
-	// Get a verse for a specific date
+	// Auto-generate default plans for every configured track
+	EnsureDefaultPlans(ctx context.Context) error
+	// Auto-generate a default plan for one track if its current plan has run out
+	EnsureDefaultTrackPlan(ctx context.Context, track domain.PlanTrack) error
+	// List the configured default plan tracks
+	ListTracks() []domain.PlanTrack
+	// Get the default track a user reads from
+	GetUserTrack(ctx context.Context, userID string) domain.PlanTrack
+	// Choose the default track a user reads from
+	SelectTrack(ctx context.Context, userID string, trackID string) (domain.PlanTrack, error)
+	// Get a verse for a specific date from the user's active plan
 	GetVerseForDate(ctx context.Context, userID string, date time.Time) (domain.DailyVerse, error)
+	// Get the verses for a specific date from every plan the user is reading, active plan first
+	GetVersesForDate(ctx context.Context, userID string, date time.Time) ([]domain.DailyVerse, error)
+	// Make a plan the user's active plan
+	ActivatePlan(ctx context.Context, planID string, actor domain.Actor) (domain.ReadingPlan, error)
+	// Set the order of a plan among the user's concurrent plans
+	SetPlanPriority(ctx context.Context, planID string, priority int, actor domain.Actor) (domain.ReadingPlan, error)
 	// Get an enriched verse for a specific date
 	GetEnrichedVerseForDate(ctx context.Context, userID string, date time.Time, verseService VerseService) (domain.DailyVerse, error)
 	// Delete a plan by ID
-	DeletePlan(ctx context.Context, planID string, userID string) error
+	DeletePlan(ctx context.Context, planID string, actor domain.Actor) error
 	// Update a plan
-	UpdatePlan(ctx context.Context, plan domain.ReadingPlan, userID string) error
+	UpdatePlan(ctx context.Context, plan domain.ReadingPlan, actor domain.Actor) error
+	// Re-check a stored plan's references against the verse repository
+	RevalidatePlan(ctx context.Context, planID string) (PlanValidationReport, error)
+	// Re-check every stored plan's references against the verse repository
+	RevalidateAllPlans(ctx context.Context) ([]PlanValidationReport, error)
+	// List the revision history of a plan
+	ListPlanRevisions(ctx context.Context, planID string, actor domain.Actor) ([]domain.PlanRevision, error)
+	// Show the day-level differences between two revisions of a plan
+	DiffPlanRevisions(ctx context.Context, planID string, fromRevision int, toRevision int, actor domain.Actor) (domain.PlanDiff, error)
+	// Restore a plan to the state of an earlier revision
+	RollbackPlan(ctx context.Context, planID string, revision int, actor domain.Actor) (domain.ReadingPlan, error)
+}
+
+// PlanValidationReport summarizes the reference resolution state of a plan
+type PlanValidationReport struct {
+	PlanID          string   `json:"plan_id"`
+	Topic           string   `json:"topic"`
+	UserID          string   `json:"user_id"`
+	ResolvedDays    int      `json:"resolved_days"`
+	UnresolvedDays  []int    `json:"unresolved_days"`
+	UnverifiedDays  []int    `json:"unverified_days,omitempty"`
+	UnresolvedParts []string `json:"unresolved_references,omitempty"`
 }
 
 type planService struct {
-	planRepo  repository.PlanRepository
-	llmClient llm.LLMClient
-	modelName string // Model name from environment config
+	planRepo      repository.PlanRepository
+	revisionRepo  repository.PlanRevisionRepository // Append-only history of plan changes
+	userRepo      repository.UserRepository         // Looks up each user's chosen default track
+	llmClient     llm.LLMClient
+	verseService  VerseService            // Used to verify that generated references resolve to verse text
+	modelName     string                  // Model name from environment config
+	tracks        []domain.PlanTrack      // Default plan tracks; the first is the fallback
+	themeCalendar *calendar.ThemeCalendar // Picks each default plan's theme from its start date
 }
 
 // NewPlanService creates a new PlanService.
-func NewPlanService(repo repository.PlanRepository, llmClient llm.LLMClient, modelName string) PlanService {
+func NewPlanService(repo repository.PlanRepository, revisionRepo repository.PlanRevisionRepository, userRepo repository.UserRepository,
+	llmClient llm.LLMClient, verseService VerseService, modelName string, tracks []domain.PlanTrack, themeCalendar *calendar.ThemeCalendar) PlanService {
 	return &planService{
-		planRepo:  repo,
-		llmClient: llmClient,
-		modelName: modelName,
+		planRepo:      repo,
+		revisionRepo:  revisionRepo,
+		userRepo:      userRepo,
+		llmClient:     llmClient,
+		verseService:  verseService,
+		modelName:     modelName,
+		tracks:        tracks,
+		themeCalendar: themeCalendar,
 	}
 }
 
-func (c *planService) generateReadingPlan(ctx context.Context, topic string, durationDays int, targetAudience string) (domain.ReadingPlan, error) {
+// Reading time targets are met when a day's estimate falls within this fraction of the
+// target, or within minReadingToleranceMinutes for short targets
+const (
+	readingTimeTolerance       = 0.4
+	minReadingToleranceMinutes = 2.0
+)
+
+func (s *planService) generateReadingPlan(ctx context.Context, topic string, durationDays int, targetAudience string, minutesPerDay int) (domain.ReadingPlan, error) {
 	var plan domain.ReadingPlan // Return an empty plan on error
 
 	// --- Construct the prompt for plan generation ---
@@ -74,12 +134,19 @@ Output ONLY JSON: {"daily_verses": [{"day": 1, "reference": "John 1:1-5, Psalms
 
 Use "title" field for short title. NEVER include verse text.`, durationDays, topic, targetAudience)
 
+	if minutesPerDay > 0 {
+		systemPrompt += fmt.Sprintf(`
+
+Each day's reading should take about %d minutes, roughly %d words at a typical reading pace. Keep the daily lengths even: lengthen short days with surrounding verses and split long passages across days.`,
+			minutesPerDay, minutesPerDay*util.ReadingWordsPerMinute)
+	}
+
 	originalUserPrompt := fmt.Sprintf(`Create a %d-day reading plan on "%s". Return only the JSON object with verse references.`, durationDays, topic)
 	userPrompt := originalUserPrompt
 
 	// Use the model from config, not hardcoded
 	request := llm.ChatCompletionRequest{
-		Model: c.modelName, // Use the model configured in environment
+		Model: s.modelName, // Use the model configured in environment
 		Messages: []llm.Message{
 			{Role: "system", Content: systemPrompt},
 			{Role: "user", Content: userPrompt},
@@ -109,7 +176,7 @@ Use "title" field for short title. NEVER include verse text.`, durationDays, top
 			log.Printf("INFO: Retrying plan generation (attempt %d/%d) for topic '%s' due to validation errors.", retry+1, maxRetries, topic)
 		}
 
-		llmResponse, err := c.llmClient.CreateChatCompletion(ctx, request)
+		llmResponse, err := s.llmClient.CreateChatCompletion(metering.WithFeature(ctx, metering.FeaturePlan), request)
 		if err != nil {
 			lastError = fmt.Errorf("LLM completion failed (attempt %d/%d): %w", retry+1, maxRetries, err)
 			// Don't retry on API errors, return directly
@@ -175,29 +242,72 @@ Use "title" field for short title. NEVER include verse text.`, durationDays, top
 		}
 
 		// --- === CHECK VALIDATION RESULTS === ---
-		if len(invalidRefsWithErrors) == 0 {
-			// Success! Populate the plan and return
-			log.Printf("INFO: Successfully generated and validated reading plan for '%s' after %d attempt(s).", topic, retry+1)
-			plan.Topic = topic
-			plan.DurationDays = durationDays
-			plan.TargetAudience = targetAudience
-			plan.DailyVerses = planData.DailyVerses
-			return plan, nil // <<< SUCCESS EXIT
+		if len(invalidRefsWithErrors) > 0 {
+			// --- Validation Failed - Prepare for Retry ---
+			lastError = fmt.Errorf("validation failed (attempt %d/%d): %d invalid references found", retry+1, maxRetries, len(invalidRefsWithErrors))
+			log.Printf("WARN: %s. Invalid references: %v", lastError.Error(), invalidRefsWithErrors)
+
+			// Construct feedback prompt
+			feedback := "\n\nThe previous plan contained invalid or incorrectly formatted references. Please correct the following:\n"
+			for ref, reason := range invalidRefsWithErrors {
+				feedback += fmt.Sprintf("- '%s': %s\n", ref, reason)
+			}
+			feedback += "Ensure all references strictly follow the required formats ('Book Ch:V' or 'Book Ch:V-V') and are complete."
+			userPrompt = originalUserPrompt + feedback // Append feedback to original request
+			continue                                   // Retry
 		}
 
-		// --- Validation Failed - Prepare for Retry ---
-		lastError = fmt.Errorf("validation failed (attempt %d/%d): %d invalid references found", retry+1, maxRetries, len(invalidRefsWithErrors))
-		log.Printf("WARN: %s. Invalid references: %v", lastError.Error(), invalidRefsWithErrors)
+		// --- === RESOLUTION STEP === ---
+		// Syntactically valid references can still point at verses that don't exist
+		// (e.g., "Jude 2:1"), so check each one against the verse repository
+		unresolvedRefs := s.resolveDailyVerses(ctx, planData.DailyVerses)
+		if len(unresolvedRefs) > 0 {
+			lastError = fmt.Errorf("resolution failed (attempt %d/%d): %d references have no verse text", retry+1, maxRetries, len(unresolvedRefs))
+			log.Printf("WARN: %s. Unresolved references: %v", lastError.Error(), unresolvedRefs)
+
+			feedback := "\n\nThe previous plan contained references that do not exist in the Bible text. Please replace the following:\n"
+			for ref, day := range unresolvedRefs {
+				feedback += fmt.Sprintf("- '%s' (day %d)\n", ref, day)
+			}
+			feedback += "Check that each book has the chapter you cite and that each chapter has the verses you cite."
+			userPrompt = originalUserPrompt + feedback
+			continue // Retry
+		}
 
-		// Construct feedback prompt
-		feedback := "\n\nThe previous plan contained invalid or incorrectly formatted references. Please correct the following:\n"
-		for ref, reason := range invalidRefsWithErrors {
-			feedback += fmt.Sprintf("- '%s': %s\n", ref, reason)
+		// --- === READING LENGTH STEP === ---
+		s.measureDailyVerses(ctx, planData.DailyVerses)
+		if minutesPerDay > 0 {
+			outOfBand := readingLengthOutliers(planData.DailyVerses, minutesPerDay)
+			if len(outOfBand) > 0 {
+				// Uneven lengths aren't worth failing the plan over, so accept them on the last attempt
+				if retry < maxRetries-1 {
+					lastError = fmt.Errorf("reading length check failed (attempt %d/%d): %d days outside the target band", retry+1, maxRetries, len(outOfBand))
+					log.Printf("WARN: %s", lastError.Error())
+
+					feedback := fmt.Sprintf("\n\nThe previous plan had days that don't fit the %d-minute daily reading target. Please adjust:\n", minutesPerDay)
+					for _, dv := range outOfBand {
+						direction := "lengthen it"
+						if dv.EstimatedMinutes > float64(minutesPerDay) {
+							direction = "shorten it"
+						}
+						feedback += fmt.Sprintf("- Day %d ('%s') takes about %.1f minutes (%d words); %s\n", dv.DayNumber, dv.Reference, dv.EstimatedMinutes, dv.WordCount, direction)
+					}
+					feedback += fmt.Sprintf("Aim for roughly %d words per day.", minutesPerDay*util.ReadingWordsPerMinute)
+					userPrompt = originalUserPrompt + feedback
+					continue // Retry
+				}
+				log.Printf("WARN: Accepting plan for '%s' with %d days outside the %d-minute target after %d attempts", topic, len(outOfBand), minutesPerDay, maxRetries)
+			}
 		}
-		feedback += "Ensure all references strictly follow the required formats ('Book Ch:V' or 'Book Ch:V-V') and are complete."
-		userPrompt = originalUserPrompt + feedback // Append feedback to original request
 
-		// Loop continues for the next retry
+		// Success! Populate the plan and return
+		log.Printf("INFO: Successfully generated and validated reading plan for '%s' after %d attempt(s).", topic, retry+1)
+		plan.Topic = topic
+		plan.DurationDays = durationDays
+		plan.TargetAudience = targetAudience
+		plan.MinutesPerDay = minutesPerDay
+		plan.DailyVerses = planData.DailyVerses
+		return plan, nil // <<< SUCCESS EXIT
 	}
 
 	// If loop finishes, all retries failed
@@ -205,45 +315,26 @@ Use "title" field for short title. NEVER include verse text.`, durationDays, top
 	return plan, fmt.Errorf("failed to generate a valid reading plan after %d retries: %w", maxRetries, lastError)
 }
 
-func (s *planService) CreatePlan(ctx context.Context, userID string, topic string, durationDays int, targetAudience string) (domain.ReadingPlan, error) {
+func (s *planService) CreatePlan(ctx context.Context, userID string, topic string, durationDays int, targetAudience string, minutesPerDay int) (domain.ReadingPlan, error) {
 	if topic == "" || durationDays <= 0 || targetAudience == "" {
 		return domain.ReadingPlan{}, errors.New("topic, positive duration, and target audience are required")
 	}
 
-	// Check for existing user plans that might overlap with this new plan
-	// Only perform this check for regular users, not for the default plan
-	if userID != "default" {
-		// Calculate the date range for the new plan
-		starting := time.Now().Truncate(24 * time.Hour)  // Start today at midnight
-		ending := starting.AddDate(0, 0, durationDays-1) // End date is start + (duration-1) days
-
-		// Get existing user plans
+	// Users may read several plans at once; the first one becomes their active plan
+	makeActive := false
+	if userID != domain.DefaultPlanUserID {
 		existingPlans, err := s.planRepo.FindByUser(ctx, userID)
 		if err != nil {
 			log.Printf("ERROR: Failed to check existing user plans: %v", err)
 			return domain.ReadingPlan{}, fmt.Errorf("failed to check existing plans: %w", err)
 		}
-
-		// Check for date overlap with any existing plan
-		for _, existingPlan := range existingPlans {
-			// Check if the new plan's date range overlaps with an existing plan
-			// Two date ranges overlap if the start of one is before or equal to the end of the other,
-			// and the end of one is after or equal to the start of the other
-			if (starting.Before(existingPlan.EndDate) || starting.Equal(existingPlan.EndDate)) &&
-				(ending.After(existingPlan.StartDate) || ending.Equal(existingPlan.StartDate)) {
-				// Found an overlapping plan
-				log.Printf("WARN: User %s already has a plan '%s' (ID: %s) overlapping with the requested date range",
-					userID, existingPlan.Topic, existingPlan.ID)
-				return domain.ReadingPlan{}, fmt.Errorf("you already have a reading plan for this date range (topic: %s)",
-					existingPlan.Topic)
-			}
-		}
+		makeActive = !hasActivePlan(existingPlans)
 	}
 
-	log.Printf("INFO: Requesting LLM to generate plan for topic='%s', duration=%d days, audience='%s'", topic, durationDays, targetAudience)
+	log.Printf("INFO: Requesting LLM to generate plan for topic='%s', duration=%d days, audience='%s', minutes/day=%d", topic, durationDays, targetAudience, minutesPerDay)
 
 	// Generate the plan using the LLM
-	plan, err := s.generateReadingPlan(ctx, topic, durationDays, targetAudience)
+	plan, err := s.generateReadingPlan(ctx, topic, durationDays, targetAudience, minutesPerDay)
 	if err != nil {
 		log.Printf("ERROR: Failed to generate plan via LLM: %v", err)
 		return domain.ReadingPlan{}, fmt.Errorf("failed to generate reading plan: %w", err)
@@ -251,6 +342,7 @@ func (s *planService) CreatePlan(ctx context.Context, userID string, topic strin
 
 	// Set the user ID
 	plan.UserID = userID
+	plan.IsActive = makeActive
 
 	// Set calendar dates - ensure they're properly initialized
 	now := time.Now().Truncate(24 * time.Hour) // Start today at midnight
@@ -275,6 +367,10 @@ func (s *planService) CreatePlan(ctx context.Context, userID string, topic strin
 		return domain.ReadingPlan{}, fmt.Errorf("failed to save reading plan: %w", err)
 	}
 
+	if err := s.recordRevision(ctx, plan, userID, domain.RevisionActionCreate, ""); err != nil {
+		return domain.ReadingPlan{}, err
+	}
+
 	// The saved plan now has an ID and CreatedAt timestamp
 	// Refetch it to return the complete object (optional but good practice)
 	savedPlan, err := s.planRepo.FindByID(ctx, plan.ID.String())
@@ -297,94 +393,195 @@ func (s *planService) GetVerseForDate(ctx context.Context, userID string, date t
 	targetDate := date.Truncate(24 * time.Hour)
 
 	// First, try to find a user-specific plan that covers this date
-	plans, err := s.planRepo.FindByUser(ctx, userID)
+	plans, err := s.plansCoveringDate(ctx, userID, targetDate)
 	if err != nil {
-		log.Printf("ERROR: Failed to get user plans: %v", err)
-		return domain.DailyVerse{}, fmt.Errorf("could not retrieve plans: %w", err)
+		return domain.DailyVerse{}, err
 	}
 
 	var activePlan *domain.ReadingPlan
-
-	// Find a user plan that covers the target date
-	for _, plan := range plans {
-		// Log plan dates for debugging
-		log.Printf("DEBUG: Checking user plan %s with date range %s to %s against target date %s",
-			plan.ID, plan.StartDate.Format("2006-01-02"), plan.EndDate.Format("2006-01-02"), targetDate.Format("2006-01-02"))
-
-		// Check if plan covers target date (note: using not-before/not-after logic to be more inclusive)
-		if !targetDate.Before(plan.StartDate) && !targetDate.After(plan.EndDate) {
-			activePlan = plan
-			log.Printf("INFO: Found user-specific plan %s for user %s covering date %s",
-				activePlan.ID, userID, targetDate.Format("2006-01-02"))
-			break
-		}
+	if len(plans) > 0 {
+		activePlan = plans[0]
+		log.Printf("INFO: Found user-specific plan %s for user %s covering date %s",
+			activePlan.ID, userID, targetDate.Format("2006-01-02"))
 	}
 
-	// If no user plan found for this date, try default plans
+	// If no user plan found for this date, fall back to the user's default track
 	if activePlan == nil {
-		defaultPlans, err := s.planRepo.FindByUser(ctx, "default")
+		activePlan, err = s.findDefaultPlanForDate(ctx, userID, targetDate)
 		if err != nil {
-			log.Printf("ERROR: Failed to get default plans: %v", err)
-			return domain.DailyVerse{}, fmt.Errorf("could not retrieve default plans: %w", err)
+			return domain.DailyVerse{}, err
 		}
+	}
 
-		// Debug info about default plans
-		log.Printf("DEBUG: Found %d default plans. Target date: %s", len(defaultPlans), targetDate.Format("2006-01-02"))
-		for i, plan := range defaultPlans {
-			log.Printf("DEBUG: Default plan #%d: ID %s, Topic '%s', StartDate %s, EndDate %s",
-				i+1, plan.ID, plan.Topic, plan.StartDate.Format("2006-01-02"), plan.EndDate.Format("2006-01-02"))
+	if activePlan == nil {
+		return domain.DailyVerse{}, fmt.Errorf("no suitable reading plan found for date %s",
+			targetDate.Format("2006-01-02"))
+	}
+
+	return verseOnDate(activePlan, targetDate)
+}
+
+// GetVersesForDate returns the reading for a date from every user plan covering it, in the
+// order of plansCoveringDate. Users without a covering plan get their default track's reading.
+func (s *planService) GetVersesForDate(ctx context.Context, userID string, date time.Time) ([]domain.DailyVerse, error) {
+	targetDate := date.Truncate(24 * time.Hour)
+
+	plans, err := s.plansCoveringDate(ctx, userID, targetDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		defaultPlan, err := s.findDefaultPlanForDate(ctx, userID, targetDate)
+		if err != nil {
+			return nil, err
 		}
+		plans = append(plans, defaultPlan)
+	}
 
-		if len(defaultPlans) == 0 {
-			log.Println("INFO: No default or user reading plans found for this date.")
-			return domain.DailyVerse{}, fmt.Errorf("no reading plan found for date %s", targetDate.Format("2006-01-02"))
+	var verses []domain.DailyVerse
+	for _, plan := range plans {
+		verse, err := verseOnDate(plan, targetDate)
+		if err != nil {
+			// One plan with a missing day shouldn't hide the readings of the others
+			log.Printf("WARN: Skipping plan %s for date %s: %v", plan.ID, targetDate.Format("2006-01-02"), err)
+			continue
 		}
+		verses = append(verses, verse)
+	}
+	if len(verses) == 0 {
+		return nil, repository.ErrDayOutOfRange
+	}
+	return verses, nil
+}
 
-		// ALWAYS use the most recent default plan regardless of date ranges
-		// This ensures users always get a reading, even when date ranges don't match
-		activePlan = defaultPlans[0] // Always use the most recent default plan (they're sorted by creation date desc)
-		log.Printf("INFO: Using most recent default plan %s (topic: %s) for date %s",
-			activePlan.ID, activePlan.Topic, targetDate.Format("2006-01-02"))
+// plansCoveringDate returns the user's own plans whose date range includes the date:
+// the active plan first, then by descending priority, then newest first
+func (s *planService) plansCoveringDate(ctx context.Context, userID string, targetDate time.Time) ([]*domain.ReadingPlan, error) {
+	plans, err := s.planRepo.FindByUser(ctx, userID)
+	if err != nil {
+		log.Printf("ERROR: Failed to get user plans: %v", err)
+		return nil, fmt.Errorf("could not retrieve plans: %w", err)
 	}
 
-	if activePlan == nil {
-		return domain.DailyVerse{}, fmt.Errorf("no suitable reading plan found for date %s",
-			targetDate.Format("2006-01-02"))
+	var covering []*domain.ReadingPlan
+	for _, plan := range plans {
+		// Log plan dates for debugging
+		log.Printf("DEBUG: Checking user plan %s with date range %s to %s against target date %s",
+			plan.ID, plan.StartDate.Format("2006-01-02"), plan.EndDate.Format("2006-01-02"), targetDate.Format("2006-01-02"))
+
+		// Check if plan covers target date (note: using not-before/not-after logic to be more inclusive)
+		if !targetDate.Before(plan.StartDate) && !targetDate.After(plan.EndDate) {
+			covering = append(covering, plan)
+		}
 	}
 
+	// FindByUser returns newest first; a stable sort keeps that as the last tie-breaker
+	sort.SliceStable(covering, func(i, j int) bool {
+		if covering[i].IsActive != covering[j].IsActive {
+			return covering[i].IsActive
+		}
+		return covering[i].Priority > covering[j].Priority
+	})
+	return covering, nil
+}
+
+// verseOnDate returns the day of a plan that falls on the target date
+func verseOnDate(plan *domain.ReadingPlan, targetDate time.Time) (domain.DailyVerse, error) {
 	// Calculate which day of the plan it is
 	// If the date is before the plan starts, use day 1
 	// If the date is after the plan ends, use the last day
 	var dayNumber int
 
-	if targetDate.Before(activePlan.StartDate) {
+	if targetDate.Before(plan.StartDate) {
 		dayNumber = 1 // Use first day of plan
-	} else if targetDate.After(activePlan.EndDate) {
-		dayNumber = activePlan.DurationDays // Use last day of plan
+	} else if targetDate.After(plan.EndDate) {
+		dayNumber = plan.DurationDays // Use last day of plan
 	} else {
 		// Calculate days since start of plan (add 1 because day 1 is the start date)
-		dayNumber = int(targetDate.Sub(activePlan.StartDate).Hours()/24) + 1
+		dayNumber = int(targetDate.Sub(plan.StartDate).Hours()/24) + 1
 	}
 
 	log.Printf("INFO: For date %s, using day %d of plan %s (range %s to %s)",
 		targetDate.Format("2006-01-02"),
 		dayNumber,
-		activePlan.ID,
-		activePlan.StartDate.Format("2006-01-02"),
-		activePlan.EndDate.Format("2006-01-02"))
+		plan.ID,
+		plan.StartDate.Format("2006-01-02"),
+		plan.EndDate.Format("2006-01-02"))
 
 	// Get the verse for the calculated day number
-	verse, found := activePlan.GetVerseForDay(dayNumber)
+	verse, found := plan.GetVerseForDay(dayNumber)
 	if !found {
 		log.Printf("ERROR: Day %d not found in plan %s (has %d verses)",
-			dayNumber, activePlan.ID, len(activePlan.DailyVerses))
+			dayNumber, plan.ID, len(plan.DailyVerses))
 		return domain.DailyVerse{}, repository.ErrDayOutOfRange
 	}
 
 	log.Printf("INFO: Found verse for day %d, reference %s (%s)", dayNumber, verse.Reference, verse.Title)
+	verse.PlanID = plan.ID.String()
+	verse.PlanTopic = plan.Topic
 	return verse, nil
 }
 
+// hasActivePlan reports whether any of the plans is flagged active
+func hasActivePlan(plans []*domain.ReadingPlan) bool {
+	for _, plan := range plans {
+		if plan.IsActive {
+			return true
+		}
+	}
+	return false
+}
+
+// ActivatePlan makes a plan its owner's active plan, the one /today serves first
+func (s *planService) ActivatePlan(ctx context.Context, planID string, actor domain.Actor) (domain.ReadingPlan, error) {
+	plan, err := s.findOwnPlan(ctx, planID, actor)
+	if err != nil {
+		return domain.ReadingPlan{}, err
+	}
+
+	if err := s.planRepo.SetActive(ctx, plan.UserID, planID); err != nil {
+		return domain.ReadingPlan{}, fmt.Errorf("failed to activate plan: %w", err)
+	}
+
+	log.Printf("INFO: User %s activated plan %s", actor.UserID, planID)
+	plan.IsActive = true
+	return *plan, nil
+}
+
+// SetPlanPriority sets the order of a plan among its owner's concurrent plans
+func (s *planService) SetPlanPriority(ctx context.Context, planID string, priority int, actor domain.Actor) (domain.ReadingPlan, error) {
+	plan, err := s.findOwnPlan(ctx, planID, actor)
+	if err != nil {
+		return domain.ReadingPlan{}, err
+	}
+
+	if err := s.planRepo.SetPriority(ctx, planID, priority); err != nil {
+		return domain.ReadingPlan{}, fmt.Errorf("failed to set plan priority: %w", err)
+	}
+
+	plan.Priority = priority
+	return *plan, nil
+}
+
+// findOwnPlan loads a user plan the actor may modify. Default plans are shared
+// by every reader, so they can't be activated or reordered.
+func (s *planService) findOwnPlan(ctx context.Context, planID string, actor domain.Actor) (*domain.ReadingPlan, error) {
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding plan: %w", err)
+	}
+	if plan == nil {
+		return nil, errors.New("plan not found")
+	}
+	if plan.UserID == domain.DefaultPlanUserID {
+		return nil, errors.New("default plans cannot be activated or reordered")
+	}
+	if !canModifyPlan(plan, actor) {
+		return nil, errors.New("unauthorized: cannot change another user's plan")
+	}
+	return plan, nil
+}
+
 func (s *planService) ListPlans(ctx context.Context, userID string) ([]domain.ReadingPlan, error) {
 	// Get only user-specific plans
 	userPlans, err := s.planRepo.FindByUser(ctx, userID)
@@ -401,6 +598,38 @@ func (s *planService) ListPlans(ctx context.Context, userID string) ([]domain.Re
 	return result, nil
 }
 
+// GetPlan returns a plan the actor may read
+func (s *planService) GetPlan(ctx context.Context, planID string, actor domain.Actor) (domain.ReadingPlan, error) {
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil {
+		return domain.ReadingPlan{}, fmt.Errorf("error finding plan: %w", err)
+	}
+	if plan == nil {
+		return domain.ReadingPlan{}, errors.New("plan not found")
+	}
+
+	if !canReadPlan(plan, actor) {
+		return domain.ReadingPlan{}, errors.New("unauthorized: cannot access another user's plan")
+	}
+	return *plan, nil
+}
+
+// GetPlanDay returns one day of a plan the actor may read, so readers can read ahead or revisit past days
+func (s *planService) GetPlanDay(ctx context.Context, planID string, dayNumber int, actor domain.Actor) (domain.DailyVerse, error) {
+	plan, err := s.GetPlan(ctx, planID, actor)
+	if err != nil {
+		return domain.DailyVerse{}, err
+	}
+
+	verse, found := plan.GetVerseForDay(dayNumber)
+	if !found {
+		return domain.DailyVerse{}, repository.ErrDayOutOfRange
+	}
+	verse.PlanID = plan.ID.String()
+	verse.PlanTopic = plan.Topic
+	return verse, nil
+}
+
 // GetEnrichedVerseForToday gets today's verse and enriches it with full text content on-demand
 func (s *planService) GetEnrichedVerseForToday(ctx context.Context, userID string, verseService VerseService) (domain.DailyVerse, error) {
 	// Use the current date
@@ -425,80 +654,46 @@ func (s *planService) GetEnrichedVerseForDate(ctx context.Context, userID string
 	return enrichedVerse, nil
 }
 
-// StartWeeklyPlanScheduler starts a background process that periodically checks
-// if a new default plan needs to be generated, using the yearly theme from config
-func StartWeeklyPlanScheduler(planService PlanService, cfg *config.Config) {
-	// Run immediately to ensure we have a plan
-	go func() {
-		// Get yearly theme and target audience from config
-		yearlyTheme := cfg.YearlyTheme
-		targetAudience := cfg.DefaultTargetAudience
-
-		log.Printf("INFO: Using yearly theme: %s and target audience: %s", yearlyTheme, targetAudience)
-
-		ctx := context.Background()
-		err := planService.EnsureDefaultWeeklyPlan(ctx, yearlyTheme, targetAudience)
-		if err != nil {
-			log.Printf("ERROR: Failed initial default plan generation: %v", err)
-		}
-	}()
-
-	// Schedule a weekly check on Sundays
-	go func() {
-		for {
-			// Calculate time until next Sunday
-			now := time.Now()
-			currentDay := int(now.Weekday())
-			daysUntilSunday := 0
-
-			// If today is not Sunday (0), calculate days until next Sunday
-			if currentDay != 0 {
-				daysUntilSunday = 7 - currentDay
-			} else {
-				// If today is Sunday but we've already passed our usual check time,
-				// wait until next Sunday
-				if now.Hour() >= 2 { // Assuming we want to run at 2 AM
-					daysUntilSunday = 7
-				}
-			}
+// DefaultPlanJobName identifies the default plan generation job in the scheduler
+const DefaultPlanJobName = "default-plans"
+
+// NewDefaultPlanJob returns the scheduled job that keeps every default track supplied with a current plan.
+// The check is cheap when plans exist, so a daily schedule keeps tracks of any cadence covered.
+func NewDefaultPlanJob(planService PlanService, schedule string) scheduler.Job {
+	return scheduler.Job{
+		Name:     DefaultPlanJobName,
+		Schedule: schedule,
+		Timeout:  30 * time.Minute,
+		// Without this a fresh deploy has no default plan until the first scheduled run
+		DueOnCreate: true,
+		Run:         planService.EnsureDefaultPlans,
+	}
+}
 
-			// Calculate next Sunday at 2 AM
-			nextSunday := time.Date(
-				now.Year(), now.Month(), now.Day()+daysUntilSunday,
-				2, 0, 0, 0, now.Location(),
-			)
-
-			// Calculate duration to sleep
-			timeUntilNextSunday := nextSunday.Sub(now)
-			log.Printf("INFO: Next default plan check scheduled for %s (in %s)",
-				nextSunday.Format(time.RFC3339), timeUntilNextSunday)
-
-			// Sleep until next Sunday
-			time.Sleep(timeUntilNextSunday)
-
-			// Use yearly theme and target audience from config
-			yearlyTheme := cfg.YearlyTheme
-			targetAudience := cfg.DefaultTargetAudience
-
-			// Generate plan on Sunday
-			log.Printf("INFO: Sunday check - generating default plan if needed")
-			ctx := context.Background()
-			err := planService.EnsureDefaultWeeklyPlan(ctx, yearlyTheme, targetAudience)
-			if err != nil {
-				log.Printf("ERROR: Failed scheduled default plan generation: %v", err)
-			}
+// EnsureDefaultPlans makes sure every configured track has a plan covering today.
+// Tracks are handled independently so one failing track doesn't starve the others.
+func (s *planService) EnsureDefaultPlans(ctx context.Context) error {
+	var failed []string
+	for _, track := range s.tracks {
+		if err := s.EnsureDefaultTrackPlan(ctx, track); err != nil {
+			log.Printf("ERROR: Failed to ensure default plan for track '%s': %v", track.ID, err)
+			failed = append(failed, track.ID)
 		}
-	}()
+	}
 
-	log.Printf("INFO: Sunday default plan generation scheduler started")
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to ensure default plans for tracks: %s", strings.Join(failed, ", "))
+	}
+	return nil
 }
 
-// EnsureDefaultWeeklyPlan checks if a current default plan exists and is valid for the current date
-// If no valid plan exists, it generates a new 7-day default plan based on the yearly theme
-// It ensures topics don't repeat by tracking previous topics
-func (s *planService) EnsureDefaultWeeklyPlan(ctx context.Context, yearlyTheme string, targetAudience string) error {
-	// Get existing default plans
-	defaultPlans, err := s.planRepo.FindByUser(ctx, "default")
+// EnsureDefaultTrackPlan checks if a current default plan exists for the track and is valid for the current date
+// If no valid plan exists, it generates a new plan of the track's cadence based on the calendar theme
+// for the start date, falling back to the track's theme
+// It ensures topics don't repeat by tracking the track's previous topics
+func (s *planService) EnsureDefaultTrackPlan(ctx context.Context, track domain.PlanTrack) error {
+	// Get existing default plans for this track
+	defaultPlans, err := s.planRepo.FindDefaultByTrack(ctx, track.ID, s.isPrimaryTrack(track.ID))
 	if err != nil {
 		return fmt.Errorf("failed to check existing default plans: %w", err)
 	}
@@ -512,14 +707,15 @@ func (s *planService) EnsureDefaultWeeklyPlan(ctx context.Context, yearlyTheme s
 		latestPlan := defaultPlans[0] // Plans are already sorted by CreateAt desc
 		today := time.Now().Truncate(24 * time.Hour)
 
-		log.Printf("DEBUG: Checking if existing default plan covers today - plan dates: %s to %s, today: %s",
-			latestPlan.StartDate.Format("2006-01-02"), latestPlan.EndDate.Format("2006-01-02"), today.Format("2006-01-02"))
+		log.Printf("DEBUG: Checking if existing default plan for track '%s' covers today - plan dates: %s to %s, today: %s",
+			track.ID, latestPlan.StartDate.Format("2006-01-02"), latestPlan.EndDate.Format("2006-01-02"), today.Format("2006-01-02"))
 
 		// Use not-before/not-after logic for more reliable date comparison
 		if !today.Before(latestPlan.StartDate) && !today.After(latestPlan.EndDate) {
 			// Today is within the plan's date range, no need for a new one
-			log.Printf("INFO: Current default plan %s is valid for today (date range %s to %s)",
+			log.Printf("INFO: Current default plan %s for track '%s' is valid for today (date range %s to %s)",
 				latestPlan.ID,
+				track.ID,
 				latestPlan.StartDate.Format("2006-01-02"),
 				latestPlan.EndDate.Format("2006-01-02"))
 			needNewPlan = false
@@ -536,36 +732,43 @@ func (s *planService) EnsureDefaultWeeklyPlan(ctx context.Context, yearlyTheme s
 		return nil
 	}
 
+	// The plan starts today, so today's calendar theme applies
+	startDate := time.Now().Truncate(24 * time.Hour) // Start today at midnight
+	theme := s.themeCalendar.ThemeFor(startDate, track.ID, track.Theme)
+
 	// Generate a new default plan
-	log.Printf("INFO: Generating new default weekly plan based on yearly theme: %s", yearlyTheme)
+	log.Printf("INFO: Generating new default plan for track '%s' based on theme: %s (season: %s, source: %s)",
+		track.ID, theme.Name, theme.Season.Name(), theme.Source)
 
-	// Generate a topic related to the yearly theme that hasn't been used before
-	topic, err := s.generateNewTopicFromTheme(ctx, yearlyTheme, previousTopics)
+	// Generate a topic related to the theme that hasn't been used before
+	topic, err := s.generateNewTopicFromTheme(ctx, theme, track.CadenceDays, previousTopics)
 	if err != nil {
 		return fmt.Errorf("failed to generate new topic: %w", err)
 	}
 
-	// Create a 7-day plan with the generated topic
-	plan, err := s.generateReadingPlan(ctx, topic, 7, targetAudience)
+	// Create a plan covering one cadence period with the generated topic
+	plan, err := s.generateReadingPlan(ctx, topic, track.CadenceDays, track.TargetAudience, 0)
 	if err != nil {
 		return fmt.Errorf("failed to generate default reading plan: %w", err)
 	}
 
-	// Set it as a default plan - ensure the exact string "default" is used
-	plan.UserID = "default"
+	// Set it as a default plan of this track - ensure the exact string "default" is used
+	plan.UserID = domain.DefaultPlanUserID
+	plan.TrackID = track.ID
+	plan.Theme = theme.Name
+	plan.Season = string(theme.Season)
 
 	// Set calendar dates for the plan
-	now := time.Now().Truncate(24 * time.Hour) // Start today at midnight
-	plan.StartDate = now
-	plan.EndDate = now.AddDate(0, 0, 6) // 7-day plan (0-6)
+	plan.StartDate = startDate
+	plan.EndDate = startDate.AddDate(0, 0, track.CadenceDays-1)
 
 	// Verify dates are set (debug only)
 	log.Printf("DEBUG: New default plan date range: %s to %s",
 		plan.StartDate.Format("2006-01-02"), plan.EndDate.Format("2006-01-02"))
 
 	// Log key details before saving
-	log.Printf("DEBUG: Saving new default plan - UserID: '%s', ID: %s, Topic: '%s'",
-		plan.UserID, plan.ID, plan.Topic)
+	log.Printf("DEBUG: Saving new default plan - UserID: '%s', TrackID: '%s', ID: %s, Topic: '%s'",
+		plan.UserID, plan.TrackID, plan.ID, plan.Topic)
 
 	// Save the plan
 	err = s.planRepo.Save(ctx, &plan)
@@ -573,38 +776,123 @@ func (s *planService) EnsureDefaultWeeklyPlan(ctx context.Context, yearlyTheme s
 		return fmt.Errorf("failed to save default plan: %w", err)
 	}
 
-	// Verify the plan was saved correctly by retrieving it
-	defaultPlans, verifyErr := s.planRepo.FindByUser(ctx, "default")
-	if verifyErr != nil {
-		log.Printf("WARN: Could not verify default plan after saving: %v", verifyErr)
-	} else if len(defaultPlans) == 0 {
-		log.Printf("ERROR: Default plan not found after saving! Verification failed.")
-	} else {
-		log.Printf("INFO: Verified default plan was saved correctly. Found %d default plans.", len(defaultPlans))
+	if err := s.recordRevision(ctx, plan, domain.RevisionAuthorSystem, domain.RevisionActionCreate, fmt.Sprintf("Generated default plan for track '%s'", track.ID)); err != nil {
+		return err
 	}
 
-	log.Printf("INFO: Successfully created new default plan with topic '%s' and ID %s", topic, plan.ID)
+	log.Printf("INFO: Successfully created new default plan for track '%s' with topic '%s' and ID %s", track.ID, topic, plan.ID)
 	return nil
 }
 
-// generateNewTopicFromTheme uses the LLM to generate a new topic based on the yearly theme
-// It avoids topics that have been used before
-func (s *planService) generateNewTopicFromTheme(ctx context.Context, yearlyTheme string, previousTopics []string) (string, error) {
+// ListTracks returns the configured default plan tracks
+func (s *planService) ListTracks() []domain.PlanTrack {
+	return s.tracks
+}
+
+// SelectTrack records which default track a user reads when they have no plan of their own
+func (s *planService) SelectTrack(ctx context.Context, userID string, trackID string) (domain.PlanTrack, error) {
+	track, ok := s.findTrack(trackID)
+	if !ok {
+		return domain.PlanTrack{}, errors.New("track not found")
+	}
+
+	if err := s.userRepo.UpdateTrack(ctx, userID, trackID); err != nil {
+		return domain.PlanTrack{}, fmt.Errorf("failed to save track selection: %w", err)
+	}
+
+	log.Printf("INFO: User %s selected default track '%s'", userID, trackID)
+	return track, nil
+}
+
+// GetUserTrack returns the user's chosen track, or the primary track if they haven't
+// chosen one or their choice is no longer configured
+func (s *planService) GetUserTrack(ctx context.Context, userID string) domain.PlanTrack {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		log.Printf("WARN: Could not look up track selection of user %s: %v", userID, err)
+	}
+
+	if user != nil && user.TrackID != "" {
+		if track, ok := s.findTrack(user.TrackID); ok {
+			return track
+		}
+		log.Printf("WARN: User %s selected unknown track '%s', using '%s'", userID, user.TrackID, s.tracks[0].ID)
+	}
+	return s.tracks[0]
+}
+
+// findDefaultPlanForDate picks the default plan of the user's track for a date,
+// preferring one that covers the date and otherwise the most recent one
+func (s *planService) findDefaultPlanForDate(ctx context.Context, userID string, targetDate time.Time) (*domain.ReadingPlan, error) {
+	track := s.GetUserTrack(ctx, userID)
+
+	defaultPlans, err := s.planRepo.FindDefaultByTrack(ctx, track.ID, s.isPrimaryTrack(track.ID))
+	if err != nil {
+		log.Printf("ERROR: Failed to get default plans: %v", err)
+		return nil, fmt.Errorf("could not retrieve default plans: %w", err)
+	}
+
+	// Debug info about default plans
+	log.Printf("DEBUG: Found %d default plans for track '%s'. Target date: %s", len(defaultPlans), track.ID, targetDate.Format("2006-01-02"))
+
+	if len(defaultPlans) == 0 {
+		log.Printf("INFO: No default plans found for track '%s' or user plans for this date.", track.ID)
+		return nil, fmt.Errorf("no reading plan found for date %s", targetDate.Format("2006-01-02"))
+	}
+
+	for _, plan := range defaultPlans {
+		if !targetDate.Before(plan.StartDate) && !targetDate.After(plan.EndDate) {
+			log.Printf("INFO: Using default plan %s (track: %s, topic: %s) for date %s",
+				plan.ID, track.ID, plan.Topic, targetDate.Format("2006-01-02"))
+			return plan, nil
+		}
+	}
+
+	// Use the most recent plan of the track even when date ranges don't match,
+	// so users always get a reading
+	log.Printf("INFO: Using most recent default plan %s (track: %s, topic: %s) for date %s",
+		defaultPlans[0].ID, track.ID, defaultPlans[0].Topic, targetDate.Format("2006-01-02"))
+	return defaultPlans[0], nil
+}
+
+func (s *planService) findTrack(trackID string) (domain.PlanTrack, bool) {
+	for _, track := range s.tracks {
+		if track.ID == trackID {
+			return track, true
+		}
+	}
+	return domain.PlanTrack{}, false
+}
+
+// isPrimaryTrack reports whether default plans saved before tracks existed belong to the track
+func (s *planService) isPrimaryTrack(trackID string) bool {
+	return len(s.tracks) > 0 && s.tracks[0].ID == trackID
+}
+
+// generateNewTopicFromTheme uses the LLM to generate a new topic based on the calendar theme
+// for the plan's start date. It avoids topics that have been used before
+func (s *planService) generateNewTopicFromTheme(ctx context.Context, theme calendar.Theme, durationDays int, previousTopics []string) (string, error) {
 	// Convert the previous topics to a string for the prompt
 	previousTopicsStr := strings.Join(previousTopics, ", ")
 
-	// Create prompt for the LLM
-	systemPrompt := fmt.Sprintf(`You are helping to generate a topic for a 7-day Bible reading plan.
+	// Mention the church season so topics follow the church year
+	seasonNote := ""
+	if theme.Season != "" && theme.Season != calendar.SeasonOrdinaryTime {
+		seasonNote = fmt.Sprintf("\nThe plan starts during the church season of %s; choose a topic fitting for that season.\n", theme.Season.Name())
+	}
 
-The yearly theme is: "%s"
+	// Create prompt for the LLM
+	systemPrompt := fmt.Sprintf(`You are helping to generate a topic for a %d-day Bible reading plan.
 
+The theme is: "%s"
+%s
 Previously used topics: %s
 
-Please suggest a specific, focused topic related to the yearly theme that hasn't been used before. It can be studying a specific book of the Bible or a character in the Bible or a story from the Bible etc.
+Please suggest a specific, focused topic related to the theme that hasn't been used before. It can be studying a specific book of the Bible or a character in the Bible or a story from the Bible etc.
 Your response should be ONLY the topic name, nothing else. Keep it concise (3-7 words).`,
-		yearlyTheme, previousTopicsStr)
+		durationDays, theme.Name, seasonNote, previousTopicsStr)
 
-	userPrompt := "Generate a new Bible reading plan topic based on the yearly theme."
+	userPrompt := "Generate a new Bible reading plan topic based on the theme."
 
 	// Create the LLM request
 	request := llm.ChatCompletionRequest{
@@ -618,7 +906,7 @@ Your response should be ONLY the topic name, nothing else. Keep it concise (3-7
 	}
 
 	// Get response from LLM
-	llmResponse, err := s.llmClient.CreateChatCompletion(ctx, request)
+	llmResponse, err := s.llmClient.CreateChatCompletion(metering.WithFeature(ctx, metering.FeatureTopic), request)
 	if err != nil {
 		return "", fmt.Errorf("LLM completion failed during topic generation: %w", err)
 	}
@@ -637,7 +925,7 @@ Your response should be ONLY the topic name, nothing else. Keep it concise (3-7
 }
 
 // DeletePlan deletes a plan by ID, ensures the user has permission
-func (s *planService) DeletePlan(ctx context.Context, planID string, userID string) error {
+func (s *planService) DeletePlan(ctx context.Context, planID string, actor domain.Actor) error {
 	// First check if the plan exists and belongs to this user
 	plan, err := s.planRepo.FindByID(ctx, planID)
 	if err != nil {
@@ -648,8 +936,8 @@ func (s *planService) DeletePlan(ctx context.Context, planID string, userID stri
 		return errors.New("plan not found")
 	}
 
-	// Verify ownership or admin status
-	if plan.UserID != userID && userID != "admin" {
+	// Verify ownership, or permission to manage everyone's plans
+	if plan.UserID != actor.UserID && !actor.Can(domain.PermManagePlans) {
 		return errors.New("unauthorized: cannot delete another user's plan")
 	}
 
@@ -658,7 +946,7 @@ func (s *planService) DeletePlan(ctx context.Context, planID string, userID stri
 }
 
 // UpdatePlan updates an existing plan, ensures the user has permission
-func (s *planService) UpdatePlan(ctx context.Context, plan domain.ReadingPlan, userID string) error {
+func (s *planService) UpdatePlan(ctx context.Context, plan domain.ReadingPlan, actor domain.Actor) error {
 	// First check if the plan exists and belongs to this user
 	existingPlan, err := s.planRepo.FindByID(ctx, plan.ID.String())
 	if err != nil {
@@ -669,14 +957,247 @@ func (s *planService) UpdatePlan(ctx context.Context, plan domain.ReadingPlan, u
 		return errors.New("plan not found")
 	}
 
-	// Verify ownership or admin status
-	if existingPlan.UserID != userID && userID != "admin" {
+	// Verify ownership or editor status
+	if !canModifyPlan(existingPlan, actor) {
 		return errors.New("unauthorized: cannot update another user's plan")
 	}
 
-	// Update the plan (keep original user ID and creation date)
+	// Plans saved before revisions were recorded get their current state logged first,
+	// so the edit below can always be rolled back
+	if err := s.ensureBaselineRevision(ctx, existingPlan); err != nil {
+		return err
+	}
+
+	// Update the plan (keep original user ID, creation date and calendar dates)
 	plan.UserID = existingPlan.UserID
 	plan.CreatedAt = existingPlan.CreatedAt
+	plan.StartDate = existingPlan.StartDate
+	plan.EndDate = existingPlan.EndDate
+
+	// Fields the edit form doesn't send are kept rather than cleared
+	if plan.TargetAudience == "" {
+		plan.TargetAudience = existingPlan.TargetAudience
+	}
+	if plan.MinutesPerDay == 0 {
+		plan.MinutesPerDay = existingPlan.MinutesPerDay
+	}
+	keepCachedDevotionals(&plan, existingPlan)
+	keepCachedStudy(&plan, existingPlan)
+	if err := s.checkEditedDays(ctx, &plan, existingPlan); err != nil {
+		return err
+	}
 
+	if err := s.recordRevision(ctx, plan, actor.UserID, domain.RevisionActionUpdate, ""); err != nil {
+		return err
+	}
 	return s.planRepo.Save(ctx, &plan)
 }
+
+// canReadPlan reports whether an actor may read a plan: their own plans, default plans, or any plan for editors
+func canReadPlan(plan *domain.ReadingPlan, actor domain.Actor) bool {
+	return plan.UserID == actor.UserID || plan.UserID == domain.DefaultPlanUserID || actor.Can(domain.PermEditAnyPlan)
+}
+
+// canModifyPlan reports whether an actor may change a plan's content
+func canModifyPlan(plan *domain.ReadingPlan, actor domain.Actor) bool {
+	return plan.UserID == actor.UserID || actor.Can(domain.PermEditAnyPlan)
+}
+
+// checkEditedDays resolves and measures the days whose reference differs from the stored plan,
+// keeping the stored results for the rest. Like generation, it refuses malformed references
+// and references without verse text.
+func (s *planService) checkEditedDays(ctx context.Context, plan *domain.ReadingPlan, existing *domain.ReadingPlan) error {
+	var changed []int
+	for i := range plan.DailyVerses {
+		day := &plan.DailyVerses[i]
+		previous, found := existing.GetVerseForDay(day.DayNumber)
+		if found && previous.Reference == day.Reference {
+			day.ResolutionStatus = previous.ResolutionStatus
+			day.ResolutionError = previous.ResolutionError
+			day.WordCount = previous.WordCount
+			day.EstimatedMinutes = previous.EstimatedMinutes
+			continue
+		}
+
+		if strings.TrimSpace(day.Reference) == "" {
+			return fmt.Errorf("invalid plan: day %d has no reference", day.DayNumber)
+		}
+		for _, singleRef := range strings.Split(day.Reference, ",") {
+			trimmedRef := strings.TrimSpace(singleRef)
+			if trimmedRef == "" {
+				continue
+			}
+			if isValid, validationErr := util.IsValidReference(trimmedRef); !isValid {
+				return fmt.Errorf("invalid plan: '%s' (day %d): %v", trimmedRef, day.DayNumber, validationErr)
+			}
+		}
+		changed = append(changed, i)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	days := make([]domain.DailyVerse, len(changed))
+	for j, i := range changed {
+		days[j] = plan.DailyVerses[i]
+	}
+	unresolvedRefs := s.resolveDailyVerses(ctx, days)
+	if len(unresolvedRefs) > 0 {
+		refs := make([]string, 0, len(unresolvedRefs))
+		for ref, day := range unresolvedRefs {
+			refs = append(refs, fmt.Sprintf("%s (day %d)", ref, day))
+		}
+		sort.Strings(refs)
+		return fmt.Errorf("invalid plan: no verse text for %s", strings.Join(refs, ", "))
+	}
+	s.measureDailyVerses(ctx, days)
+
+	for j, i := range changed {
+		plan.DailyVerses[i] = days[j]
+	}
+	return nil
+}
+
+// resolveDailyVerses records a resolution status on each day and returns the
+// unresolved reference parts mapped to the day they appeared on.
+// Lookup failures (as opposed to missing verses) mark the day unverified rather
+// than failing generation, since the LLM can't fix a database outage.
+func (s *planService) resolveDailyVerses(ctx context.Context, verses []domain.DailyVerse) map[string]int {
+	unresolvedRefs := make(map[string]int)
+
+	for i := range verses {
+		unresolved, err := s.verseService.ResolveReference(ctx, verses[i].Reference)
+		if err != nil {
+			log.Printf("WARN: Could not verify reference '%s' for day %d: %v", verses[i].Reference, verses[i].DayNumber, err)
+			verses[i].ResolutionStatus = domain.ResolutionStatusUnverified
+			verses[i].ResolutionError = ""
+			continue
+		}
+
+		if len(unresolved) > 0 {
+			verses[i].ResolutionStatus = domain.ResolutionStatusUnresolved
+			verses[i].ResolutionError = "no verse text for " + strings.Join(unresolved, ", ")
+			for _, ref := range unresolved {
+				unresolvedRefs[ref] = verses[i].DayNumber
+			}
+			continue
+		}
+
+		verses[i].ResolutionStatus = domain.ResolutionStatusResolved
+		verses[i].ResolutionError = ""
+	}
+
+	return unresolvedRefs
+}
+
+// RevalidatePlan re-checks a stored plan's references and saves the per-day statuses
+func (s *planService) RevalidatePlan(ctx context.Context, planID string) (PlanValidationReport, error) {
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil {
+		return PlanValidationReport{}, fmt.Errorf("error finding plan before revalidation: %w", err)
+	}
+
+	if plan == nil {
+		return PlanValidationReport{}, errors.New("plan not found")
+	}
+
+	return s.revalidate(ctx, plan)
+}
+
+// RevalidateAllPlans re-checks every stored plan. A failure on one plan is
+// logged and skipped so a single bad document doesn't block the rest.
+func (s *planService) RevalidateAllPlans(ctx context.Context) ([]PlanValidationReport, error) {
+	plans, err := s.planRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve plans for revalidation: %w", err)
+	}
+
+	reports := make([]PlanValidationReport, 0, len(plans))
+	for _, plan := range plans {
+		report, err := s.revalidate(ctx, plan)
+		if err != nil {
+			log.Printf("ERROR: Failed to revalidate plan %s: %v", plan.ID, err)
+			continue
+		}
+		reports = append(reports, report)
+	}
+
+	log.Printf("INFO: Revalidated %d/%d stored plans", len(reports), len(plans))
+	return reports, nil
+}
+
+// revalidate resolves a plan's references, persists the statuses and builds the report
+func (s *planService) revalidate(ctx context.Context, plan *domain.ReadingPlan) (PlanValidationReport, error) {
+	unresolvedRefs := s.resolveDailyVerses(ctx, plan.DailyVerses)
+	s.measureDailyVerses(ctx, plan.DailyVerses)
+
+	// Only the statuses are written, so devotionals and studies cached meanwhile survive
+	for _, dv := range plan.DailyVerses {
+		if err := s.planRepo.SetDayStatus(ctx, plan.ID.String(), dv); err != nil {
+			return PlanValidationReport{}, fmt.Errorf("failed to save revalidated plan: %w", err)
+		}
+	}
+
+	report := PlanValidationReport{
+		PlanID:         plan.ID.String(),
+		Topic:          plan.Topic,
+		UserID:         plan.UserID,
+		UnresolvedDays: []int{},
+	}
+	for _, dv := range plan.DailyVerses {
+		switch dv.ResolutionStatus {
+		case domain.ResolutionStatusResolved:
+			report.ResolvedDays++
+		case domain.ResolutionStatusUnresolved:
+			report.UnresolvedDays = append(report.UnresolvedDays, dv.DayNumber)
+		default:
+			report.UnverifiedDays = append(report.UnverifiedDays, dv.DayNumber)
+		}
+	}
+	for ref := range unresolvedRefs {
+		report.UnresolvedParts = append(report.UnresolvedParts, ref)
+	}
+	sort.Strings(report.UnresolvedParts)
+
+	if len(report.UnresolvedDays) > 0 {
+		log.Printf("WARN: Plan %s has %d unresolved days: %v", plan.ID, len(report.UnresolvedDays), report.UnresolvedDays)
+	}
+	return report, nil
+}
+
+// measureDailyVerses fills in the word count and estimated reading time for each day.
+// Days whose text can't be fetched are left unmeasured.
+func (s *planService) measureDailyVerses(ctx context.Context, verses []domain.DailyVerse) {
+	for i := range verses {
+		words, err := s.verseService.CountWords(ctx, verses[i].Reference)
+		if err != nil {
+			log.Printf("WARN: Could not measure reading length of '%s' for day %d: %v", verses[i].Reference, verses[i].DayNumber, err)
+			verses[i].WordCount = 0
+			verses[i].EstimatedMinutes = 0
+			continue
+		}
+		verses[i].WordCount = words
+		verses[i].EstimatedMinutes = util.EstimateReadingMinutes(words)
+	}
+}
+
+// readingLengthOutliers returns the measured days whose estimated reading time falls
+// outside the tolerance band around the target
+func readingLengthOutliers(verses []domain.DailyVerse, minutesPerDay int) []domain.DailyVerse {
+	target := float64(minutesPerDay)
+	tolerance := target * readingTimeTolerance
+	if tolerance < minReadingToleranceMinutes {
+		tolerance = minReadingToleranceMinutes
+	}
+
+	var outliers []domain.DailyVerse
+	for _, dv := range verses {
+		if dv.WordCount == 0 {
+			continue // Unmeasured, nothing to compare
+		}
+		if dv.EstimatedMinutes < target-tolerance || dv.EstimatedMinutes > target+tolerance {
+			outliers = append(outliers, dv)
+		}
+	}
+	return outliers
+}
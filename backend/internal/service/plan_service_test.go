@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlanRepository keeps plans in memory; operations a test doesn't need are left unimplemented
+type fakePlanRepository struct {
+	repository.PlanRepository
+	plans map[string]*domain.ReadingPlan
+}
+
+func newFakePlanRepository(plans ...*domain.ReadingPlan) *fakePlanRepository {
+	repo := &fakePlanRepository{plans: make(map[string]*domain.ReadingPlan)}
+	for _, plan := range plans {
+		repo.plans[plan.ID.String()] = plan
+	}
+	return repo
+}
+
+func (r *fakePlanRepository) FindByID(ctx context.Context, id string) (*domain.ReadingPlan, error) {
+	plan, ok := r.plans[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *plan
+	copied.DailyVerses = append([]domain.DailyVerse(nil), plan.DailyVerses...)
+	return &copied, nil
+}
+
+// FindByUser returns the user's plans newest first, like the Mongo repository
+func (r *fakePlanRepository) FindByUser(ctx context.Context, userID string) ([]*domain.ReadingPlan, error) {
+	plans := []*domain.ReadingPlan{}
+	for _, plan := range r.plans {
+		if plan.UserID == userID {
+			plans = append(plans, plan)
+		}
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].CreatedAt.After(plans[j].CreatedAt) })
+	return plans, nil
+}
+
+// FindDefaultByTrack returns the track's default plans newest first, like the Mongo repository
+func (r *fakePlanRepository) FindDefaultByTrack(ctx context.Context, trackID string, includeUntracked bool) ([]*domain.ReadingPlan, error) {
+	plans, _ := r.FindByUser(ctx, domain.DefaultPlanUserID)
+	tracked := []*domain.ReadingPlan{}
+	for _, plan := range plans {
+		if plan.TrackID == trackID || (includeUntracked && plan.TrackID == "") {
+			tracked = append(tracked, plan)
+		}
+	}
+	return tracked, nil
+}
+
+// SetDayExplanation stores a day's devotional, leaving an existing one alone with onlyIfEmpty
+func (r *fakePlanRepository) SetDayExplanation(ctx context.Context, planID string, dayNumber int, explanation string, onlyIfEmpty bool) (bool, error) {
+	plan, ok := r.plans[planID]
+	if !ok || dayNumber < 1 || dayNumber > len(plan.DailyVerses) {
+		return false, nil
+	}
+	day := &plan.DailyVerses[dayNumber-1]
+	if onlyIfEmpty && day.Explanation != "" {
+		return false, nil
+	}
+	day.Explanation = explanation
+	return true, nil
+}
+
+func TestPlansCoveringDate(t *testing.T) {
+	date := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	plan := func(topic string, startOffset, days int, created int, active bool, priority int) *domain.ReadingPlan {
+		start := date.AddDate(0, 0, startOffset)
+		return &domain.ReadingPlan{
+			ID:        uuid.New(),
+			UserID:    "user-1",
+			Topic:     topic,
+			StartDate: start,
+			EndDate:   start.AddDate(0, 0, days-1),
+			CreatedAt: date.AddDate(0, 0, created),
+			IsActive:  active,
+			Priority:  priority,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		plans    []*domain.ReadingPlan
+		expected []string
+	}{
+		{
+			name: "Active plan first, then priority, then newest",
+			plans: []*domain.ReadingPlan{
+				plan("low", -3, 7, -9, false, 0),
+				plan("high older", -1, 7, -8, false, 5),
+				plan("active", -5, 30, -10, true, 0),
+				plan("high newer", 0, 1, -7, false, 5),
+			},
+			expected: []string{"active", "high newer", "high older", "low"},
+		},
+		{
+			name: "Without an active plan priority decides",
+			plans: []*domain.ReadingPlan{
+				plan("newest", -1, 7, -1, false, 0),
+				plan("first", -2, 7, -2, false, 3),
+			},
+			expected: []string{"first", "newest"},
+		},
+		{
+			name: "Plans starting or ending on the date cover it",
+			plans: []*domain.ReadingPlan{
+				plan("ends today", -6, 7, -7, false, 0),
+				plan("starts today", 0, 7, -6, false, 0),
+			},
+			expected: []string{"starts today", "ends today"},
+		},
+		{
+			name: "Plans outside the date are left out, even the active one",
+			plans: []*domain.ReadingPlan{
+				plan("finished", -10, 7, -10, true, 0),
+				plan("upcoming", 1, 7, -1, false, 9),
+				plan("current", -1, 7, -2, false, 0),
+			},
+			expected: []string{"current"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			other := plan("another user's", -1, 7, 0, true, 9)
+			other.UserID = "user-2"
+			s := &planService{planRepo: newFakePlanRepository(append(tc.plans, other)...)}
+
+			covering, err := s.plansCoveringDate(context.Background(), "user-1", date)
+			require.NoError(t, err)
+			topics := []string{}
+			for _, plan := range covering {
+				topics = append(topics, plan.Topic)
+			}
+			assert.Equal(t, tc.expected, topics)
+		})
+	}
+}
+
+func TestDefaultPlanFollowsUserTrack(t *testing.T) {
+	date := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	defaultPlan := func(topic string, trackID string, startOffset int) *domain.ReadingPlan {
+		start := date.AddDate(0, 0, startOffset)
+		return &domain.ReadingPlan{
+			ID:        uuid.New(),
+			UserID:    domain.DefaultPlanUserID,
+			Topic:     topic,
+			TrackID:   trackID,
+			StartDate: start,
+			EndDate:   start.AddDate(0, 0, 6),
+			CreatedAt: start,
+		}
+	}
+	plans := newFakePlanRepository(
+		defaultPlan("adults this week", "adults", -2),
+		defaultPlan("adults next week", "adults", 5),
+		defaultPlan("from before tracks", "", -3),
+		defaultPlan("kids last week", "kids", -9),
+		defaultPlan("kids two weeks ago", "kids", -16),
+	)
+	tracks := []domain.PlanTrack{{ID: "adults", Name: "Adults"}, {ID: "kids", Name: "Kids"}}
+
+	tests := []struct {
+		name          string
+		trackID       string // Selected by the user; empty for no selection
+		expectedTrack string
+		expectedTopic string
+	}{
+		{name: "No selection reads the primary track", expectedTrack: "adults", expectedTopic: "adults this week"},
+		{name: "Selected track", trackID: "kids", expectedTrack: "kids", expectedTopic: "kids last week"},
+		{name: "Track no longer configured falls back to the primary one", trackID: "teens", expectedTrack: "adults", expectedTopic: "adults this week"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			users := repository.NewInMemoryUserRepository()
+			_, err := users.Create(context.Background(), &domain.User{ID: "user-1", GoogleID: "g-user-1", TrackID: tc.trackID})
+			require.NoError(t, err)
+			s := &planService{planRepo: plans, userRepo: users, tracks: tracks}
+
+			assert.Equal(t, tc.expectedTrack, s.GetUserTrack(context.Background(), "user-1").ID)
+			// The kids track has no plan covering the date, so its most recent plan is used
+			plan, err := s.findDefaultPlanForDate(context.Background(), "user-1", date)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTopic, plan.Topic)
+		})
+	}
+
+	t.Run("Untracked plans belong to the primary track", func(t *testing.T) {
+		s := &planService{planRepo: plans, userRepo: repository.NewInMemoryUserRepository(), tracks: tracks}
+		plan, err := s.findDefaultPlanForDate(context.Background(), "user-1", date.AddDate(0, 0, -3))
+		require.NoError(t, err)
+		assert.Equal(t, "from before tracks", plan.Topic)
+	})
+
+	t.Run("Unknown track can't be selected", func(t *testing.T) {
+		s := &planService{planRepo: plans, userRepo: repository.NewInMemoryUserRepository(), tracks: tracks}
+		_, err := s.SelectTrack(context.Background(), "user-1", "teens")
+		assert.EqualError(t, err, "track not found")
+	})
+}
+
+func TestGetPlanDay(t *testing.T) {
+	own := &domain.ReadingPlan{ID: uuid.New(), UserID: "user-1", DailyVerses: []domain.DailyVerse{
+		{DayNumber: 1, Reference: "Psalms 23:1-6"},
+		{DayNumber: 2, Reference: "Psalms 24:1-10"},
+	}}
+	shared := &domain.ReadingPlan{ID: uuid.New(), UserID: domain.DefaultPlanUserID, DailyVerses: []domain.DailyVerse{{DayNumber: 1, Reference: "John 1:1-5"}}}
+	reader := domain.Actor{UserID: "user-1", Roles: []string{domain.RoleUser}}
+	otherReader := domain.Actor{UserID: "user-2", Roles: []string{domain.RoleUser}}
+	editor := domain.Actor{UserID: "editor-1", Roles: []string{domain.RoleEditor}}
+
+	tests := []struct {
+		name          string
+		planID        string
+		day           int
+		actor         domain.Actor
+		expectedRef   string
+		expectedErr   string
+		expectedErrIs error
+	}{
+		{name: "Reading ahead in one's own plan", planID: own.ID.String(), day: 2, actor: reader, expectedRef: "Psalms 24:1-10"},
+		{name: "Default plans are readable by everyone", planID: shared.ID.String(), day: 1, actor: otherReader, expectedRef: "John 1:1-5"},
+		{name: "Editors read any plan", planID: own.ID.String(), day: 1, actor: editor, expectedRef: "Psalms 23:1-6"},
+		{name: "Another user's plan is refused", planID: own.ID.String(), day: 1, actor: otherReader, expectedErr: "unauthorized: cannot access another user's plan"},
+		{name: "Day past the end", planID: own.ID.String(), day: 3, actor: reader, expectedErrIs: repository.ErrDayOutOfRange},
+		{name: "Day zero", planID: own.ID.String(), day: 0, actor: reader, expectedErrIs: repository.ErrDayOutOfRange},
+		{name: "Unknown plan", planID: uuid.NewString(), day: 1, actor: reader, expectedErr: "plan not found"},
+	}
+
+	s := &planService{planRepo: newFakePlanRepository(own, shared)}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			verse, err := s.GetPlanDay(context.Background(), tc.planID, tc.day, tc.actor)
+			switch {
+			case tc.expectedErrIs != nil:
+				assert.ErrorIs(t, err, tc.expectedErrIs)
+			case tc.expectedErr != "":
+				assert.EqualError(t, err, tc.expectedErr)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedRef, verse.Reference)
+				assert.Equal(t, tc.planID, verse.PlanID)
+				assert.Equal(t, tc.day, verse.DayNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/metering"
+	"bibleapp/backend/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Limits the generated study material is validated against
+const (
+	minReflectionQuestions = 2
+	maxReflectionQuestions = 3
+	minQuizQuestions       = 3
+	maxQuizQuestions       = 5
+	minQuizOptions         = 3
+	maxQuizOptions         = 4
+)
+
+// DayStudy is the reflection and quiz material of one plan day, without quiz answers
+type DayStudy struct {
+	PlanID              string                `json:"plan_id"`
+	DayNumber           int                   `json:"day"`
+	Reference           string                `json:"reference"`
+	Title               string                `json:"title"`
+	ReflectionQuestions []string              `json:"reflection_questions"`
+	Quiz                []domain.QuizQuestion `json:"quiz"`
+}
+
+// QuizAnswerResult shows how one quiz question was answered
+type QuizAnswerResult struct {
+	Question    string `json:"question"`
+	Selected    int    `json:"selected"`
+	AnswerIndex int    `json:"answer_index"`
+	Correct     bool   `json:"correct"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// QuizResult is the scored outcome of a quiz submission
+type QuizResult struct {
+	Attempt domain.QuizAttempt `json:"attempt"`
+	Results []QuizAnswerResult `json:"results"`
+}
+
+// Engagement summarizes a reader's quiz activity for their guardians
+type Engagement struct {
+	UserID         string                `json:"user_id"`
+	Name           string                `json:"name"`
+	Days           int                   `json:"days"` // Length of the reporting window
+	Attempts       int                   `json:"attempts"`
+	DaysCompleted  int                   `json:"days_completed"` // Distinct plan days with at least one attempt
+	AverageScore   float64               `json:"average_score"`
+	LastActivityAt *time.Time            `json:"last_activity_at,omitempty"`
+	Recent         []*domain.QuizAttempt `json:"recent"`
+}
+
+// maxRecentAttempts bounds the attempts listed in an engagement report
+const maxRecentAttempts = 20
+
+// StudyService provides reflection questions and comprehension quizzes for plan days
+type StudyService interface {
+	// GetDayStudy returns a day's questions and quiz, generating and caching them on first use
+	GetDayStudy(ctx context.Context, planID string, dayNumber int, actor domain.Actor) (DayStudy, error)
+	// SubmitQuiz scores answers to a day's quiz and records the attempt
+	SubmitQuiz(ctx context.Context, planID string, dayNumber int, answers []int, actor domain.Actor) (QuizResult, error)
+	// GetEngagement summarizes a user's quiz activity over the last days, for the user's guardians
+	GetEngagement(ctx context.Context, userID string, days int, actor domain.Actor) (Engagement, error)
+}
+
+type studyService struct {
+	planRepo     repository.PlanRepository
+	attemptRepo  repository.QuizAttemptRepository
+	userRepo     repository.UserRepository
+	llmClient    llm.LLMClient
+	verseService VerseService
+	modelName    string
+}
+
+// NewStudyService creates a new StudyService
+func NewStudyService(planRepo repository.PlanRepository, attemptRepo repository.QuizAttemptRepository, userRepo repository.UserRepository,
+	llmClient llm.LLMClient, verseService VerseService, modelName string) StudyService {
+	return &studyService{
+		planRepo:     planRepo,
+		attemptRepo:  attemptRepo,
+		userRepo:     userRepo,
+		llmClient:    llmClient,
+		verseService: verseService,
+		modelName:    modelName,
+	}
+}
+
+// GetDayStudy returns a day's questions and quiz, generating and caching them on first use
+func (s *studyService) GetDayStudy(ctx context.Context, planID string, dayNumber int, actor domain.Actor) (DayStudy, error) {
+	plan, verse, err := s.findReadableDay(ctx, planID, dayNumber, actor)
+	if err != nil {
+		return DayStudy{}, err
+	}
+
+	if len(verse.Quiz) == 0 {
+		questions, quiz, err := s.generateStudy(ctx, plan, verse)
+		if err != nil {
+			return DayStudy{}, err
+		}
+
+		stored, err := s.planRepo.SetDayStudy(ctx, planID, dayNumber, questions, quiz, true)
+		if err != nil {
+			return DayStudy{}, fmt.Errorf("failed to store study: %w", err)
+		}
+		if stored {
+			log.Printf("INFO: Cached study for day %d of plan %s", dayNumber, planID)
+			verse.ReflectionQuestions = questions
+			verse.Quiz = quiz
+		} else {
+			// Another request stored its study first; use that one so answers are scored against what readers saw
+			if _, verse, err = s.findReadableDay(ctx, planID, dayNumber, actor); err != nil {
+				return DayStudy{}, err
+			}
+		}
+	}
+
+	return DayStudy{
+		PlanID:              planID,
+		DayNumber:           dayNumber,
+		Reference:           verse.Reference,
+		Title:               verse.Title,
+		ReflectionQuestions: verse.ReflectionQuestions,
+		Quiz:                verse.Quiz,
+	}, nil
+}
+
+// SubmitQuiz scores answers to a day's quiz and records the attempt
+func (s *studyService) SubmitQuiz(ctx context.Context, planID string, dayNumber int, answers []int, actor domain.Actor) (QuizResult, error) {
+	_, verse, err := s.findReadableDay(ctx, planID, dayNumber, actor)
+	if err != nil {
+		return QuizResult{}, err
+	}
+	if len(verse.Quiz) == 0 {
+		return QuizResult{}, errors.New("quiz not found")
+	}
+	if len(answers) != len(verse.Quiz) {
+		return QuizResult{}, fmt.Errorf("invalid answers: expected %d answers, got %d", len(verse.Quiz), len(answers))
+	}
+
+	results := make([]QuizAnswerResult, len(verse.Quiz))
+	correct := 0
+	for i, question := range verse.Quiz {
+		if answers[i] < 0 || answers[i] >= len(question.Options) {
+			return QuizResult{}, fmt.Errorf("invalid answers: answer %d is out of range", i+1)
+		}
+		results[i] = QuizAnswerResult{
+			Question:    question.Question,
+			Selected:    answers[i],
+			AnswerIndex: question.AnswerIndex,
+			Correct:     answers[i] == question.AnswerIndex,
+			Explanation: question.Explanation,
+		}
+		if results[i].Correct {
+			correct++
+		}
+	}
+
+	attempt := domain.QuizAttempt{
+		UserID:    actor.UserID,
+		PlanID:    planID,
+		DayNumber: dayNumber,
+		Reference: verse.Reference,
+		Answers:   answers,
+		Correct:   correct,
+		Total:     len(verse.Quiz),
+		Score:     float64(correct) / float64(len(verse.Quiz)),
+	}
+	if err := s.attemptRepo.Save(ctx, &attempt); err != nil {
+		return QuizResult{}, fmt.Errorf("failed to record quiz attempt: %w", err)
+	}
+
+	log.Printf("INFO: User %s scored %d/%d on day %d of plan %s", actor.UserID, correct, attempt.Total, dayNumber, planID)
+	return QuizResult{Attempt: attempt, Results: results}, nil
+}
+
+// GetEngagement summarizes a user's quiz activity. Only the user's linked guardians
+// and user managers may see it.
+func (s *studyService) GetEngagement(ctx context.Context, userID string, days int, actor domain.Actor) (Engagement, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return Engagement{}, fmt.Errorf("error finding user: %w", err)
+	}
+	if user == nil {
+		return Engagement{}, errors.New("user not found")
+	}
+
+	linkedGuardian := actor.Can(domain.PermViewWards) && user.HasGuardian(actor.UserID)
+	if !linkedGuardian && !actor.Can(domain.PermManageUsers) {
+		return Engagement{}, errors.New("unauthorized: not a guardian of this user")
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	attempts, err := s.attemptRepo.ListByUser(ctx, userID, since)
+	if err != nil {
+		return Engagement{}, fmt.Errorf("failed to retrieve quiz attempts: %w", err)
+	}
+
+	engagement := Engagement{
+		UserID:   user.ID,
+		Name:     user.Name,
+		Days:     days,
+		Attempts: len(attempts),
+		Recent:   attempts,
+	}
+	if len(attempts) > maxRecentAttempts {
+		engagement.Recent = attempts[:maxRecentAttempts]
+	}
+
+	completed := make(map[string]bool)
+	totalScore := 0.0
+	for _, attempt := range attempts {
+		completed[fmt.Sprintf("%s/%d", attempt.PlanID, attempt.DayNumber)] = true
+		totalScore += attempt.Score
+	}
+	engagement.DaysCompleted = len(completed)
+	if len(attempts) > 0 {
+		engagement.AverageScore = totalScore / float64(len(attempts))
+		lastActivity := attempts[0].SubmittedAt // Attempts are newest first
+		engagement.LastActivityAt = &lastActivity
+	}
+
+	return engagement, nil
+}
+
+// findReadableDay loads a plan day the actor may read: their own plans, default plans,
+// or any plan for editors
+func (s *studyService) findReadableDay(ctx context.Context, planID string, dayNumber int, actor domain.Actor) (*domain.ReadingPlan, domain.DailyVerse, error) {
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil {
+		return nil, domain.DailyVerse{}, fmt.Errorf("error finding plan: %w", err)
+	}
+	if plan == nil {
+		return nil, domain.DailyVerse{}, errors.New("plan not found")
+	}
+
+	if !canReadPlan(plan, actor) {
+		return nil, domain.DailyVerse{}, errors.New("unauthorized: cannot access another user's plan")
+	}
+
+	verse, found := plan.GetVerseForDay(dayNumber)
+	if !found {
+		return nil, domain.DailyVerse{}, repository.ErrDayOutOfRange
+	}
+	return plan, verse, nil
+}
+
+// generatedStudy is the JSON the LLM is asked to return. Unlike domain.QuizQuestion
+// it carries the answers in JSON.
+type generatedStudy struct {
+	ReflectionQuestions []string `json:"reflection_questions"`
+	Quiz                []struct {
+		Question    string   `json:"question"`
+		Options     []string `json:"options"`
+		AnswerIndex int      `json:"answer_index"`
+		Explanation string   `json:"explanation"`
+	} `json:"quiz"`
+}
+
+// generateStudy asks the LLM for reflection questions and a quiz grounded in the day's passage,
+// retrying with feedback when the result fails validation
+func (s *studyService) generateStudy(ctx context.Context, plan *domain.ReadingPlan, verse domain.DailyVerse) ([]string, []domain.QuizQuestion, error) {
+	passage, err := s.verseService.GetVerseContent(ctx, verse.Reference)
+	if err != nil {
+		// Questions not grounded in the actual text could teach the wrong thing
+		return nil, nil, fmt.Errorf("failed to fetch passage for study: %w", err)
+	}
+
+	audience := plan.TargetAudience
+	if audience == "" {
+		audience = "reader"
+	}
+
+	systemPrompt := fmt.Sprintf(`You write study material for a Bible reading plan on "%s" for a %s.
+
+Based ONLY on the passage the user gives you, write:
+- %d to %d open reflection questions that help a %s apply the passage
+- a multiple-choice quiz of %d to %d questions checking comprehension of the passage, each with %d to %d distinct options and exactly one correct answer that is stated in the passage
+
+Output ONLY JSON: {"reflection_questions": ["..."], "quiz": [{"question": "...", "options": ["...", "...", "..."], "answer_index": 0, "explanation": "..."}]}
+"answer_index" is the 0-based index of the correct option. "explanation" says briefly why it is correct.`,
+		plan.Topic, audience,
+		minReflectionQuestions, maxReflectionQuestions, audience,
+		minQuizQuestions, maxQuizQuestions, minQuizOptions, maxQuizOptions)
+
+	originalUserPrompt := fmt.Sprintf("Day %d: %s\nPassage: %s\n\n%s", verse.DayNumber, verse.Title, verse.Reference, passage)
+	userPrompt := originalUserPrompt
+
+	const maxRetries = 3
+	var lastError error
+
+	for retry := 0; retry < maxRetries; retry++ {
+		request := llm.ChatCompletionRequest{
+			Model: s.modelName,
+			Messages: []llm.Message{
+				{Role: "system", Content: systemPrompt},
+				{Role: "user", Content: userPrompt},
+			},
+			MaxTokens:   1200,
+			Temperature: 0.4,
+			ResponseFormat: &llm.ResponseFormat{
+				Type: "json_object",
+			},
+		}
+
+		llmResponse, err := s.llmClient.CreateChatCompletion(metering.WithFeature(ctx, metering.FeatureStudy), request)
+		if err != nil {
+			// Don't retry on API errors
+			return nil, nil, fmt.Errorf("LLM completion failed during study generation: %w", err)
+		}
+		if len(llmResponse.Choices) == 0 || llmResponse.Choices[0].Message.Content == "" {
+			lastError = errors.New("LLM returned an empty response for study generation")
+			userPrompt = originalUserPrompt + "\n\nThe previous attempt returned an empty response. Please provide the JSON object."
+			continue
+		}
+
+		rawJson := strings.TrimSpace(llmResponse.Choices[0].Message.Content)
+		if strings.HasPrefix(rawJson, "```json") {
+			rawJson = strings.TrimPrefix(rawJson, "```json")
+			rawJson = strings.TrimSuffix(rawJson, "```")
+			rawJson = strings.TrimSpace(rawJson)
+		}
+
+		var study generatedStudy
+		if err := json.Unmarshal([]byte(rawJson), &study); err != nil {
+			lastError = fmt.Errorf("failed to parse study JSON: %w", err)
+			log.Printf("WARN: %v (attempt %d/%d)", lastError, retry+1, maxRetries)
+			userPrompt = originalUserPrompt + "\n\nThe previous response was not valid JSON. Please ensure you ONLY return the JSON object."
+			continue
+		}
+
+		questions, quiz, problems := validateStudy(study)
+		if len(problems) > 0 {
+			lastError = fmt.Errorf("generated study failed validation: %s", strings.Join(problems, "; "))
+			log.Printf("WARN: %v (attempt %d/%d)", lastError, retry+1, maxRetries)
+			userPrompt = originalUserPrompt + "\n\nThe previous response had these problems, please fix them:\n- " + strings.Join(problems, "\n- ")
+			continue
+		}
+
+		log.Printf("INFO: Generated %d reflection questions and %d quiz questions for day %d of plan %s",
+			len(questions), len(quiz), verse.DayNumber, plan.ID)
+		return questions, quiz, nil
+	}
+
+	return nil, nil, fmt.Errorf("failed to generate valid study after %d attempts: %w", maxRetries, lastError)
+}
+
+// validateStudy checks generated study material and converts it to domain types.
+// It returns the problems found, phrased as feedback for the LLM.
+func validateStudy(study generatedStudy) ([]string, []domain.QuizQuestion, []string) {
+	var problems []string
+
+	var questions []string
+	for _, question := range study.ReflectionQuestions {
+		if trimmed := strings.TrimSpace(question); trimmed != "" {
+			questions = append(questions, trimmed)
+		}
+	}
+	if len(questions) < minReflectionQuestions || len(questions) > maxReflectionQuestions {
+		problems = append(problems, fmt.Sprintf("provide %d to %d reflection questions, got %d", minReflectionQuestions, maxReflectionQuestions, len(questions)))
+	}
+
+	if len(study.Quiz) < minQuizQuestions || len(study.Quiz) > maxQuizQuestions {
+		problems = append(problems, fmt.Sprintf("provide %d to %d quiz questions, got %d", minQuizQuestions, maxQuizQuestions, len(study.Quiz)))
+	}
+
+	quiz := make([]domain.QuizQuestion, 0, len(study.Quiz))
+	for i, item := range study.Quiz {
+		question := domain.QuizQuestion{
+			Question:    strings.TrimSpace(item.Question),
+			AnswerIndex: item.AnswerIndex,
+			Explanation: strings.TrimSpace(item.Explanation),
+		}
+		if question.Question == "" {
+			problems = append(problems, fmt.Sprintf("quiz question %d has no question text", i+1))
+		}
+
+		seen := make(map[string]bool)
+		for _, option := range item.Options {
+			trimmed := strings.TrimSpace(option)
+			key := strings.ToLower(trimmed)
+			if trimmed == "" || seen[key] {
+				problems = append(problems, fmt.Sprintf("quiz question %d has empty or duplicate options", i+1))
+				break
+			}
+			seen[key] = true
+			question.Options = append(question.Options, trimmed)
+		}
+		if len(item.Options) < minQuizOptions || len(item.Options) > maxQuizOptions {
+			problems = append(problems, fmt.Sprintf("quiz question %d needs %d to %d options, got %d", i+1, minQuizOptions, maxQuizOptions, len(item.Options)))
+		}
+		if item.AnswerIndex < 0 || item.AnswerIndex >= len(item.Options) {
+			problems = append(problems, fmt.Sprintf("quiz question %d has answer_index %d outside its options", i+1, item.AnswerIndex))
+		}
+
+		quiz = append(quiz, question)
+	}
+
+	return questions, quiz, problems
+}
+
+// keepCachedStudy carries generated study material over to an edited plan for days whose
+// passage didn't change. Quiz answers never reach the client, so an edit can't set them.
+func keepCachedStudy(plan *domain.ReadingPlan, existing *domain.ReadingPlan) {
+	for i := range plan.DailyVerses {
+		day := &plan.DailyVerses[i]
+		day.ReflectionQuestions, day.Quiz, day.StudyGeneratedAt = nil, nil, time.Time{}
+
+		previous, found := existing.GetVerseForDay(day.DayNumber)
+		if found && previous.Reference == day.Reference {
+			day.ReflectionQuestions = previous.ReflectionQuestions
+			day.Quiz = previous.Quiz
+			day.StudyGeneratedAt = previous.StudyGeneratedAt
+		}
+	}
+}
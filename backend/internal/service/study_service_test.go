@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAttemptRepository records saved quiz attempts
+type fakeAttemptRepository struct {
+	repository.QuizAttemptRepository
+	saved []domain.QuizAttempt
+}
+
+func (r *fakeAttemptRepository) Save(ctx context.Context, attempt *domain.QuizAttempt) error {
+	r.saved = append(r.saved, *attempt)
+	return nil
+}
+
+func TestSubmitQuiz(t *testing.T) {
+	options := []string{"Moses", "David", "Solomon"}
+	plan := &domain.ReadingPlan{
+		ID:     uuid.New(),
+		UserID: "user-1",
+		DailyVerses: []domain.DailyVerse{
+			{DayNumber: 1, Reference: "Psalms 23:1-6", Quiz: []domain.QuizQuestion{
+				{Question: "Who wrote the psalm?", Options: options, AnswerIndex: 1},
+				{Question: "Who is the shepherd?", Options: []string{"The king", "The Lord", "The psalmist"}, AnswerIndex: 1},
+				{Question: "Where does he lead?", Options: []string{"Still waters", "The desert", "The city", "The sea"}, AnswerIndex: 0},
+			}},
+			{DayNumber: 2, Reference: "Psalms 24:1-10"},
+		},
+	}
+	reader := domain.Actor{UserID: "user-1", Roles: []string{domain.RoleUser}}
+
+	tests := []struct {
+		name            string
+		day             int
+		answers         []int
+		actor           domain.Actor
+		expectedCorrect int
+		expectedScore   float64
+		expectedErr     string
+	}{
+		{name: "All correct", day: 1, answers: []int{1, 1, 0}, actor: reader, expectedCorrect: 3, expectedScore: 1},
+		{name: "Partly correct", day: 1, answers: []int{1, 0, 3}, actor: reader, expectedCorrect: 1, expectedScore: 1.0 / 3},
+		{name: "None correct", day: 1, answers: []int{0, 2, 1}, actor: reader, expectedCorrect: 0, expectedScore: 0},
+		{name: "Too few answers", day: 1, answers: []int{1, 1}, actor: reader, expectedErr: "invalid answers: expected 3 answers, got 2"},
+		{name: "Choice past the options", day: 1, answers: []int{1, 3, 0}, actor: reader, expectedErr: "invalid answers: answer 2 is out of range"},
+		{name: "Negative choice", day: 1, answers: []int{-1, 1, 0}, actor: reader, expectedErr: "invalid answers: answer 1 is out of range"},
+		{name: "Day without a quiz", day: 2, answers: []int{0}, actor: reader, expectedErr: "quiz not found"},
+		{
+			name:        "Another user's plan",
+			day:         1,
+			answers:     []int{1, 1, 0},
+			actor:       domain.Actor{UserID: "user-2", Roles: []string{domain.RoleUser}},
+			expectedErr: "unauthorized: cannot access another user's plan",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := &fakeAttemptRepository{}
+			study := NewStudyService(newFakePlanRepository(plan), attempts, nil, nil, nil, "some/model")
+
+			result, err := study.SubmitQuiz(context.Background(), plan.ID.String(), tc.day, tc.answers, tc.actor)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				assert.Empty(t, attempts.saved, "a rejected submission isn't recorded")
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCorrect, result.Attempt.Correct)
+			assert.Equal(t, 3, result.Attempt.Total)
+			assert.InDelta(t, tc.expectedScore, result.Attempt.Score, 1e-9)
+			require.Len(t, result.Results, 3)
+			for i, answer := range result.Results {
+				assert.Equal(t, tc.answers[i] == answer.AnswerIndex, answer.Correct)
+			}
+			require.Len(t, attempts.saved, 1)
+			assert.Equal(t, result.Attempt, attempts.saved[0])
+		})
+	}
+}
+
+func TestValidateStudy(t *testing.T) {
+	type quizItem = struct {
+		Question    string   `json:"question"`
+		Options     []string `json:"options"`
+		AnswerIndex int      `json:"answer_index"`
+		Explanation string   `json:"explanation"`
+	}
+	question := func(answerIndex int, options ...string) quizItem {
+		return quizItem{Question: "Who is the shepherd?", Options: options, AnswerIndex: answerIndex}
+	}
+	valid := func() generatedStudy {
+		return generatedStudy{
+			ReflectionQuestions: []string{"What do you lack?", " Where are your still waters? "},
+			Quiz: []quizItem{
+				question(1, "The king", "The Lord", "The psalmist"),
+				question(0, "Still waters", "The desert", "The city", "The sea"),
+				question(2, "Fear", "Doubt", "Goodness and mercy"),
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		edit             func(study *generatedStudy)
+		expectedProblems []string
+	}{
+		{
+			name: "Valid study",
+			edit: func(study *generatedStudy) {},
+		},
+		{
+			name:             "Answer key outside the options",
+			edit:             func(study *generatedStudy) { study.Quiz[0].AnswerIndex = 3 },
+			expectedProblems: []string{"quiz question 1 has answer_index 3 outside its options"},
+		},
+		{
+			name:             "Negative answer key",
+			edit:             func(study *generatedStudy) { study.Quiz[2].AnswerIndex = -1 },
+			expectedProblems: []string{"quiz question 3 has answer_index -1 outside its options"},
+		},
+		{
+			name: "Duplicate options",
+			edit: func(study *generatedStudy) {
+				study.Quiz[1] = question(0, "Still waters", "The desert", "still waters ")
+			},
+			expectedProblems: []string{"quiz question 2 has empty or duplicate options"},
+		},
+		{
+			name:             "Too few options",
+			edit:             func(study *generatedStudy) { study.Quiz[1] = question(0, "Still waters", "The desert") },
+			expectedProblems: []string{"quiz question 2 needs 3 to 4 options, got 2"},
+		},
+		{
+			name: "Too few questions",
+			edit: func(study *generatedStudy) {
+				study.ReflectionQuestions = []string{"What do you lack?", " "}
+				study.Quiz = study.Quiz[:2]
+			},
+			expectedProblems: []string{"provide 2 to 3 reflection questions, got 1", "provide 3 to 5 quiz questions, got 2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			study := valid()
+			tc.edit(&study)
+
+			questions, quiz, problems := validateStudy(study)
+			assert.Equal(t, tc.expectedProblems, problems)
+			if tc.expectedProblems == nil {
+				assert.Equal(t, []string{"What do you lack?", "Where are your still waters?"}, questions)
+				require.Len(t, quiz, 3)
+				assert.Equal(t, 2, quiz[2].AnswerIndex)
+				assert.Equal(t, []string{"Fear", "Doubt", "Goodness and mercy"}, quiz[2].Options)
+			}
+		})
+	}
+}
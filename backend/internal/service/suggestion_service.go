@@ -0,0 +1,315 @@
+package service
+
+import (
+	"bibleapp/backend/internal/calendar"
+	"bibleapp/backend/internal/llm"
+	"bibleapp/backend/internal/metering"
+	"bibleapp/backend/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Where a topic suggestion came from
+const (
+	SuggestionSourceLLM      = "llm"
+	SuggestionSourceFallback = "fallback"
+)
+
+// MaxTopicSuggestions caps the suggestions returned in one request
+const MaxTopicSuggestions = 10
+
+// suggestionLookahead is how far ahead the upcoming season is looked up
+const suggestionLookahead = 21 * 24 * time.Hour
+
+// suggestionHistoryWindow bounds the quiz history used to judge plan completion
+const suggestionHistoryWindow = 365 * 24 * time.Hour
+
+// fallbackTopics are suggested, in order, when the LLM is unavailable
+var fallbackTopics = []struct{ Topic, Rationale string }{
+	{"The Parables of Jesus", "Short stories that open up the heart of Jesus' teaching."},
+	{"Prayer in the Psalms", "Learn to pray honestly through the Bible's own prayer book."},
+	{"Wisdom for Everyday Life from Proverbs", "Practical wisdom in short daily readings."},
+	{"The Life of David", "Courage, failure and forgiveness in one life."},
+	{"The Sermon on the Mount", "Jesus' central teaching on living as his disciple."},
+	{"Heroes of Faith in Hebrews 11", "A tour of faithful lives across the Old Testament."},
+	{"The Fruit of the Spirit", "One quality of a Spirit-led life at a time."},
+	{"God's Promises in Hard Times", "Encouragement for difficult seasons."},
+	{"The Early Church in Acts", "How the first believers lived out the gospel."},
+	{"Creation and God's Care in Genesis", "The beginning of the Bible's story."},
+}
+
+// TopicSuggestion is a plan topic recommended to a user
+type TopicSuggestion struct {
+	Topic     string `json:"topic"`
+	Rationale string `json:"rationale"`
+}
+
+// TopicSuggestions are ranked suggestions, best first, and the context they were drawn from
+type TopicSuggestions struct {
+	Suggestions    []TopicSuggestion `json:"suggestions"`
+	Source         string            `json:"source"` // "llm" or "fallback"
+	Theme          string            `json:"theme,omitempty"`
+	Season         string            `json:"season"`
+	UpcomingSeason string            `json:"upcoming_season,omitempty"` // Set when a new season starts soon
+}
+
+// SuggestionService recommends plan topics
+type SuggestionService interface {
+	// SuggestTopics returns up to limit ranked topic suggestions for a user
+	SuggestTopics(ctx context.Context, userID string, limit int) (TopicSuggestions, error)
+}
+
+type suggestionService struct {
+	planService   PlanService
+	attemptRepo   repository.QuizAttemptRepository
+	themeCalendar *calendar.ThemeCalendar
+	llmClient     llm.LLMClient
+	modelName     string
+}
+
+// NewSuggestionService creates a new SuggestionService
+func NewSuggestionService(planService PlanService, attemptRepo repository.QuizAttemptRepository, themeCalendar *calendar.ThemeCalendar,
+	llmClient llm.LLMClient, modelName string) SuggestionService {
+	return &suggestionService{
+		planService:   planService,
+		attemptRepo:   attemptRepo,
+		themeCalendar: themeCalendar,
+		llmClient:     llmClient,
+		modelName:     modelName,
+	}
+}
+
+// pastPlan summarizes one of the user's plans for the suggestion prompt
+type pastPlan struct {
+	Topic      string
+	Finished   bool    // The plan's last day has passed
+	Completion float64 // Fraction of the plan's days with a quiz attempt
+}
+
+// suggestionContext is what suggestions are drawn from
+type suggestionContext struct {
+	theme          calendar.Theme
+	season         calendar.Season
+	upcomingSeason calendar.Season // Empty when the season doesn't change soon
+	history        []pastPlan
+}
+
+// SuggestTopics returns up to limit ranked topic suggestions for a user.
+// When the LLM fails, a deterministic list built from the calendar and fallback topics is returned.
+func (s *suggestionService) SuggestTopics(ctx context.Context, userID string, limit int) (TopicSuggestions, error) {
+	if limit < 1 || limit > MaxTopicSuggestions {
+		return TopicSuggestions{}, fmt.Errorf("limit must be between 1 and %d", MaxTopicSuggestions)
+	}
+
+	sc, err := s.buildContext(ctx, userID, time.Now())
+	if err != nil {
+		return TopicSuggestions{}, err
+	}
+
+	result := TopicSuggestions{
+		Theme:  sc.theme.Name,
+		Season: sc.season.Name(),
+	}
+	if sc.upcomingSeason != "" {
+		result.UpcomingSeason = sc.upcomingSeason.Name()
+	}
+
+	suggestions, err := s.generateSuggestions(ctx, sc, limit)
+	if err != nil {
+		log.Printf("WARN: Using fallback topic suggestions for user %s: %v", userID, err)
+		result.Suggestions = fallbackSuggestions(sc, limit)
+		result.Source = SuggestionSourceFallback
+		return result, nil
+	}
+
+	result.Suggestions = suggestions
+	result.Source = SuggestionSourceLLM
+	return result, nil
+}
+
+// buildContext gathers the calendar and the user's plan history
+func (s *suggestionService) buildContext(ctx context.Context, userID string, now time.Time) (suggestionContext, error) {
+	track := s.planService.GetUserTrack(ctx, userID)
+	sc := suggestionContext{
+		theme:  s.themeCalendar.ThemeFor(now, track.ID, track.Theme),
+		season: calendar.SeasonFor(now),
+	}
+	if upcoming := calendar.SeasonFor(now.Add(suggestionLookahead)); upcoming != sc.season {
+		sc.upcomingSeason = upcoming
+	}
+
+	plans, err := s.planService.ListPlans(ctx, userID)
+	if err != nil {
+		return sc, fmt.Errorf("failed to load plan history: %w", err)
+	}
+
+	// Completion is judged from the days a quiz was taken; without attempts it is unknown rather than fatal
+	daysDone := make(map[string]map[int]bool)
+	attempts, err := s.attemptRepo.ListByUser(ctx, userID, now.Add(-suggestionHistoryWindow))
+	if err != nil {
+		log.Printf("WARN: Suggesting topics for user %s without quiz history: %v", userID, err)
+	}
+	for _, attempt := range attempts {
+		if daysDone[attempt.PlanID] == nil {
+			daysDone[attempt.PlanID] = make(map[int]bool)
+		}
+		daysDone[attempt.PlanID][attempt.DayNumber] = true
+	}
+
+	for _, plan := range plans {
+		past := pastPlan{Topic: plan.Topic, Finished: now.After(plan.EndDate)}
+		if plan.DurationDays > 0 {
+			past.Completion = float64(len(daysDone[plan.ID.String()])) / float64(plan.DurationDays)
+		}
+		sc.history = append(sc.history, past)
+	}
+	return sc, nil
+}
+
+// generateSuggestions asks the LLM for ranked topics
+func (s *suggestionService) generateSuggestions(ctx context.Context, sc suggestionContext, limit int) ([]TopicSuggestion, error) {
+	systemPrompt := fmt.Sprintf(`You recommend topics for Bible reading plans.
+Suggest %d topics the user would benefit from next, ranked best first. Each topic is a short title (at most 8 words).
+Take into account the current theme and church season, an upcoming season worth preparing for, and the user's past plans:
+don't repeat past topics, build on plans they completed, and prefer shorter or gentler topics if they rarely finish plans.
+
+Output ONLY JSON: {"suggestions": [{"topic": "...", "rationale": "one sentence on why this topic suits the user now"}]}`, limit)
+
+	request := llm.ChatCompletionRequest{
+		Model: s.modelName,
+		Messages: []llm.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: describeSuggestionContext(sc)},
+		},
+		MaxTokens:   800,
+		Temperature: 0.8,
+		ResponseFormat: &llm.ResponseFormat{
+			Type: "json_object",
+		},
+	}
+
+	llmResponse, err := s.llmClient.CreateChatCompletion(metering.WithFeature(ctx, metering.FeatureTopic), request)
+	if err != nil {
+		return nil, fmt.Errorf("LLM completion failed during topic suggestion: %w", err)
+	}
+	if len(llmResponse.Choices) == 0 || llmResponse.Choices[0].Message.Content == "" {
+		return nil, errors.New("LLM returned an empty response for topic suggestion")
+	}
+
+	rawJson := strings.TrimSpace(llmResponse.Choices[0].Message.Content)
+	if strings.HasPrefix(rawJson, "```json") {
+		rawJson = strings.TrimPrefix(rawJson, "```json")
+		rawJson = strings.TrimSuffix(rawJson, "```")
+		rawJson = strings.TrimSpace(rawJson)
+	}
+
+	var parsed struct {
+		Suggestions []TopicSuggestion `json:"suggestions"`
+	}
+	if err := json.Unmarshal([]byte(rawJson), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse topic suggestion JSON: %w", err)
+	}
+
+	// Drop blanks, repeats and past topics the model suggested anyway
+	seen := pastTopics(sc)
+	var suggestions []TopicSuggestion
+	for _, suggestion := range parsed.Suggestions {
+		suggestion.Topic = strings.TrimSpace(suggestion.Topic)
+		suggestion.Rationale = strings.TrimSpace(suggestion.Rationale)
+		key := strings.ToLower(suggestion.Topic)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		suggestions = append(suggestions, suggestion)
+		if len(suggestions) == limit {
+			break
+		}
+	}
+	if len(suggestions) == 0 {
+		return nil, errors.New("LLM returned no usable topic suggestions")
+	}
+	return suggestions, nil
+}
+
+// describeSuggestionContext renders the suggestion context as the user prompt
+func describeSuggestionContext(sc suggestionContext) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Current church season: %s\n", sc.season.Name())
+	if sc.upcomingSeason != "" {
+		fmt.Fprintf(&b, "Upcoming season (starts within three weeks): %s\n", sc.upcomingSeason.Name())
+	}
+	if sc.theme.Name != "" {
+		fmt.Fprintf(&b, "Current theme: %s\n", sc.theme.Name)
+	}
+
+	if len(sc.history) == 0 {
+		b.WriteString("Past plans: none, this would be the user's first plan\n")
+		return b.String()
+	}
+	b.WriteString("Past plans, newest first:\n")
+	for _, past := range sc.history {
+		status := "in progress"
+		if past.Finished {
+			status = "ended"
+		}
+		fmt.Fprintf(&b, "- %s (%s, %.0f%% of days completed)\n", past.Topic, status, past.Completion*100)
+	}
+	return b.String()
+}
+
+// fallbackSuggestions builds a deterministic list: the upcoming season and current theme first,
+// then the fixed fallback topics, skipping the user's past topics
+func fallbackSuggestions(sc suggestionContext, limit int) []TopicSuggestion {
+	var candidates []TopicSuggestion
+	if theme := sc.upcomingSeason.DefaultTheme(); theme != "" {
+		candidates = append(candidates, TopicSuggestion{
+			Topic:     theme,
+			Rationale: fmt.Sprintf("%s begins soon; this plan prepares for it.", sc.upcomingSeason.Name()),
+		})
+	}
+	if sc.theme.Name != "" {
+		candidates = append(candidates, TopicSuggestion{
+			Topic:     sc.theme.Name,
+			Rationale: "This is the current theme of the reading calendar.",
+		})
+	}
+	if theme := sc.season.DefaultTheme(); theme != "" {
+		candidates = append(candidates, TopicSuggestion{
+			Topic:     theme,
+			Rationale: fmt.Sprintf("A reading plan for the season of %s.", sc.season.Name()),
+		})
+	}
+	for _, fallback := range fallbackTopics {
+		candidates = append(candidates, TopicSuggestion{Topic: fallback.Topic, Rationale: fallback.Rationale})
+	}
+
+	seen := pastTopics(sc)
+	var suggestions []TopicSuggestion
+	for _, candidate := range candidates {
+		key := strings.ToLower(candidate.Topic)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		suggestions = append(suggestions, candidate)
+		if len(suggestions) == limit {
+			break
+		}
+	}
+	return suggestions
+}
+
+// pastTopics returns the user's past plan topics, lowercased
+func pastTopics(sc suggestionContext) map[string]bool {
+	topics := make(map[string]bool, len(sc.history))
+	for _, past := range sc.history {
+		topics[strings.ToLower(strings.TrimSpace(past.Topic))] = true
+	}
+	return topics
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"bibleapp/backend/internal/calendar"
+	"bibleapp/backend/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func suggestionTopics(suggestions []TopicSuggestion) []string {
+	topics := []string{}
+	for _, suggestion := range suggestions {
+		topics = append(topics, suggestion.Topic)
+	}
+	return topics
+}
+
+func TestFallbackSuggestions(t *testing.T) {
+	tests := []struct {
+		name     string
+		sc       suggestionContext
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "Ordinary Time without a theme starts with the fixed topics",
+			sc:       suggestionContext{season: calendar.SeasonOrdinaryTime},
+			limit:    2,
+			expected: []string{"The Parables of Jesus", "Prayer in the Psalms"},
+		},
+		{
+			name: "Upcoming season, then theme, then current season",
+			sc: suggestionContext{
+				theme:          calendar.Theme{Name: "Forgiveness"},
+				season:         calendar.SeasonHolyWeek,
+				upcomingSeason: calendar.SeasonEaster,
+			},
+			limit: 4,
+			expected: []string{
+				"The Resurrection and New Life in Christ",
+				"Forgiveness",
+				"The Passion and the Cross of Christ",
+				"The Parables of Jesus",
+			},
+		},
+		{
+			name: "Past topics and repeats are skipped",
+			sc: suggestionContext{
+				theme:   calendar.Theme{Name: "Prayer in the Psalms"},
+				season:  calendar.SeasonOrdinaryTime,
+				history: []pastPlan{{Topic: " the parables of jesus "}},
+			},
+			limit:    3,
+			expected: []string{"Prayer in the Psalms", "Wisdom for Everyday Life from Proverbs", "The Life of David"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			suggestions := fallbackSuggestions(tc.sc, tc.limit)
+			assert.Equal(t, tc.expected, suggestionTopics(suggestions))
+			assert.Equal(t, suggestions, fallbackSuggestions(tc.sc, tc.limit), "the fallback is deterministic")
+		})
+	}
+}
+
+func TestGenerateSuggestionsCleansModelOutput(t *testing.T) {
+	respond := func(content string) *scriptedLLM {
+		return &scriptedLLM{responses: []llm.ChatCompletionResponse{{Choices: []llm.ChatChoice{{Message: llm.Message{Role: "assistant", Content: content}}}}}}
+	}
+	sc := suggestionContext{season: calendar.SeasonOrdinaryTime, history: []pastPlan{{Topic: "The Life of David", Finished: true, Completion: 0.9}}}
+
+	tests := []struct {
+		name        string
+		content     string
+		limit       int
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "Blank, repeated and past topics are dropped",
+			content:  `{"suggestions":[{"topic":" Psalms of Trust ","rationale":"r"},{"topic":""},{"topic":"the life of david"},{"topic":"psalms of trust"},{"topic":"Ruth and Naomi"}]}`,
+			limit:    5,
+			expected: []string{"Psalms of Trust", "Ruth and Naomi"},
+		},
+		{
+			name:     "Fenced JSON is cut down to the limit",
+			content:  "```json\n" + `{"suggestions":[{"topic":"Psalms of Trust"},{"topic":"Ruth and Naomi"},{"topic":"Jonah"}]}` + "\n```",
+			limit:    2,
+			expected: []string{"Psalms of Trust", "Ruth and Naomi"},
+		},
+		{
+			name:        "Nothing usable is an error",
+			content:     `{"suggestions":[{"topic":"The Life of David"}]}`,
+			limit:       3,
+			expectedErr: "LLM returned no usable topic suggestions",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &suggestionService{llmClient: respond(tc.content), modelName: "some/model"}
+			suggestions, err := s.generateSuggestions(context.Background(), sc, tc.limit)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, suggestionTopics(suggestions))
+		})
+	}
+}
+
+func TestSuggestTopicsLimit(t *testing.T) {
+	s := &suggestionService{}
+	for _, limit := range []int{0, -1, MaxTopicSuggestions + 1} {
+		_, err := s.SuggestTopics(context.Background(), "user-1", limit)
+		assert.EqualError(t, err, "limit must be between 1 and 10", "limit %d", limit)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/metering"
+	"bibleapp/backend/internal/repository"
+)
+
+// maxUsageDays caps how far back a usage report reaches
+const maxUsageDays = 365
+
+// UsageReport sums LLM usage over a range of UTC days
+type UsageReport struct {
+	From             time.Time              `json:"from"`
+	To               time.Time              `json:"to"`
+	Requests         int                    `json:"requests"`
+	PromptTokens     int                    `json:"prompt_tokens"`
+	CompletionTokens int                    `json:"completion_tokens"`
+	Cost             float64                `json:"cost"`   // US dollars
+	Totals           []domain.LLMUsageTotal `json:"totals"` // Per day and feature
+}
+
+// BudgetStatus is what this month's LLM requests have cost against the monthly budget
+type BudgetStatus struct {
+	MonthlyLimit float64 `json:"monthly_limit"` // 0 means no budget
+	Spent        float64 `json:"spent"`
+	Fallback     bool    `json:"fallback"` // Whether requests take the budget route once it is spent, rather than being refused
+	Exceeded     bool    `json:"exceeded"`
+}
+
+// UsageService reports the tokens and cost of LLM requests
+type UsageService interface {
+	// UserUsage reports the requests made for a user over the last days, today included
+	UserUsage(ctx context.Context, userID string, days int) (UsageReport, error)
+	// TotalUsage reports every request made over the last days, today included
+	TotalUsage(ctx context.Context, days int) (UsageReport, error)
+	// Budget reports this month's spend against the budget
+	Budget(ctx context.Context) (BudgetStatus, error)
+}
+
+type usageService struct {
+	usageRepo repository.LLMUsageRepository
+	budget    metering.Budget
+}
+
+// NewUsageService creates a new UsageService
+func NewUsageService(usageRepo repository.LLMUsageRepository, budget metering.Budget) UsageService {
+	return &usageService{usageRepo: usageRepo, budget: budget}
+}
+
+// UserUsage reports the requests made for a user over the last days
+func (s *usageService) UserUsage(ctx context.Context, userID string, days int) (UsageReport, error) {
+	return s.report(ctx, userID, days)
+}
+
+// TotalUsage reports every request made over the last days
+func (s *usageService) TotalUsage(ctx context.Context, days int) (UsageReport, error) {
+	return s.report(ctx, "", days)
+}
+
+func (s *usageService) report(ctx context.Context, userID string, days int) (UsageReport, error) {
+	if days < 1 || days > maxUsageDays {
+		return UsageReport{}, fmt.Errorf("invalid days: must be between 1 and %d", maxUsageDays)
+	}
+
+	now := time.Now().UTC()
+	report := UsageReport{
+		From: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-days),
+		To:   now,
+	}
+	totals, err := s.usageRepo.Totals(ctx, repository.LLMUsageFilter{UserID: userID, From: report.From, To: report.To})
+	if err != nil {
+		return UsageReport{}, fmt.Errorf("failed to retrieve LLM usage: %w", err)
+	}
+
+	report.Totals = totals
+	for _, total := range totals {
+		report.Requests += total.Requests
+		report.PromptTokens += total.PromptTokens
+		report.CompletionTokens += total.CompletionTokens
+		report.Cost += total.Cost
+	}
+	return report, nil
+}
+
+// Budget reports this calendar month's (UTC) spend against the budget
+func (s *usageService) Budget(ctx context.Context) (BudgetStatus, error) {
+	now := time.Now().UTC()
+	spent, err := s.usageRepo.CostSince(ctx, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return BudgetStatus{}, fmt.Errorf("failed to retrieve LLM spend: %w", err)
+	}
+	return BudgetStatus{
+		MonthlyLimit: s.budget.MonthlyLimit,
+		Spent:        spent,
+		Fallback:     s.budget.Fallback != nil,
+		Exceeded:     s.budget.MonthlyLimit > 0 && spent >= s.budget.MonthlyLimit,
+	}, nil
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// UserService manages user accounts and their roles
+type UserService interface {
+	// GetUser returns a user by internal ID
+	GetUser(ctx context.Context, userID string) (domain.User, error)
+	// ListUsers returns all users
+	ListUsers(ctx context.Context) ([]domain.User, error)
+	// SetRoles replaces a user's roles. Changes take effect at the user's next request.
+	SetRoles(ctx context.Context, userID string, roles []string) (domain.User, error)
+	// SetGuardians replaces the guardians who may see a user's activity
+	SetGuardians(ctx context.Context, userID string, guardianIDs []string) (domain.User, error)
+	// ListWards returns the users linked to a guardian
+	ListWards(ctx context.Context, guardianID string) ([]domain.User, error)
+	// SetAudience stores the audience profile a user's answers and plans are written for; nil resets it.
+	// A profile set by an admin or guardian (managed) is locked: the user can't change or override it.
+	SetAudience(ctx context.Context, userID string, audience *domain.AudienceProfile, managed bool) (domain.User, error)
+	// SetWardAudience sets and locks a ward's audience profile on behalf of one of their linked guardians
+	SetWardAudience(ctx context.Context, userID string, audience *domain.AudienceProfile, actor domain.Actor) (domain.User, error)
+	// AudienceFor returns the profile to write for: the override's fields, then the user's profile, then the defaults.
+	// Overrides are ignored for users whose profile is locked.
+	AudienceFor(ctx context.Context, userID string, override *domain.AudienceProfile) (domain.AudienceProfile, error)
+	// SetTimezone stores the IANA time zone a user's daily chat limit resets in
+	SetTimezone(ctx context.Context, userID string, timezone string) (domain.User, error)
+	// SetChatLimit overrides a user's daily chat limit; nil goes back to the role limits and 0 means unlimited
+	SetChatLimit(ctx context.Context, userID string, limit *int) (domain.User, error)
+}
+
+type userService struct {
+	userRepo repository.UserRepository
+}
+
+// NewUserService creates a new UserService
+func NewUserService(userRepo repository.UserRepository) UserService {
+	return &userService{userRepo: userRepo}
+}
+
+// GetUser returns a user by internal ID
+func (s *userService) GetUser(ctx context.Context, userID string) (domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("error finding user: %w", err)
+	}
+	if user == nil {
+		return domain.User{}, errors.New("user not found")
+	}
+	return *user, nil
+}
+
+// ListUsers returns all users
+func (s *userService) ListUsers(ctx context.Context) ([]domain.User, error) {
+	users, err := s.userRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve users: %w", err)
+	}
+
+	result := make([]domain.User, len(users))
+	for i, user := range users {
+		result[i] = *user
+	}
+	return result, nil
+}
+
+// SetRoles validates and replaces a user's roles. The last admin can't be demoted,
+// otherwise nobody would be left to grant the role back.
+func (s *userService) SetRoles(ctx context.Context, userID string, roles []string) (domain.User, error) {
+	if len(roles) == 0 {
+		return domain.User{}, errors.New("invalid roles: at least one role is required")
+	}
+
+	seen := make(map[string]bool)
+	var cleaned []string
+	for _, role := range roles {
+		if !domain.IsValidRole(role) {
+			return domain.User{}, fmt.Errorf("invalid roles: unknown role '%s'", role)
+		}
+		if !seen[role] {
+			seen[role] = true
+			cleaned = append(cleaned, role)
+		}
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if user.HasRole(domain.RoleAdmin) && !seen[domain.RoleAdmin] {
+		users, err := s.userRepo.List(ctx)
+		if err != nil {
+			return domain.User{}, fmt.Errorf("failed to count admins: %w", err)
+		}
+		admins := 0
+		for _, u := range users {
+			if u.HasRole(domain.RoleAdmin) {
+				admins++
+			}
+		}
+		if admins <= 1 {
+			return domain.User{}, errors.New("invalid roles: cannot remove the last admin")
+		}
+	}
+
+	if err := s.userRepo.UpdateRoles(ctx, userID, cleaned); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.User{}, errors.New("user not found")
+		}
+		return domain.User{}, fmt.Errorf("failed to update roles: %w", err)
+	}
+
+	log.Printf("INFO: Roles of user %s (%s) set to %v", user.ID, user.Email, cleaned)
+	user.Roles = cleaned
+	return user, nil
+}
+
+// SetGuardians replaces the guardians linked to a user. Every guardian must exist and hold the guardian role.
+func (s *userService) SetGuardians(ctx context.Context, userID string, guardianIDs []string) (domain.User, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	seen := make(map[string]bool)
+	cleaned := []string{}
+	for _, guardianID := range guardianIDs {
+		if seen[guardianID] {
+			continue
+		}
+		seen[guardianID] = true
+
+		if guardianID == userID {
+			return domain.User{}, errors.New("invalid guardians: a user can't be their own guardian")
+		}
+		guardian, err := s.userRepo.FindByID(ctx, guardianID)
+		if err != nil || guardian == nil {
+			return domain.User{}, fmt.Errorf("invalid guardians: user '%s' not found", guardianID)
+		}
+		if !guardian.HasRole(domain.RoleGuardian) && !guardian.HasRole(domain.RoleAdmin) {
+			return domain.User{}, fmt.Errorf("invalid guardians: user '%s' is not a guardian", guardianID)
+		}
+		cleaned = append(cleaned, guardianID)
+	}
+
+	if err := s.userRepo.UpdateGuardians(ctx, userID, cleaned); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.User{}, errors.New("user not found")
+		}
+		return domain.User{}, fmt.Errorf("failed to update guardians: %w", err)
+	}
+
+	log.Printf("INFO: Guardians of user %s (%s) set to %v", user.ID, user.Email, cleaned)
+	user.GuardianIDs = cleaned
+	return user, nil
+}
+
+// ListWards returns the users linked to a guardian
+func (s *userService) ListWards(ctx context.Context, guardianID string) ([]domain.User, error) {
+	wards, err := s.userRepo.FindByGuardian(ctx, guardianID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve wards: %w", err)
+	}
+
+	result := make([]domain.User, len(wards))
+	for i, ward := range wards {
+		result[i] = *ward
+	}
+	return result, nil
+}
+
+// SetAudience validates and stores a user's audience profile. Users can't change a locked profile;
+// a managed reset to the default unlocks it.
+func (s *userService) SetAudience(ctx context.Context, userID string, audience *domain.AudienceProfile, managed bool) (domain.User, error) {
+	if audience != nil {
+		if err := audience.Validate(); err != nil {
+			return domain.User{}, err
+		}
+		audience.Tradition = strings.TrimSpace(audience.Tradition)
+		audience.Language = strings.TrimSpace(audience.Language)
+		if *audience == (domain.AudienceProfile{}) {
+			audience = nil
+		}
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if user.AudienceLocked && !managed {
+		return domain.User{}, errors.New("audience locked: it was set by an admin or guardian")
+	}
+	locked := managed && audience != nil
+	if err := s.userRepo.UpdateAudience(ctx, userID, audience, locked); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.User{}, errors.New("user not found")
+		}
+		return domain.User{}, fmt.Errorf("failed to update audience: %w", err)
+	}
+
+	if audience == nil {
+		log.Printf("INFO: Audience of user %s reset to the default", user.ID)
+	} else {
+		log.Printf("INFO: Audience of user %s set to %s", user.ID, audience.Describe())
+	}
+	user.Audience = audience
+	user.AudienceLocked = locked
+	return user, nil
+}
+
+// SetWardAudience stores a managed audience profile for a user whose linked guardian the actor
+// is. User managers may set any user's profile.
+func (s *userService) SetWardAudience(ctx context.Context, userID string, audience *domain.AudienceProfile, actor domain.Actor) (domain.User, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	linkedGuardian := actor.Can(domain.PermViewWards) && user.HasGuardian(actor.UserID)
+	if !linkedGuardian && !actor.Can(domain.PermManageUsers) {
+		return domain.User{}, errors.New("unauthorized: not a guardian of this user")
+	}
+	return s.SetAudience(ctx, userID, audience, true)
+}
+
+// AudienceFor merges a per-request override over the user's stored profile and the defaults.
+// A user who can't be looked up gets the defaults rather than an error.
+func (s *userService) AudienceFor(ctx context.Context, userID string, override *domain.AudienceProfile) (domain.AudienceProfile, error) {
+	profile := domain.AudienceProfile{}
+	if override != nil {
+		if err := override.Validate(); err != nil {
+			return domain.AudienceProfile{}, err
+		}
+		profile = *override
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		log.Printf("WARN: Could not look up audience of user %s: %v", userID, err)
+	} else if user != nil && user.Audience != nil {
+		if user.AudienceLocked {
+			profile = domain.AudienceProfile{}
+		}
+		profile = profile.Merge(*user.Audience)
+	}
+	return profile.Merge(domain.DefaultAudienceProfile), nil
+}
+
+// SetTimezone validates and stores a user's time zone. Storing the zone they already have is a no-op.
+// The new zone takes over at the next midnight of the zone days are counted in now; changing again
+// before then keeps counting in that zone, so switching zones can't reset the daily chat limit.
+func (s *userService) SetTimezone(ctx context.Context, userID string, timezone string) (domain.User, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return domain.User{}, fmt.Errorf("invalid timezone: unknown time zone '%s'", timezone)
+		}
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if user.Timezone == timezone {
+		return user, nil
+	}
+
+	now := time.Now()
+	current := user.TimezoneAt(now)
+	previous, from := current, nextMidnight(now, loadTimezone(current))
+	if now.Before(user.TimezoneFrom) {
+		from = user.TimezoneFrom
+	}
+	if timezone == current {
+		// Back to the zone still in use before the change took over
+		previous, from = "", time.Time{}
+	}
+	if err := s.userRepo.UpdateTimezone(ctx, userID, timezone, previous, from); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.User{}, errors.New("user not found")
+		}
+		return domain.User{}, fmt.Errorf("failed to update timezone: %w", err)
+	}
+
+	log.Printf("INFO: Timezone of user %s set to '%s' from %s", user.ID, timezone, from.Format(time.RFC3339))
+	user.Timezone, user.PreviousTimezone, user.TimezoneFrom = timezone, previous, from
+	return user, nil
+}
+
+// SetChatLimit validates and stores a user's daily chat limit
+func (s *userService) SetChatLimit(ctx context.Context, userID string, limit *int) (domain.User, error) {
+	if limit != nil && *limit < 0 {
+		return domain.User{}, errors.New("invalid limit: must be 0 (unlimited) or more")
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err := s.userRepo.UpdateChatLimit(ctx, userID, limit); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.User{}, errors.New("user not found")
+		}
+		return domain.User{}, fmt.Errorf("failed to update chat limit: %w", err)
+	}
+
+	if limit == nil {
+		log.Printf("INFO: Chat limit of user %s reset to the role limits", user.ID)
+	} else {
+		log.Printf("INFO: Chat limit of user %s set to %d a day", user.ID, *limit)
+	}
+	user.ChatLimit = limit
+	return user, nil
+}
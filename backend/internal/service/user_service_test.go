@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bibleapp/backend/internal/domain"
+	"bibleapp/backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserService(t *testing.T, users ...*domain.User) UserService {
+	t.Helper()
+	repo := repository.NewInMemoryUserRepository()
+	for _, user := range users {
+		_, err := repo.Create(context.Background(), user)
+		require.NoError(t, err)
+	}
+	return NewUserService(repo)
+}
+
+func TestSetWardAudience(t *testing.T) {
+	teen := domain.AudienceProfile{AgeBand: domain.AgeBandTeen}
+	tests := []struct {
+		name        string
+		actor       domain.Actor
+		expectedErr string
+	}{
+		{
+			name:  "Linked guardian locks the profile",
+			actor: domain.Actor{UserID: "guardian-1", Roles: []string{domain.RoleGuardian}},
+		},
+		{
+			name:  "User manager may set any profile",
+			actor: domain.Actor{UserID: "admin-1", Roles: []string{domain.RoleAdmin}},
+		},
+		{
+			name:        "Guardian of other users is refused",
+			actor:       domain.Actor{UserID: "guardian-2", Roles: []string{domain.RoleGuardian}},
+			expectedErr: "unauthorized: not a guardian of this user",
+		},
+		{
+			name:        "Linked user without the guardian role is refused",
+			actor:       domain.Actor{UserID: "guardian-1", Roles: []string{domain.RoleUser}},
+			expectedErr: "unauthorized: not a guardian of this user",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			users := newTestUserService(t, &domain.User{ID: "ward-1", GoogleID: "g-ward-1", GuardianIDs: []string{"guardian-1"}})
+			profile := teen
+
+			user, err := users.SetWardAudience(context.Background(), "ward-1", &profile, tc.actor)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, user.AudienceLocked)
+			assert.Equal(t, &teen, user.Audience)
+		})
+	}
+}
+
+func TestLockedAudienceOverridesUserChoices(t *testing.T) {
+	ctx := context.Background()
+	users := newTestUserService(t, &domain.User{ID: "ward-1", GoogleID: "g-ward-1", GuardianIDs: []string{"guardian-1"}})
+	guardian := domain.Actor{UserID: "guardian-1", Roles: []string{domain.RoleGuardian}}
+	_, err := users.SetWardAudience(ctx, "ward-1", &domain.AudienceProfile{AgeBand: domain.AgeBandChild, ReadingLevel: domain.ReadingLevelSimple}, guardian)
+	require.NoError(t, err)
+
+	// The ward can't change the profile
+	_, err = users.SetAudience(ctx, "ward-1", &domain.AudienceProfile{AgeBand: domain.AgeBandAdult}, false)
+	assert.EqualError(t, err, "audience locked: it was set by an admin or guardian")
+
+	// Nor override it for a request; unset fields still come from the defaults
+	effective, err := users.AudienceFor(ctx, "ward-1", &domain.AudienceProfile{AgeBand: domain.AgeBandAdult, Tone: domain.ToneScholarly})
+	require.NoError(t, err)
+	assert.Equal(t, domain.AudienceProfile{
+		AgeBand:      domain.AgeBandChild,
+		ReadingLevel: domain.ReadingLevelSimple,
+		Tone:         domain.DefaultAudienceProfile.Tone,
+		Language:     domain.DefaultAudienceProfile.Language,
+	}, effective)
+
+	// A managed reset unlocks it again
+	user, err := users.SetWardAudience(ctx, "ward-1", &domain.AudienceProfile{}, guardian)
+	require.NoError(t, err)
+	assert.False(t, user.AudienceLocked)
+	assert.Nil(t, user.Audience)
+}
+
+func TestSetTimezone(t *testing.T) {
+	nextUTCMidnight := nextMidnight(time.Now(), time.UTC)
+	tests := []struct {
+		name             string
+		user             domain.User
+		timezone         string
+		expectedPrevious string
+		expectedFrom     time.Time
+		expectedErr      string
+	}{
+		{
+			name:         "First zone takes over at the next UTC midnight",
+			user:         domain.User{},
+			timezone:     "Asia/Tokyo",
+			expectedFrom: nextUTCMidnight,
+		},
+		{
+			name:             "Changing again before the takeover keeps counting in the zone in use",
+			user:             domain.User{Timezone: "Asia/Tokyo", TimezoneFrom: nextUTCMidnight},
+			timezone:         "America/New_York",
+			expectedPrevious: "",
+			expectedFrom:     nextUTCMidnight,
+		},
+		{
+			name:     "Going back to the zone in use cancels the change",
+			user:     domain.User{Timezone: "Asia/Tokyo", TimezoneFrom: nextUTCMidnight},
+			timezone: "",
+		},
+		{
+			name:             "Settled zone hands over at its own midnight",
+			user:             domain.User{Timezone: "Europe/Berlin"},
+			timezone:         "UTC",
+			expectedPrevious: "Europe/Berlin",
+			expectedFrom:     nextMidnight(time.Now(), loadTimezone("Europe/Berlin")),
+		},
+		{
+			name:        "Unknown zone is refused",
+			user:        domain.User{},
+			timezone:    "Mars/Olympus_Mons",
+			expectedErr: "invalid timezone: unknown time zone 'Mars/Olympus_Mons'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user := tc.user
+			user.ID, user.GoogleID = "user-1", "g-user-1"
+			users := newTestUserService(t, &user)
+
+			updated, err := users.SetTimezone(context.Background(), "user-1", tc.timezone)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			stored, err := users.GetUser(context.Background(), "user-1")
+			require.NoError(t, err)
+			for _, got := range []domain.User{updated, stored} {
+				assert.Equal(t, tc.timezone, got.Timezone)
+				assert.Equal(t, tc.expectedPrevious, got.PreviousTimezone)
+				assert.True(t, tc.expectedFrom.Equal(got.TimezoneFrom), "takes over at %s", got.TimezoneFrom)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bibleapp/backend/internal/repository"
 	"bibleapp/backend/internal/util"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -19,8 +20,21 @@ type VerseService interface {
 
 	// EnrichDailyVerse takes a daily verse with just a reference and fetches the full content
 	EnrichDailyVerse(ctx context.Context, verse domain.DailyVerse) (domain.DailyVerse, error)
+
+	// ResolveReference checks every part of a reference against the verse repository
+	// and returns the parts that have no verse text
+	ResolveReference(ctx context.Context, reference string) ([]string, error)
+
+	// CountWords returns the number of words in the passage for a reference
+	CountWords(ctx context.Context, reference string) (int, error)
+
+	// SearchVerses finds verses containing the words of a query, best matches first
+	SearchVerses(ctx context.Context, query string, limit int) ([]domain.Citation, error)
 }
 
+// MaxVerseSearchResults caps the verses one search returns
+const MaxVerseSearchResults = 10
+
 type verseService struct {
 	repo repository.VerseRepository
 }
@@ -130,3 +144,75 @@ func (s *verseService) EnrichDailyVerse(ctx context.Context, verse domain.DailyV
 
 	return verse, nil
 }
+
+// ResolveReference checks that every part of a (possibly comma-separated or multi-chapter)
+// reference resolves to verse text. Both ends of a range must exist, so a range running past
+// the end of its chapter isn't resolved by the verses it does cover. It returns the parts that
+// could not be found.
+// An error is only returned when the lookup itself fails, not when parts are missing.
+func (s *verseService) ResolveReference(ctx context.Context, reference string) ([]string, error) {
+	references := util.SplitReferences(reference)
+	if len(references) == 0 {
+		return []string{reference}, nil
+	}
+
+	// Batch lookup first, then retry anything missing individually,
+	// mirroring the fallbacks GetVerseContent relies on
+	verseMap, err := s.repo.GetVersesByReferences(ctx, references)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reference %s: %w", reference, err)
+	}
+
+	var unresolved []string
+	for _, ref := range references {
+		found := verseMap[ref] != ""
+		if !found {
+			text, err := s.repo.GetVerseByReference(ctx, ref)
+			found = err == nil && text != ""
+		}
+		if !found || !s.rangeEndsExist(ctx, ref) {
+			unresolved = append(unresolved, ref)
+		}
+	}
+
+	return unresolved, nil
+}
+
+// rangeEndsExist reports whether the first and last verse of a range exist. Verses within a
+// chapter are numbered without gaps, so the whole range exists when both ends do.
+func (s *verseService) rangeEndsExist(ctx context.Context, reference string) bool {
+	first, last, ok := util.RangeEndpoints(reference)
+	if !ok {
+		return true
+	}
+	for _, end := range []string{first, last} {
+		if end == "" {
+			continue
+		}
+		if text, err := s.repo.GetVerseByReference(ctx, end); err != nil || text == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// CountWords returns the number of words in the passage for a reference
+func (s *verseService) CountWords(ctx context.Context, reference string) (int, error) {
+	text, err := s.GetVerseContent(ctx, reference)
+	if err != nil {
+		return 0, err
+	}
+	return util.CountWords(text), nil
+}
+
+// SearchVerses finds verses containing the words of a query. The limit is clamped to 1..MaxVerseSearchResults.
+func (s *verseService) SearchVerses(ctx context.Context, query string, limit int) ([]domain.Citation, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("invalid query: query cannot be empty")
+	}
+	if limit <= 0 || limit > MaxVerseSearchResults {
+		limit = MaxVerseSearchResults
+	}
+	return s.repo.SearchVerses(ctx, query, limit)
+}
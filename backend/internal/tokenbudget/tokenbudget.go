@@ -0,0 +1,122 @@
+// Package tokenbudget estimates prompt sizes and trims chat history to fit a model's context window.
+package tokenbudget
+
+import (
+	"bibleapp/backend/internal/llm"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultContextSize is assumed for models missing from the context size tables
+const DefaultContextSize = 8192
+
+// messageOverhead approximates the tokens each message adds for its role and separators
+const messageOverhead = 4
+
+// knownContextSizes are the context windows of common models, matched by model name prefix.
+// The longest matching prefix wins, so specific entries can refine a family.
+var knownContextSizes = map[string]int{
+	"openai/gpt-3.5-turbo":          16385,
+	"openai/gpt-4":                  8192,
+	"openai/gpt-4-turbo":            128000,
+	"openai/gpt-4o":                 128000,
+	"openai/gpt-4.1":                1047576,
+	"anthropic/claude":              200000,
+	"google/gemini":                 1000000,
+	"meta-llama/llama-3":            8192,
+	"meta-llama/llama-3.1":          131072,
+	"mistralai/mistral":             32768,
+	"deepseek/deepseek":             64000,
+	"nousresearch/hermes-3-llama-3": 131072,
+}
+
+// ContextSize returns the context window of a model. Overrides are checked first and,
+// like the built-in table, are matched by the longest model name prefix.
+func ContextSize(model string, overrides map[string]int) int {
+	if size := longestPrefixMatch(model, overrides); size > 0 {
+		return size
+	}
+	if size := longestPrefixMatch(model, knownContextSizes); size > 0 {
+		return size
+	}
+	return DefaultContextSize
+}
+
+func longestPrefixMatch(model string, sizes map[string]int) int {
+	best, bestLength := 0, -1
+	for prefix, size := range sizes {
+		if strings.HasPrefix(model, prefix) && len(prefix) > bestLength && size > 0 {
+			best, bestLength = size, len(prefix)
+		}
+	}
+	return best
+}
+
+// EstimateTokens approximates the tokens in a text without a model-specific tokenizer.
+// English averages about four characters per token; words are counted too so that
+// text with many short words isn't underestimated.
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	byChars := (utf8.RuneCountInString(text) + 3) / 4
+	byWords := len(strings.Fields(text)) * 4 / 3
+	if byWords > byChars {
+		return byWords
+	}
+	return byChars
+}
+
+// EstimateMessages approximates the tokens a list of messages takes in a prompt
+func EstimateMessages(messages []llm.Message) int {
+	total := 0
+	for _, message := range messages {
+		total += messageOverhead + EstimateTokens(message.Content)
+	}
+	return total
+}
+
+// Window keeps the most recent messages of a history that fit in a token budget.
+// It returns the index of the first kept message. The last message (normally the
+// new question) is always kept, even when it alone exceeds the budget.
+func Window(history []llm.Message, budget int) int {
+	if len(history) == 0 {
+		return 0
+	}
+
+	first := len(history) - 1
+	used := EstimateMessages(history[first:])
+	for first > 0 {
+		cost := EstimateMessages(history[first-1 : first])
+		if used+cost > budget {
+			break
+		}
+		used += cost
+		first--
+	}
+
+	// Don't start the window with an answer whose question was cut off
+	if first > 0 && first < len(history)-1 && history[first].Role == "assistant" {
+		first++
+	}
+	return first
+}
+
+// Truncate shortens a text at a word boundary so that it fits in a token budget
+func Truncate(text string, budget int) string {
+	if EstimateTokens(text) <= budget {
+		return text
+	}
+	words := strings.Fields(text)
+	// The estimate grows with every word, so the longest prefix that fits can be found by bisection
+	low, high := 0, len(words)
+	for low < high {
+		mid := (low + high + 1) / 2
+		if EstimateTokens(strings.Join(words[:mid], " ")) <= budget {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return strings.Join(words[:low], " ")
+}
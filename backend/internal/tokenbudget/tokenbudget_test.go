@@ -0,0 +1,66 @@
+package tokenbudget
+
+import (
+	"bibleapp/backend/internal/llm"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextSize(t *testing.T) {
+	assert.Equal(t, 16385, ContextSize("openai/gpt-3.5-turbo", nil))
+	assert.Equal(t, 128000, ContextSize("openai/gpt-4o-mini", nil), "longest prefix wins over openai/gpt-4")
+	assert.Equal(t, 8192, ContextSize("openai/gpt-4", nil))
+	assert.Equal(t, DefaultContextSize, ContextSize("someone/unknown-model", nil))
+	assert.Equal(t, 4096, ContextSize("openai/gpt-4o", map[string]int{"openai/": 4096}), "overrides are checked first")
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 8, EstimateTokens("In the beginning was the Word"), "29 characters, 6 words")
+	assert.Equal(t, 133, EstimateTokens(strings.Repeat("a ", 100)), "short words count by word")
+	assert.Equal(t, 25, EstimateTokens(strings.Repeat("x", 100)), "long words count by character")
+	assert.Equal(t, 2*messageOverhead+EstimateTokens("hello")+EstimateTokens("hi there"),
+		EstimateMessages([]llm.Message{{Role: "user", Content: "hello"}, {Role: "assistant", Content: "hi there"}}))
+}
+
+func TestWindowKeepsRecentMessagesWithinBudget(t *testing.T) {
+	long := strings.Repeat("word ", 40) // About 54 tokens with overhead
+	history := []llm.Message{
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "question"},
+	}
+
+	assert.Equal(t, 0, Window(history, 10000), "everything fits")
+	first := Window(history, 150)
+	assert.Equal(t, 2, first, "two older messages are dropped")
+	assert.LessOrEqual(t, EstimateMessages(history[first:]), 150)
+	assert.Equal(t, 4, Window(history, 1), "the last message is always kept")
+	assert.Equal(t, 0, Window(nil, 100))
+}
+
+func TestWindowDoesNotStartWithAnOrphanedAnswer(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	history := []llm.Message{
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "short"},
+		{Role: "assistant", Content: "short"},
+		{Role: "user", Content: "question"},
+	}
+
+	// Room for the last three messages plus the long answer, but not its question
+	budget := EstimateMessages(history[1:])
+	assert.Equal(t, 2, Window(history, budget))
+}
+
+func TestTruncate(t *testing.T) {
+	text := "In the beginning was the Word"
+	assert.Equal(t, text, Truncate(text, 8))
+	assert.Equal(t, "In the beginning", Truncate(text, 4))
+	assert.Equal(t, "", Truncate(text, 0))
+}
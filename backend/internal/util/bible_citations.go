@@ -0,0 +1,244 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bookAliases maps lower-case book names and common abbreviations to the book names the verse
+// repository uses. Numbered books are listed without their number, see numberedBooks.
+var bookAliases = map[string]string{
+	"genesis": "Genesis", "gen": "Genesis",
+	"exodus": "Exodus", "exod": "Exodus", "ex": "Exodus",
+	"leviticus": "Leviticus", "lev": "Leviticus",
+	"numbers": "Numbers", "num": "Numbers",
+	"deuteronomy": "Deuteronomy", "deut": "Deuteronomy",
+	"joshua": "Joshua", "josh": "Joshua",
+	"judges": "Judges", "judg": "Judges",
+	"ruth":   "Ruth",
+	"samuel": "Samuel", "sam": "Samuel",
+	"kings": "Kings", "kgs": "Kings",
+	"chronicles": "Chronicles", "chron": "Chronicles", "chr": "Chronicles",
+	"ezra":     "Ezra",
+	"nehemiah": "Nehemiah", "neh": "Nehemiah",
+	"esther": "Esther", "esth": "Esther",
+	"job":    "Job",
+	"psalms": "Psalm", "psalm": "Psalm", "psa": "Psalm", "ps": "Psalm",
+	"proverbs": "Proverbs", "prov": "Proverbs",
+	"ecclesiastes": "Ecclesiastes", "eccl": "Ecclesiastes",
+	"song of solomon": "Song of Solomon", "song of songs": "Song of Solomon",
+	"isaiah": "Isaiah", "isa": "Isaiah",
+	"jeremiah": "Jeremiah", "jer": "Jeremiah",
+	"lamentations": "Lamentations", "lam": "Lamentations",
+	"ezekiel": "Ezekiel", "ezek": "Ezekiel",
+	"daniel": "Daniel", "dan": "Daniel",
+	"hosea": "Hosea", "hos": "Hosea",
+	"joel":    "Joel",
+	"amos":    "Amos",
+	"obadiah": "Obadiah", "obad": "Obadiah",
+	"jonah": "Jonah",
+	"micah": "Micah", "mic": "Micah",
+	"nahum": "Nahum", "nah": "Nahum",
+	"habakkuk": "Habakkuk", "hab": "Habakkuk",
+	"zephaniah": "Zephaniah", "zeph": "Zephaniah",
+	"haggai": "Haggai", "hag": "Haggai",
+	"zechariah": "Zechariah", "zech": "Zechariah",
+	"malachi": "Malachi", "mal": "Malachi",
+	"matthew": "Matthew", "matt": "Matthew", "mt": "Matthew",
+	"mark": "Mark", "mk": "Mark",
+	"luke": "Luke", "lk": "Luke",
+	"john": "John", "jn": "John",
+	"acts":   "Acts",
+	"romans": "Romans", "rom": "Romans",
+	"corinthians": "Corinthians", "cor": "Corinthians",
+	"galatians": "Galatians", "gal": "Galatians",
+	"ephesians": "Ephesians", "eph": "Ephesians",
+	"philippians": "Philippians", "phil": "Philippians",
+	"colossians": "Colossians", "col": "Colossians",
+	"thessalonians": "Thessalonians", "thess": "Thessalonians",
+	"timothy": "Timothy", "tim": "Timothy",
+	"titus":    "Titus",
+	"philemon": "Philemon", "philem": "Philemon",
+	"hebrews": "Hebrews", "heb": "Hebrews",
+	"james": "James", "jas": "James",
+	"peter": "Peter", "pet": "Peter",
+	"jude":       "Jude",
+	"revelation": "Revelation", "rev": "Revelation",
+}
+
+// numberedBooks gives the highest number each numbered book takes ("3 John")
+var numberedBooks = map[string]int{
+	"Samuel": 2, "Kings": 2, "Chronicles": 2, "Corinthians": 2,
+	"Thessalonians": 2, "Timothy": 2, "Peter": 2, "John": 3,
+}
+
+// referenceMentionRegex finds references such as "John 3:16", "1 Cor 13:4-7" or "Psalm 23" in free text
+var referenceMentionRegex = buildReferenceMentionRegex()
+
+func buildReferenceMentionRegex() *regexp.Regexp {
+	aliases := make([]string, 0, len(bookAliases))
+	for alias := range bookAliases {
+		aliases = append(aliases, regexp.QuoteMeta(alias))
+	}
+	// Longest first, so "psalms" is preferred to "ps" and "philemon" to "phil"
+	sort.Slice(aliases, func(i, j int) bool {
+		if len(aliases[i]) != len(aliases[j]) {
+			return len(aliases[i]) > len(aliases[j])
+		}
+		return aliases[i] < aliases[j]
+	})
+	return regexp.MustCompile(`(?i)\b(?:([1-3])\s?)?(` + strings.Join(aliases, "|") + `)\.?\s+(\d{1,3})(?::(\d{1,3})(?:\s*[-–]\s*(\d{1,3}))?)?\b`)
+}
+
+// ExtractReferences returns the Bible references mentioned in text, in the order they first appear,
+// using the book names the verse repository knows ("1 Cor 13:4-7" becomes "1 Corinthians 13:4-7").
+// A bare chapter ("Psalm 23") only counts when the book name is capitalized, so ordinary words
+// followed by a number ("my job 2 days ago") aren't taken for references.
+func ExtractReferences(text string) []string {
+	var references []string
+	seen := make(map[string]bool)
+	for _, match := range referenceMentionRegex.FindAllStringSubmatchIndex(text, -1) {
+		group := func(i int) string {
+			if match[2*i] < 0 {
+				return ""
+			}
+			return text[match[2*i]:match[2*i+1]]
+		}
+		number, alias, chapter, verse, endVerse := group(1), group(2), group(3), group(4), group(5)
+
+		book := bookAliases[strings.ToLower(alias)]
+		if maxNumber, numbered := numberedBooks[book]; numbered {
+			if number == "" {
+				if book != "John" {
+					// "Corinthians 13" on its own doesn't say which letter
+					continue
+				}
+			} else {
+				n, _ := strconv.Atoi(number)
+				if n > maxNumber {
+					continue
+				}
+				book = number + " " + book
+			}
+		}
+		if verse == "" && !startsUpper(alias) {
+			continue
+		}
+
+		reference := fmt.Sprintf("%s %s", book, chapter)
+		if verse != "" {
+			reference += ":" + verse
+			if endVerse != "" && endVerse != verse {
+				reference += "-" + endVerse
+			}
+		}
+		if !seen[reference] {
+			seen[reference] = true
+			references = append(references, reference)
+		}
+	}
+	return references
+}
+
+func startsUpper(s string) bool {
+	return s != "" && s[0] >= 'A' && s[0] <= 'Z'
+}
+
+// singleChapterRegex matches "Book Chapter:Verse" and "Book Chapter:StartVerse-EndVerse"
+var singleChapterRegex = regexp.MustCompile(`^([1-3]?\s*[A-Za-z]+(?:\s+[A-Za-z]+)*)\s+(\d+):(\d+)(?:-(\d+))?$`)
+
+// verseSpan is a run of verses within one chapter
+type verseSpan struct {
+	book    string
+	chapter int
+	start   int
+	end     int
+}
+
+func parseVerseSpan(reference string) (verseSpan, bool) {
+	parts := singleChapterRegex.FindStringSubmatch(strings.TrimSpace(reference))
+	if parts == nil {
+		return verseSpan{}, false
+	}
+	span := verseSpan{book: strings.ToLower(strings.Join(strings.Fields(parts[1]), " "))}
+	span.chapter, _ = strconv.Atoi(parts[2])
+	span.start, _ = strconv.Atoi(parts[3])
+	span.end = span.start
+	if parts[4] != "" {
+		span.end, _ = strconv.Atoi(parts[4])
+	}
+	return span, true
+}
+
+// chapterEndVerse ends ranges that run to the end of a chapter, being the most verses any chapter has (Psalm 119)
+const chapterEndVerse = 176
+
+// RangeEndpoints returns the first and last verse of a single-chapter range as references, so
+// "Jude 1:20-25" gives "Jude 1:20" and "Jude 1:25". A range running to the end of its chapter
+// has no last verse to check and gives "" for it. ok is false for single verses and references
+// it can't parse.
+func RangeEndpoints(reference string) (first string, last string, ok bool) {
+	parts := singleChapterRegex.FindStringSubmatch(strings.TrimSpace(reference))
+	if parts == nil || parts[4] == "" {
+		return "", "", false
+	}
+	book := strings.Join(strings.Fields(parts[1]), " ")
+	first = fmt.Sprintf("%s %s:%s", book, parts[2], parts[3])
+	if end, _ := strconv.Atoi(parts[4]); end != chapterEndVerse {
+		last = fmt.Sprintf("%s %s:%s", book, parts[2], parts[4])
+	}
+	return first, last, true
+}
+
+// ExpandReference widens a single-chapter reference by a number of verses on each side, so
+// "John 3:16" with 3 and 3 becomes "John 3:13-19". Verses past the end of the chapter are
+// simply not found by the repository. It returns "" for references it can't widen, such as
+// ones spanning several chapters.
+func ExpandReference(reference string, before, after int) string {
+	parts := singleChapterRegex.FindStringSubmatch(strings.TrimSpace(NormalizeBibleReference(reference)))
+	if parts == nil {
+		return ""
+	}
+	start, _ := strconv.Atoi(parts[3])
+	end := start
+	if parts[4] != "" {
+		end, _ = strconv.Atoi(parts[4])
+	}
+	start -= before
+	if start < 1 {
+		start = 1
+	}
+	return fmt.Sprintf("%s %s:%d-%d", strings.TrimSpace(parts[1]), parts[2], start, end+after)
+}
+
+// ReferenceContains reports whether every verse of inner lies within outer.
+// Either may be a whole chapter, and outer may list several passages.
+func ReferenceContains(outer, inner string) bool {
+	innerParts := SplitReferences(inner)
+	outerParts := SplitReferences(outer)
+	if len(innerParts) == 0 {
+		return false
+	}
+	for _, innerPart := range innerParts {
+		innerSpan, ok := parseVerseSpan(innerPart)
+		if !ok {
+			return false
+		}
+		contained := false
+		for _, outerPart := range outerParts {
+			outerSpan, ok := parseVerseSpan(outerPart)
+			if ok && outerSpan.book == innerSpan.book && outerSpan.chapter == innerSpan.chapter &&
+				outerSpan.start <= innerSpan.start && innerSpan.end <= outerSpan.end {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Verse and range",
+			input:    "How does John 3:16 relate to Romans 5:6-8?",
+			expected: []string{"John 3:16", "Romans 5:6-8"},
+		},
+		{
+			name:     "Abbreviations and numbered books",
+			input:    "Compare 1 Cor 13:4-7 with 1 Jn 4:8 and Ps. 23:1",
+			expected: []string{"1 Corinthians 13:4-7", "1 John 4:8", "Psalm 23:1"},
+		},
+		{
+			name:     "Whole chapter needs a capitalized book",
+			input:    "I read Psalm 23 after my job 2 days ago",
+			expected: []string{"Psalm 23"},
+		},
+		{
+			name:     "Numbered book without its number",
+			input:    "What does Corinthians 13:4 mean?",
+			expected: nil,
+		},
+		{
+			name:     "Duplicates are dropped",
+			input:    "john 3:16 and John 3:16 again",
+			expected: []string{"John 3:16"},
+		},
+		{
+			name:     "No references",
+			input:    "Why did Jesus weep?",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ExtractReferences(tc.input))
+		})
+	}
+}
+
+func TestExpandReference(t *testing.T) {
+	assert.Equal(t, "John 3:13-19", ExpandReference("John 3:16", 3, 3))
+	assert.Equal(t, "Genesis 1:1-7", ExpandReference("Genesis 1:2-4", 3, 3))
+	assert.Equal(t, "", ExpandReference("Matthew 5:1-7:29", 3, 3))
+}
+
+func TestReferenceContains(t *testing.T) {
+	assert.True(t, ReferenceContains("John 3:1-21", "John 3:16"))
+	assert.True(t, ReferenceContains("John 3", "John 3:16-18"))
+	assert.True(t, ReferenceContains("Psalm 1:1-6, John 3:14-18", "john 3:16"))
+	assert.False(t, ReferenceContains("John 3:1-15", "John 3:14-16"))
+	assert.False(t, ReferenceContains("John 3:1-21", "1 John 3:16"))
+	assert.False(t, ReferenceContains("John 3:1-21", "not a reference"))
+}
+
+func TestRangeEndpoints(t *testing.T) {
+	tests := []struct {
+		reference string
+		first     string
+		last      string
+		ok        bool
+	}{
+		{"Jude 1:20-30", "Jude 1:20", "Jude 1:30", true},
+		{"1  John 4:7-8", "1 John 4:7", "1 John 4:8", true},
+		{"John 3:1-176", "John 3:1", "", true},
+		{"John 3:16", "", "", false},
+		{"John 3", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			first, last, ok := RangeEndpoints(tt.reference)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.first, first)
+			assert.Equal(t, tt.last, last)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// ReadingWordsPerMinute is the assumed pace for unhurried devotional reading.
+// Silent reading averages closer to 240 wpm, but scripture tends to be read more slowly.
+const ReadingWordsPerMinute = 200
+
+// verseMarkerRegex matches the "[16]" verse number markers added when verse ranges are joined
+var verseMarkerRegex = regexp.MustCompile(`^\[\d+\]$`)
+
+// CountWords counts the words in a passage, ignoring verse number markers
+func CountWords(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		if verseMarkerRegex.MatchString(field) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// EstimateReadingMinutes converts a word count into minutes of reading time,
+// rounded to one decimal place
+func EstimateReadingMinutes(words int) float64 {
+	if words <= 0 {
+		return 0
+	}
+	minutes := float64(words) / ReadingWordsPerMinute
+	return math.Round(minutes*10) / 10
+}
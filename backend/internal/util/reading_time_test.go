@@ -0,0 +1,49 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{
+			name:     "Plain text",
+			input:    "For God so loved the world",
+			expected: 6,
+		},
+		{
+			name:     "Verse markers are ignored",
+			input:    "[16] For God so loved the world [17] For God sent not his Son",
+			expected: 12,
+		},
+		{
+			name:     "Bracketed words are counted",
+			input:    "[selah] Be still",
+			expected: 3,
+		},
+		{
+			name:     "Empty text",
+			input:    "   ",
+			expected: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CountWords(tc.input))
+		})
+	}
+}
+
+func TestEstimateReadingMinutes(t *testing.T) {
+	assert.Equal(t, 0.0, EstimateReadingMinutes(0))
+	assert.Equal(t, 1.0, EstimateReadingMinutes(ReadingWordsPerMinute))
+	assert.Equal(t, 2.5, EstimateReadingMinutes(500))
+	assert.Equal(t, 0.1, EstimateReadingMinutes(25))
+}
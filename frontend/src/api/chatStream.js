@@ -0,0 +1,53 @@
+import apiClient from './axiosConfig';
+
+// Streams a chat answer from POST /api/chat/stream.
+// onToken is called with each piece of the answer as it arrives, and onReset when the pieces so far
+// turn out not to be the answer; the resolved value is the final response
+// ({ conversation_id, answer, usage_today, daily_limit }).
+export async function streamChat(body, onToken, onReset) {
+  const response = await fetch(`${apiClient.defaults.baseURL}/api/chat/stream`, {
+    method: 'POST',
+    credentials: 'include', // Send the HttpOnly auth_token cookie
+    headers: { 'Content-Type': 'application/json', Accept: 'text/event-stream' },
+    body: JSON.stringify(body),
+  });
+
+  // Failures before the first token come back as regular JSON errors
+  if (!response.ok || !response.body) {
+    const data = await response.json().catch(() => ({}));
+    throw new Error(data.error || `Request failed with status ${response.status}`);
+  }
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) break;
+    buffer += decoder.decode(value, { stream: true });
+
+    // Events are separated by a blank line
+    let boundary;
+    while ((boundary = buffer.indexOf('\n\n')) !== -1) {
+      const rawEvent = buffer.slice(0, boundary);
+      buffer = buffer.slice(boundary + 2);
+
+      let event = 'message';
+      let data = '';
+      for (const line of rawEvent.split('\n')) {
+        if (line.startsWith('event:')) event = line.slice(6).trim();
+        else if (line.startsWith('data:')) data += line.slice(5).trim();
+      }
+      if (!data) continue;
+
+      const payload = JSON.parse(data);
+      if (event === 'token') onToken(payload.content);
+      else if (event === 'reset') onReset();
+      else if (event === 'error') throw new Error(payload.error);
+      else if (event === 'done') return payload;
+    }
+  }
+
+  throw new Error('The answer was cut off. Please try again.');
+}
@@ -18,6 +18,13 @@ export const AuthProvider = ({ children }) => {
       const response = await apiClient.get('/api/me');
       setUser(response.data); // Set user data if successful
       console.log('AuthProvider: User fetched successfully:', response.data);
+      // Daily chat limits reset at the user's local midnight
+      const timezone = Intl.DateTimeFormat().resolvedOptions().timeZone;
+      if (timezone) {
+        apiClient.put('/api/me/timezone', { timezone }).catch((err) =>
+          console.log('AuthProvider: Failed to store time zone:', err.response?.data?.error || err.message)
+        );
+      }
     } catch (err) {
       console.log('AuthProvider: Failed to fetch user (likely not logged in):', err.response?.data?.error || err.message);
       setUser(null); // Ensure user is null if fetch fails
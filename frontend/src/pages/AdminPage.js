@@ -22,6 +22,9 @@ function AdminPage() {
     const [isEditing, setIsEditing] = useState(false)
     const [editingPlan, setEditingPlan] = useState(null)
     const [windowHeight, setWindowHeight] = useState(window.innerHeight)
+    const [suggestions, setSuggestions] = useState([])
+    const [audience, setAudience] = useState({ age_band: "adult", reading_level: "standard" })
+    const [audienceLocked, setAudienceLocked] = useState(false) // Set by an admin or guardian
 
     // Handle window resize events for responsiveness
     useEffect(() => {
@@ -61,6 +64,26 @@ function AdminPage() {
         fetchPlans()
     }, [fetchPlans])
 
+    // Fetch topic suggestions for the create form; the form works without them
+    useEffect(() => {
+        apiClient
+            .get("/api/plans/suggestions")
+            .then((response) => setSuggestions(response.data?.suggestions || []))
+            .catch((err) => console.error("Failed to fetch topic suggestions:", err))
+    }, [])
+
+    // Start the audience selects from the user's profile
+    useEffect(() => {
+        apiClient
+            .get("/api/me/audience")
+            .then((response) => {
+                const { age_band, reading_level } = response.data?.effective || {}
+                if (age_band && reading_level) setAudience({ age_band, reading_level })
+                setAudienceLocked(Boolean(response.data?.locked))
+            })
+            .catch((err) => console.error("Failed to fetch audience profile:", err))
+    }, [])
+
     // Handle plan creation using apiClient
     const handleCreatePlan = async (e) => {
         e.preventDefault()
@@ -77,11 +100,12 @@ function AdminPage() {
             const response = await apiClient.post("/api/plans", {
                 topic: topic,
                 duration_days: Number.parseInt(duration, 10),
+                audience: audience,
             })
 
             const responseData = response.data
             setSuccessMessage(
-                `Successfully created plan for "${responseData.topic}" (ID: ${responseData.id}). This is now the active plan.`
+                `Successfully created plan for "${responseData.topic}" (ID: ${responseData.id}).${responseData.is_active ? " This is now the active plan." : ""}`
             )
             setTopic("")
             setDuration(7)
@@ -137,7 +161,7 @@ function AdminPage() {
         setSuccessMessage("")
 
         try {
-            await apiClient.delete(`/api/plans?id=${planId}`)
+            await apiClient.delete(`/api/plans/${planId}`)
             setSuccessMessage("Plan deleted successfully!")
             fetchPlans() // Refresh the list of plans
             setConfirmDelete(null)
@@ -189,7 +213,7 @@ function AdminPage() {
                 daily_verses: editingPlan.daily_verses
             }
             
-            await apiClient.put("/api/plans", updatedPlan)
+            await apiClient.put(`/api/plans/${editingPlan.id}`, updatedPlan)
             setSuccessMessage("Plan updated successfully!")
             setTopic("")
             setDuration(7)
@@ -329,6 +353,22 @@ function AdminPage() {
                                             className="admin-input"
                                         />
                                         <p className="input-help">Choose a specific theme, character, or concept from the Bible</p>
+                                        {!isEditing && suggestions.length > 0 && (
+                                            <div className="topic-suggestions">
+                                                {suggestions.map((suggestion) => (
+                                                    <button
+                                                        key={suggestion.topic}
+                                                        type="button"
+                                                        className="topic-suggestion"
+                                                        title={suggestion.rationale}
+                                                        onClick={() => setTopic(suggestion.topic)}
+                                                        disabled={isLoading}
+                                                    >
+                                                        {suggestion.topic}
+                                                    </button>
+                                                ))}
+                                            </div>
+                                        )}
                                     </div>
 
                                     <div className="form-group">
@@ -367,6 +407,43 @@ function AdminPage() {
                                         <p className="input-help">Recommended: 7-30 days (maximum 90 days)</p>
                                     </div>
 
+                                    {!isEditing && (
+                                        <div className="form-group">
+                                            <label htmlFor="age-band">Written for:</label>
+                                            <div className="audience-input-group">
+                                                <select
+                                                    id="age-band"
+                                                    value={audience.age_band}
+                                                    onChange={(e) => setAudience({ ...audience, age_band: e.target.value })}
+                                                    disabled={isLoading || audienceLocked}
+                                                    className="admin-input"
+                                                >
+                                                    <option value="child">Children (8-12)</option>
+                                                    <option value="teen">Teens (13-17)</option>
+                                                    <option value="young_adult">Young adults (18-25)</option>
+                                                    <option value="adult">Adults</option>
+                                                    <option value="senior">Older adults</option>
+                                                </select>
+                                                <select
+                                                    aria-label="Reading level"
+                                                    value={audience.reading_level}
+                                                    onChange={(e) => setAudience({ ...audience, reading_level: e.target.value })}
+                                                    disabled={isLoading || audienceLocked}
+                                                    className="admin-input"
+                                                >
+                                                    <option value="simple">Simple reading level</option>
+                                                    <option value="standard">Standard reading level</option>
+                                                    <option value="advanced">Advanced reading level</option>
+                                                </select>
+                                            </div>
+                                            <p className="input-help">
+                                                {audienceLocked
+                                                    ? "Your audience profile was set by an admin or guardian"
+                                                    : "Defaults to your audience profile"}
+                                            </p>
+                                        </div>
+                                    )}
+
                                     <div className="button-group">
                                         <button type="submit" className="admin-button create-button" disabled={isLoading}>
                                             {isLoading ? (
@@ -411,7 +488,7 @@ function AdminPage() {
                                 <div className="admin-card-header">
                                     <h2 className="admin-card-title">Existing Reading Plans</h2>
                                     <p className="admin-card-description">
-                                        Manage your created reading plans. Only one plan can be active at a time.
+                                        Manage your created reading plans. Several plans can run at once; the active plan is shown first.
                                     </p>
                                 </div>
 
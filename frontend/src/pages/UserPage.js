@@ -2,6 +2,7 @@
 
 import { useState, useEffect, useRef, useCallback } from "react"
 import apiClient from "../api/axiosConfig"
+import { streamChat } from "../api/chatStream"
 import { useAuth } from "../context/AuthContext"
 import { Link } from "react-router-dom"
 import "../App.css"
@@ -18,6 +19,17 @@ const MSG_TYPE = {
     INFO: "info",
 }
 
+// Convert a stored conversation's branch to chat history entries
+const toChatHistory = (messages) =>
+    (messages || []).map((msg) => ({
+        role: msg.role,
+        content: msg.content,
+        citations: msg.citations || [],
+        messageId: msg.id,
+        siblings: msg.siblings || [],
+        timestamp: new Date(msg.created_at),
+    }))
+
 function UserPage() {
     const { user, logout } = useAuth()
     const [dailyVerse, setDailyVerse] = useState(null)
@@ -26,6 +38,7 @@ function UserPage() {
     const [chatQuestion, setChatQuestion] = useState("")
     const [chatHistory, setChatHistory] = useState([])
     const [isChatLoading, setIsChatLoading] = useState(false)
+    const [conversationId, setConversationId] = useState(null)
     const [isDarkMode, setIsDarkMode] = useState(() => {
         // Check user preference or system preference
         return window.matchMedia && window.matchMedia("(prefers-color-scheme: dark)").matches
@@ -49,6 +62,21 @@ function UserPage() {
         scrollToBottom()
     }, [chatHistory])
 
+    // Resume the latest conversation about today's reading, if there is one
+    useEffect(() => {
+        if (!dailyVerse?.plan_id) return
+        apiClient
+            .get("/api/chat/conversations", { params: { plan_id: dailyVerse.plan_id, day: dailyVerse.day } })
+            .then(async (response) => {
+                const latest = response.data?.[0]
+                if (!latest) return
+                const conversation = await apiClient.get(`/api/chat/conversations/${latest.id}`)
+                setConversationId(latest.id)
+                setChatHistory(toChatHistory(conversation.data.messages))
+            })
+            .catch((err) => console.error("Failed to load conversation:", err))
+    }, [dailyVerse?.plan_id, dailyVerse?.day])
+
     // Handle window resize events for responsiveness
     useEffect(() => {
         const handleResize = () => {
@@ -155,24 +183,45 @@ function UserPage() {
         setChatHistory((prev) => [...prev, { role, content, timestamp: new Date() }])
     }
 
-    // Handle chat submit using apiClient
-    const handleChatSubmit = async (e) => {
-        e.preventDefault()
-        const question = chatQuestion.trim()
-        if (!question || !dailyVerse || isChatLoading) return
+    // Append a streamed piece of the answer, starting the assistant message on the first piece
+    const appendToStreamingAnswer = (delta) => {
+        setChatHistory((prev) => {
+            const last = prev[prev.length - 1]
+            if (last?.streaming) {
+                return [...prev.slice(0, -1), { ...last, content: last.content + delta }]
+            }
+            return [...prev, { role: MSG_TYPE.ASSISTANT, content: delta, timestamp: new Date(), streaming: true }]
+        })
+    }
 
-        addMessageToHistory(MSG_TYPE.USER, question)
-        setChatQuestion("")
+    // Drop the streamed answer, whose text turned out not to be the answer
+    const dropStreamingAnswer = () => {
+        setChatHistory((prev) => prev.filter((msg) => !msg.streaming))
+    }
+
+    // Stream an answer after showing the given history, then show the branch as stored,
+    // which carries the moderated answer and the alternatives to each message
+    const streamAnswer = async (request, history) => {
+        setChatHistory(history)
         setIsChatLoading(true)
 
         try {
-            const response = await apiClient.post("/api/chat", {
-                verse: dailyVerse,
-                question: question,
-            })
-            addMessageToHistory(MSG_TYPE.ASSISTANT, response.data.answer)
+            const result = await streamChat(
+                {
+                    conversation_id: conversationId || undefined,
+                    verse: dailyVerse,
+                    ...request,
+                },
+                appendToStreamingAnswer,
+                dropStreamingAnswer
+            )
+            const conversation = await apiClient.get(`/api/chat/conversations/${result.conversation_id}`)
+            setConversationId(result.conversation_id)
+            setChatHistory(toChatHistory(conversation.data.messages))
         } catch (error) {
             console.error("Failed to get chat response:", error)
+            // A partial answer isn't saved, so drop it
+            setChatHistory((prev) => prev.filter((msg) => !msg.streaming))
             const errorMsg = error.response?.data?.error || error.message || "Unknown error"
             addMessageToHistory(MSG_TYPE.ERROR, `Oops! Chatbot trouble: ${errorMsg}`)
         } finally {
@@ -180,6 +229,47 @@ function UserPage() {
         }
     }
 
+    // Handle chat submit, streaming the answer as it is written
+    const handleChatSubmit = async (e) => {
+        e.preventDefault()
+        const question = chatQuestion.trim()
+        if (!question || !dailyVerse || isChatLoading) return
+
+        setChatQuestion("")
+        await streamAnswer({ question }, [...chatHistory, { role: MSG_TYPE.USER, content: question, timestamp: new Date() }])
+    }
+
+    // Edit an earlier question, starting a new branch from it
+    const handleEditQuestion = async (historyIndex) => {
+        const msg = chatHistory[historyIndex]
+        if (!msg?.messageId || isChatLoading) return
+        const question = (window.prompt("Edit your question", msg.content) || "").trim()
+        if (!question || question === msg.content) return
+
+        await streamAnswer({ question, edit_message_id: msg.messageId }, [
+            ...chatHistory.slice(0, historyIndex),
+            { role: MSG_TYPE.USER, content: question, timestamp: new Date() },
+        ])
+    }
+
+    // Answer the last question again; the earlier answer stays available as an alternative
+    const handleRegenerate = async (historyIndex) => {
+        if (!conversationId || isChatLoading) return
+        await streamAnswer({ regenerate: true }, chatHistory.slice(0, historyIndex))
+    }
+
+    // Show the previous or next alternative to a message
+    const handleSwitchBranch = async (msg, step) => {
+        const target = msg.siblings[msg.siblings.indexOf(msg.messageId) + step]
+        if (!conversationId || !target || isChatLoading) return
+        try {
+            const response = await apiClient.put(`/api/chat/conversations/${conversationId}/branch`, { message_id: target })
+            setChatHistory(toChatHistory(response.data.messages))
+        } catch (error) {
+            console.error("Failed to switch branch:", error)
+        }
+    }
+
     // Navigate through verse pages
     const nextPage = () => {
         if (currentPage < versePages.length - 1) {
@@ -195,9 +285,15 @@ function UserPage() {
 
     // Handle chat reset using apiClient
     const handleResetChat = async () => {
+        // Without a conversation there is nothing stored to clear
+        if (!conversationId) {
+            setChatHistory([])
+            addMessageToHistory(MSG_TYPE.INFO, "Chat history cleared. Ask a new question!")
+            return
+        }
         setIsChatLoading(true)
         try {
-            const response = await apiClient.post("/api/chat/reset")
+            const response = await apiClient.post("/api/chat/reset", { conversation_id: conversationId })
             setChatHistory([])
             addMessageToHistory(MSG_TYPE.INFO, response.data.message || "Chat history cleared. Ask a new question!")
             console.log("Chat reset successfully")
@@ -210,6 +306,44 @@ function UserPage() {
         }
     }
 
+    // Rate an answer thumbs up or down, asking why when it was unhelpful
+    const handleRateAnswer = async (historyIndex, rating) => {
+        const msg = chatHistory[historyIndex]
+        if (!conversationId || !msg?.messageId) return
+        const reason = rating === "down" ? window.prompt("What was wrong with this answer? (optional)") || "" : ""
+        try {
+            await apiClient.post(`/api/chat/conversations/${conversationId}/messages/${msg.messageId}/feedback`, {
+                rating,
+                reason,
+            })
+            setChatHistory((prev) => prev.map((m, i) => (i === historyIndex ? { ...m, rating } : m)))
+        } catch (error) {
+            console.error("Failed to rate answer:", error)
+        }
+    }
+
+    // Download the current conversation as Markdown for a journal
+    const handleExportChat = async () => {
+        if (!conversationId) return
+        try {
+            const response = await apiClient.get(`/api/chat/conversations/${conversationId}/export`, {
+                params: { format: "md" },
+                responseType: "blob",
+            })
+            const fileName =
+                /filename="([^"]+)"/.exec(response.headers["content-disposition"] || "")?.[1] || "conversation.md"
+            const url = URL.createObjectURL(response.data)
+            const link = document.createElement("a")
+            link.href = url
+            link.download = fileName
+            link.click()
+            URL.revokeObjectURL(url)
+        } catch (error) {
+            console.error("Failed to export chat:", error)
+            addMessageToHistory(MSG_TYPE.ERROR, "Could not export this conversation. Please try again.")
+        }
+    }
+
     // Share verse functionality
     const shareVerse = () => {
         if (navigator.share && dailyVerse) {
@@ -432,6 +566,27 @@ function UserPage() {
                                 <div className="chat-header">
                                     <h2 className="chat-title">Bible Study Assistant</h2>
                                     <div className="chat-actions">
+                                        {conversationId && (
+                                            <button
+                                                onClick={handleExportChat}
+                                                className="reset-button"
+                                                disabled={isChatLoading}
+                                                aria-label="Export conversation"
+                                            >
+                                                <svg
+                                                    xmlns="http://www.w3.org/2000/svg"
+                                                    viewBox="0 0 24 24"
+                                                    fill="none"
+                                                    stroke="currentColor"
+                                                    strokeWidth="2"
+                                                    strokeLinecap="round"
+                                                    strokeLinejoin="round"
+                                                >
+                                                    <path d="M21 15v4a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2v-4M7 10l5 5 5-5M12 15V3"></path>
+                                                </svg>
+                                                Export
+                                            </button>
+                                        )}
                                         <button
                                             onClick={handleResetChat}
                                             className="reset-button"
@@ -483,7 +638,83 @@ function UserPage() {
                                             role={msg.role === MSG_TYPE.ASSISTANT ? "status" : ""}
                                         >
                                             <p>{msg.content}</p>
+                                            {msg.citations?.length > 0 && (
+                                                <details className="chat-citations">
+                                                    <summary>Sources: {msg.citations.map((c) => c.reference).join(", ")}</summary>
+                                                    {msg.citations.map((citation) => (
+                                                        <blockquote key={citation.reference}>
+                                                            <cite>{citation.reference}</cite>
+                                                            {citation.text && <p>{citation.text}</p>}
+                                                        </blockquote>
+                                                    ))}
+                                                </details>
+                                            )}
                                             <div className="chat-message-time">{formatTime(msg.timestamp)}</div>
+                                            {msg.messageId && (
+                                                <div className="chat-message-actions">
+                                                    {msg.siblings.length > 1 && (
+                                                        <span className="chat-branch-nav">
+                                                            <button
+                                                                type="button"
+                                                                onClick={() => handleSwitchBranch(msg, -1)}
+                                                                disabled={isChatLoading || msg.siblings[0] === msg.messageId}
+                                                                aria-label="Previous version"
+                                                            >
+                                                                ‹
+                                                            </button>
+                                                            {msg.siblings.indexOf(msg.messageId) + 1}/{msg.siblings.length}
+                                                            <button
+                                                                type="button"
+                                                                onClick={() => handleSwitchBranch(msg, 1)}
+                                                                disabled={isChatLoading || msg.siblings[msg.siblings.length - 1] === msg.messageId}
+                                                                aria-label="Next version"
+                                                            >
+                                                                ›
+                                                            </button>
+                                                        </span>
+                                                    )}
+                                                    {msg.role === MSG_TYPE.USER && (
+                                                        <button
+                                                            type="button"
+                                                            onClick={() => handleEditQuestion(index)}
+                                                            disabled={isChatLoading}
+                                                            aria-label="Edit question"
+                                                        >
+                                                            ✏️
+                                                        </button>
+                                                    )}
+                                                    {msg.role === MSG_TYPE.ASSISTANT && index === chatHistory.length - 1 && (
+                                                        <button
+                                                            type="button"
+                                                            onClick={() => handleRegenerate(index)}
+                                                            disabled={isChatLoading}
+                                                            aria-label="Regenerate answer"
+                                                        >
+                                                            🔄
+                                                        </button>
+                                                    )}
+                                                </div>
+                                            )}
+                                            {msg.role === MSG_TYPE.ASSISTANT && msg.messageId && (
+                                                <div className="chat-feedback">
+                                                    <button
+                                                        type="button"
+                                                        className={msg.rating === "up" ? "selected" : ""}
+                                                        onClick={() => handleRateAnswer(index, "up")}
+                                                        aria-label="Helpful answer"
+                                                    >
+                                                        👍
+                                                    </button>
+                                                    <button
+                                                        type="button"
+                                                        className={msg.rating === "down" ? "selected" : ""}
+                                                        onClick={() => handleRateAnswer(index, "down")}
+                                                        aria-label="Unhelpful answer"
+                                                    >
+                                                        👎
+                                                    </button>
+                                                </div>
+                                            )}
                                         </div>
                                     ))}
                                     <div ref={chatEndRef} />